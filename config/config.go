@@ -1,8 +1,15 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"codepair-sinarmas/pkg/logger"
 	"codepair-sinarmas/pkg/serror"
 
 	"github.com/gin-gonic/gin"
@@ -16,16 +23,52 @@ type Config struct {
 
 func Init() (cfg Config) {
 	Catch(cfg.InitTimezone())
+	Catch(cfg.InitLogger())
+	Catch(cfg.InitPasswordHasher())
+	Catch(cfg.InitPasswordPolicy())
+	Catch(cfg.InitTokenKeys())
 	Catch(cfg.InitPostgres())
 	Catch(cfg.InitService())
 
 	return
 }
 
+// Start serves until SIGINT or SIGTERM then shuts down gracefully, the logger is flushed and closed last
 func (cfg *Config) Start() (errx serror.SError) {
-	cfg.Server.Run(os.Getenv("APP_PORT"))
+	timeout, errx := envDuration("APP_SHUTDOWN_TIMEOUT", 10*time.Second)
+	if errx != nil {
+		return errx
+	}
 
-	return
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: os.Getenv("APP_PORT"), Handler: cfg.Server}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			errx = serror.NewFromErrorc(err, "Failed to start the server")
+		}
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			errx = serror.NewFromErrorc(err, "Failed to shutdown the server")
+		}
+	}
+
+	if err := logger.Default().Close(); err != nil && errx == nil {
+		errx = serror.NewFromErrorc(err, "Failed to close the logger")
+	}
+
+	return errx
 }
 
 // func (cfg *Config) stop() {
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"codepair-sinarmas/pkg/logger"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utstring"
+	"codepair-sinarmas/service/helper"
+)
+
+// InitTokenKeys loads the token signing keys, the asymmetric keys must be persisted in JWT_KEYS_DIR so they
+// survive restarts and are shared by the replicas, only the local environment may keep them in memory
+func (cfg *Config) InitTokenKeys() serror.SError {
+	algorithm := utstring.Env("JWT_ALGORITHM", helper.TokenAlgorithmRS256)
+	if strings.EqualFold(algorithm, "eddsa") || strings.EqualFold(algorithm, "ed25519") {
+		algorithm = helper.TokenAlgorithmEdDSA
+	} else {
+		algorithm = strings.ToUpper(algorithm)
+	}
+
+	dir := utstring.Env("JWT_KEYS_DIR")
+	if dir == "" && algorithm != helper.TokenAlgorithmHS256 && utstring.Env("APP_ENV", "local") != "local" {
+		return serror.Newf("JWT_KEYS_DIR is required to persist the %s signing keys", algorithm)
+	}
+
+	rotationInterval, errx := envDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour)
+	if errx != nil {
+		return errx
+	}
+
+	overlap, errx := envDuration("JWT_KEY_OVERLAP", 48*time.Hour)
+	if errx != nil {
+		return errx
+	}
+
+	keySet, errx := helper.NewTokenKeySet(helper.TokenKeySetOptions{
+		Algorithm:        algorithm,
+		Secret:           utstring.Env("SECRET_KEY"),
+		Dir:              dir,
+		RotationInterval: rotationInterval,
+		Overlap:          overlap,
+	})
+	if errx != nil {
+		return errx
+	}
+
+	ttl, errx := envDuration("JWT_TTL", 24*time.Hour)
+	if errx != nil {
+		return errx
+	}
+
+	clockSkew, errx := envDuration("JWT_CLOCK_SKEW", 30*time.Second)
+	if errx != nil {
+		return errx
+	}
+
+	opt := helper.DefaultTokenOptions()
+	opt.Issuer = utstring.Env("JWT_ISSUER", opt.Issuer)
+	opt.TTL = ttl
+	opt.ClockSkew = clockSkew
+	if audience := utstring.Env("JWT_AUDIENCE"); audience != "" {
+		opt.Audience = nil
+		for _, v := range strings.Split(audience, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				opt.Audience = append(opt.Audience, v)
+			}
+		}
+	}
+
+	helper.SetTokenOptions(opt)
+	helper.SetTokenKeySet(keySet)
+	keySet.StartRotation(time.Minute, nil, func(errx serror.SError) {
+		logger.Err(errx)
+	})
+
+	return nil
+}
+
+func envDuration(key string, def time.Duration) (time.Duration, serror.SError) {
+	value := utstring.Env(key)
+	if value == "" {
+		return def, nil
+	}
+
+	res, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, serror.NewFromErrorc(err, "Invalid duration for "+key)
+	}
+
+	return res, nil
+}
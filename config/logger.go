@@ -0,0 +1,198 @@
+package config
+
+import (
+	"strings"
+
+	"codepair-sinarmas/pkg/logger"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utint"
+	"codepair-sinarmas/pkg/utils/utstring"
+)
+
+func (cfg *Config) InitLogger() serror.SError {
+	modes := map[string]logger.Mode{
+		"daily":     logger.ModeDaily,
+		"monthly":   logger.ModeMonthly,
+		"yearly":    logger.ModeYearly,
+		"permanent": logger.ModePermanent,
+	}
+
+	modeName := strings.ToLower(utstring.Env("LOG_MODE", "daily"))
+	mode, ok := modes[modeName]
+	if !ok {
+		return serror.Newf("unsupported log mode %s", modeName)
+	}
+
+	level, err := logger.ParseErrorLevel(utstring.Env("LOG_LEVEL", string(logger.DefaultLevel)))
+	if err != nil {
+		return serror.NewFromErrorc(err, "Failed to parse LOG_LEVEL")
+	}
+
+	maxAge, errx := envDuration("LOG_MAX_AGE", 0)
+	if errx != nil {
+		return errx
+	}
+
+	flushInterval, errx := envDuration("LOG_FLUSH_INTERVAL", logger.DefaultFlushInterval)
+	if errx != nil {
+		return errx
+	}
+
+	writePolicies := map[string]logger.WritePolicy{
+		"block": logger.WritePolicyBlock,
+		"drop":  logger.WritePolicyDrop,
+	}
+
+	writePolicyName := strings.ToLower(utstring.Env("LOG_WRITE_POLICY", "block"))
+	writePolicy, ok := writePolicies[writePolicyName]
+	if !ok {
+		return serror.Newf("unsupported log write policy %s", writePolicyName)
+	}
+
+	fsyncPolicies := map[string]logger.FsyncPolicy{
+		"flush":  logger.FsyncOnFlush,
+		"always": logger.FsyncAlways,
+		"never":  logger.FsyncNever,
+	}
+
+	fsyncPolicyName := strings.ToLower(utstring.Env("LOG_FSYNC", "flush"))
+	fsyncPolicy, ok := fsyncPolicies[fsyncPolicyName]
+	if !ok {
+		return serror.Newf("unsupported log fsync policy %s", fsyncPolicyName)
+	}
+
+	intercept, errx := logInterceptor()
+	if errx != nil {
+		return errx
+	}
+
+	log := logger.Construct(logger.Options{
+		Interceptor:   intercept,
+		Redactor:      logRedactor(),
+		Mode:          mode,
+		Level:         level,
+		Path:          utstring.Env("LOG_PATH", "./storage/logs"),
+		Writing:       envBool("LOG_WRITING", false),
+		MaxSize:       utint.StringToInt(utstring.Env("LOG_MAX_SIZE_MB"), 0) * 1024 * 1024,
+		MaxAge:        maxAge,
+		MaxBackups:    int(utint.StringToInt(utstring.Env("LOG_MAX_BACKUPS"), 0)),
+		Compress:      envBool("LOG_COMPRESS", false),
+		FlushInterval: flushInterval,
+
+		WriteQueueSize: int(utint.StringToInt(utstring.Env("LOG_WRITE_QUEUE_SIZE"), logger.DefaultWriteQueueSize)),
+		WritePolicy:    writePolicy,
+		FsyncPolicy:    fsyncPolicy,
+	})
+
+	if err := logger.SetDefault(log); err != nil {
+		return serror.NewFromErrorc(err, "Failed to startup the logger")
+	}
+
+	return nil
+}
+
+// logRedactor masks the default keys and the comma separated LOG_REDACT_KEYS
+func logRedactor() *logger.Redactor {
+	keys := append([]string{}, logger.DefaultRedactKeys...)
+	for _, v := range strings.Split(utstring.Env("LOG_REDACT_KEYS"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			keys = append(keys, v)
+		}
+	}
+
+	return logger.NewRedactor(logger.RedactOptions{
+		Keys: keys,
+		Patterns: []logger.RedactPattern{
+			logger.RedactPatternJWT,
+			logger.RedactPatternEmail,
+			logger.RedactPatternPhone,
+		},
+	})
+}
+
+// logInterceptor fans out to the Rollbar, OTLP and Sentry exporters when configured, each behind an async queue
+func logInterceptor() (logger.LogInterceptor, serror.SError) {
+	var (
+		queueSize = int(utint.StringToInt(utstring.Env("LOG_EXPORT_QUEUE_SIZE"), logger.DefaultAsyncQueueSize))
+		sinks     = []logger.FanoutSink{
+			{Name: "console", Interceptor: logger.DefaultInterceptor()},
+		}
+	)
+
+	// LOG_FORMAT=json prints one JSON object per line, shaped by LOG_FORMAT_PROFILE
+	if strings.EqualFold(utstring.Env("LOG_FORMAT", "text"), "json") {
+		fjson, errx := logger.FJSONInterceptor(logger.FJSONOptions{
+			Key:      utstring.Env("LOG_ROLLBAR_HOST", "codepair-sinarmas"),
+			Name:     utstring.Env("LOG_SERVICE_NAME", "codepair-sinarmas"),
+			Version:  utstring.Env("LOG_SERVICE_VERSION", "-"),
+			Printing: true,
+			Profile:  logger.FJSONProfile(strings.ToLower(utstring.Env("LOG_FORMAT_PROFILE", string(logger.FJSONProfileDefault)))),
+			Caller:   envBool("LOG_CALLER", false),
+		})
+		if errx != nil {
+			return nil, errx
+		}
+
+		sinks[0] = logger.FanoutSink{Name: "console", Interceptor: fjson}
+	}
+
+	if token := utstring.Env("LOG_ROLLBAR_TOKEN"); token != "" {
+		rollbar, errx := logger.RollbarInterceptor(logger.RollbarOptions{
+			Key:      utstring.Env("LOG_ROLLBAR_HOST", "codepair-sinarmas"),
+			Name:     utstring.Env("LOG_SERVICE_NAME", "codepair-sinarmas"),
+			Token:    token,
+			Version:  utstring.Env("LOG_SERVICE_VERSION", "-"),
+			Level:    logger.ErrorLevel(utstring.Env("LOG_ROLLBAR_LEVEL", string(logger.ErrorLevelWarning))),
+			Endpoint: utstring.Env("LOG_ROLLBAR_ENDPOINT"),
+			// the console sink already prints every entry, the rollbar sink printing them too would duplicate the lines
+			Printing: false,
+		})
+		if errx != nil {
+			return nil, errx
+		}
+
+		sinks = append(sinks, logger.FanoutSink{
+			Name:        "rollbar",
+			Interceptor: logger.AsyncInterceptor(rollbar, logger.AsyncOptions{QueueSize: queueSize}),
+		})
+	}
+
+	if endpoint := utstring.Env("LOG_OTLP_ENDPOINT"); endpoint != "" {
+		otlp, errx := logger.OTLPInterceptor(logger.OTLPOptions{
+			Endpoint:       endpoint,
+			ServiceName:    utstring.Env("LOG_SERVICE_NAME", "codepair-sinarmas"),
+			ServiceVersion: utstring.Env("LOG_SERVICE_VERSION"),
+			Level:          logger.ErrorLevel(utstring.Env("LOG_OTLP_LEVEL", string(logger.ErrorLevelInfo))),
+		})
+		if errx != nil {
+			return nil, errx
+		}
+
+		sinks = append(sinks, logger.FanoutSink{
+			Name:        "otlp",
+			Interceptor: logger.AsyncInterceptor(otlp, logger.AsyncOptions{QueueSize: queueSize}),
+		})
+	}
+
+	if dsn := utstring.Env("LOG_SENTRY_DSN"); dsn != "" {
+		sentry, errx := logger.SentryInterceptor(logger.SentryOptions{
+			DSN:     dsn,
+			Release: utstring.Env("LOG_SERVICE_VERSION"),
+			Level:   logger.ErrorLevel(utstring.Env("LOG_SENTRY_LEVEL", string(logger.ErrorLevelWarning))),
+		})
+		if errx != nil {
+			return nil, errx
+		}
+
+		sinks = append(sinks, logger.FanoutSink{
+			Name:        "sentry",
+			Interceptor: logger.AsyncInterceptor(sentry, logger.AsyncOptions{QueueSize: queueSize}),
+		})
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0].Interceptor, nil
+	}
+
+	return logger.FanoutInterceptor(sinks...)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utstring"
+	api "codepair-sinarmas/service"
+	"codepair-sinarmas/service/notifier"
+)
+
+// otpNotifier mails the OTPs through SMTP_HOST, the local environment logs them when no SMTP server is configured
+func otpNotifier() (api.OTPNotifier, serror.SError) {
+	host := utstring.Env("SMTP_HOST")
+	if host == "" {
+		if utstring.Env("APP_ENV", "local") != "local" {
+			return nil, serror.New("SMTP_HOST is required to send the OTPs")
+		}
+
+		return notifier.NewLogOTPNotifier(), nil
+	}
+
+	return notifier.NewEmailOTPNotifier(notifier.EmailOptions{
+		Host:     host,
+		Port:     utstring.Env("SMTP_PORT", "587"),
+		Username: utstring.Env("SMTP_USERNAME"),
+		Password: utstring.Env("SMTP_PASSWORD"),
+		From:     utstring.Env("SMTP_FROM", "no-reply@codepair-sinarmas.local"),
+	}), nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utint"
+	"codepair-sinarmas/pkg/utils/utstring"
+	"codepair-sinarmas/service/helper"
+)
+
+func (cfg *Config) InitPasswordHasher() serror.SError {
+	bcryptHasher := helper.NewBcryptHasher(int(utint.StringToInt(utstring.Env("BCRYPT_COST"), helper.DEFAULT_BCRYPT_COST)))
+	argon2idHasher := helper.NewArgon2idHasher(helper.Argon2idParams{
+		Memory:      uint32(utint.StringToInt(utstring.Env("ARGON2_MEMORY"), 0)),
+		Iterations:  uint32(utint.StringToInt(utstring.Env("ARGON2_ITERATIONS"), 0)),
+		Parallelism: uint8(utint.StringToInt(utstring.Env("ARGON2_PARALLELISM"), 0)),
+	})
+
+	algo := strings.ToLower(utstring.Env("PASSWORD_HASHER", "bcrypt"))
+	switch algo {
+	case "bcrypt":
+		helper.SetPasswordHasher(helper.NewPasswordHasherChain(bcryptHasher, argon2idHasher))
+
+	case "argon2id":
+		helper.SetPasswordHasher(helper.NewPasswordHasherChain(argon2idHasher, bcryptHasher))
+
+	default:
+		return serror.Newf("unsupported password hasher %s", algo)
+	}
+
+	return nil
+}
+
+func (cfg *Config) InitPasswordPolicy() serror.SError {
+	policy := helper.DefaultPasswordPolicy()
+	policy.MinLength = int(utint.StringToInt(utstring.Env("PASSWORD_MIN_LENGTH"), int64(policy.MinLength)))
+	policy.MaxLength = int(utint.StringToInt(utstring.Env("PASSWORD_MAX_LENGTH"), int64(policy.MaxLength)))
+	policy.RequireUpper = envBool("PASSWORD_REQUIRE_UPPER", policy.RequireUpper)
+	policy.RequireLower = envBool("PASSWORD_REQUIRE_LOWER", policy.RequireLower)
+	policy.RequireDigit = envBool("PASSWORD_REQUIRE_DIGIT", policy.RequireDigit)
+	policy.RequireSymbol = envBool("PASSWORD_REQUIRE_SYMBOL", policy.RequireSymbol)
+	policy.BanCommon = envBool("PASSWORD_BAN_COMMON", policy.BanCommon)
+	policy.DisallowPersonalInfo = envBool("PASSWORD_DISALLOW_PERSONAL_INFO", policy.DisallowPersonalInfo)
+
+	if policy.MaxLength > 0 && policy.MinLength > policy.MaxLength {
+		return serror.Newf("invalid password policy, min length %d exceeds max length %d", policy.MinLength, policy.MaxLength)
+	}
+
+	helper.SetPasswordPolicy(policy)
+	return nil
+}
+
+func envBool(key string, def bool) bool {
+	val, err := strconv.ParseBool(utstring.Env(key))
+	if err != nil {
+		return def
+	}
+
+	return val
+}
@@ -4,45 +4,135 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
+	"net"
+	"strings"
+	"time"
 
 	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/logger"
 	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utarray"
+	"codepair-sinarmas/pkg/utils/utint"
+	"codepair-sinarmas/pkg/utils/utstring"
+	"codepair-sinarmas/service/helper"
+	"codepair-sinarmas/service/repository"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
+// postgresSSLModes are the libpq sslmode values accepted by DB_SSL_MODE
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// maxConnectBackoff caps the delay between two connect attempts
+const maxConnectBackoff = 30 * time.Second
+
+// auditEventsAppendOnlySQL rejects any UPDATE or DELETE on audit_events so the trail stays append-only
+const auditEventsAppendOnlySQL = `
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+	BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+`
+
 func (cfg *Config) InitPostgres() serror.SError {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname =%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"))
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Info)})
+	sslMode := strings.ToLower(utstring.Env("DB_SSL_MODE", "disable"))
+	if !utarray.IsExist(sslMode, postgresSSLModes) {
+		return serror.Newf("unsupported database sslmode %s", sslMode)
+	}
+
+	connMaxLifetime, errx := envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute)
+	if errx != nil {
+		return errx
+	}
+
+	connMaxIdleTime, errx := envDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute)
+	if errx != nil {
+		return errx
+	}
+
+	backoff, errx := envDuration("DB_CONNECT_BACKOFF", time.Second)
+	if errx != nil {
+		return errx
+	}
+
+	var (
+		port         = utstring.Env("DB_PORT", "5432")
+		maxOpenConns = int(utint.StringToInt(utstring.Env("DB_MAX_OPEN_CONNS"), 25))
+		maxIdleConns = int(utint.StringToInt(utstring.Env("DB_MAX_IDLE_CONNS"), 10))
+		retries      = int(utint.StringToInt(utstring.Env("DB_CONNECT_RETRIES"), 5))
+	)
+
+	db, err := connectPostgres(postgresDSN(utstring.Env("DB_HOST"), port, sslMode), retries, backoff)
+	if err != nil {
+		return serror.NewFromErrorc(err, "Failed to connect to the database")
+	}
 
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("failed connect to database %+v", err)
-		return serror.NewFromError(err)
+		return serror.NewFromErrorc(err, "Failed to get the database connection pool")
+	}
+
+	sqlDB.SetMaxOpenConns(maxOpenConns)
+	sqlDB.SetMaxIdleConns(maxIdleConns)
+	sqlDB.SetConnMaxLifetime(connMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(connMaxIdleTime)
+
+	// replicas are only used by the statements asking for them, see repository.ResolverReplicas
+	if hosts := utstring.Env("DB_REPLICA_HOSTS"); hosts != "" {
+		var replicas []gorm.Dialector
+		for _, v := range strings.Split(hosts, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+
+			host, replicaPort := v, port
+			if h, p, err := net.SplitHostPort(v); err == nil {
+				host, replicaPort = h, p
+			}
+
+			replicas = append(replicas, postgres.Open(postgresDSN(host, replicaPort, sslMode)))
+		}
+
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		}, repository.ResolverReplicas).
+			SetMaxOpenConns(maxOpenConns).
+			SetMaxIdleConns(maxIdleConns).
+			SetConnMaxLifetime(connMaxLifetime).
+			SetConnMaxIdleTime(connMaxIdleTime)
+
+		if err = db.Use(resolver); err != nil {
+			return serror.NewFromErrorc(err, "Failed to connect to the database replicas")
+		}
 	}
 
 	err = db.Debug().AutoMigrate(
 		models.User{},
 		models.OTPLog{},
+		models.AuditEvent{},
+		models.APIKey{},
 	)
 	if err != nil {
-		log.Fatalf("failed to migrate database %+v", err)
-		return serror.NewFromError(err)
+		return serror.NewFromErrorc(err, "Failed to migrate the database")
 	}
 
-	if db.Migrator().HasTable(&models.User{}) {
-		if err := db.First(&models.User{}).Error; errors.Is(err, gorm.ErrRecordNotFound) {
-			users := []models.User{
-				{Name: "Admin 1", Email: "admin@gmail.com", Password: "admin123"},
-			}
-			if err := db.Create(&users).Error; err != nil {
-				log.Printf("Error seeding users: %s", err)
-			} else {
-				log.Println("Users seeded successfully")
-			}
-		}
+	err = db.Exec(auditEventsAppendOnlySQL).Error
+	if err != nil {
+		return serror.NewFromErrorc(err, "Failed to protect the audit events table")
+	}
+
+	if errx = seedAdmin(db); errx != nil {
+		return errx
 	}
 
 	cfg.DB = db
@@ -53,3 +143,80 @@ func (cfg *Config) InitPostgres() serror.SError {
 
 	return nil
 }
+
+// postgresDSN returns the DSN of the database on host and port, the credentials and TLS settings are shared by the replicas
+func postgresDSN(host, port, sslMode string) string {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		host, port, utstring.Env("DB_USER"), utstring.Env("DB_PASSWORD"), utstring.Env("DB_NAME"), sslMode)
+
+	for _, v := range [][2]string{
+		{"sslrootcert", "DB_SSL_ROOT_CERT"},
+		{"sslcert", "DB_SSL_CERT"},
+		{"sslkey", "DB_SSL_KEY"},
+	} {
+		if value := utstring.Env(v[1]); value != "" {
+			dsn += fmt.Sprintf(" %s=%s", v[0], value)
+		}
+	}
+
+	return dsn
+}
+
+// connectPostgres opens dsn, retrying up to retries times with a doubling backoff while the database is unreachable
+func connectPostgres(dsn string, retries int, backoff time.Duration) (db *gorm.DB, err error) {
+	for attempt := 0; ; attempt++ {
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Info)})
+		if err == nil || attempt >= retries {
+			return db, err
+		}
+
+		logger.Warnf("Failed to connect to the database, attempt %d of %d, retrying in %s, details: %v", attempt+1, retries+1, backoff, err)
+		time.Sleep(backoff)
+
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
+
+// seedAdmin creates the first admin from ADMIN_EMAIL and ADMIN_PASSWORD when the database has none,
+// the admin has to reset the password on the first login
+func seedAdmin(db *gorm.DB) serror.SError {
+	err := db.Where("role = ?", models.RoleAdmin).First(&models.User{}).Error
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return serror.NewFromErrorc(err, "Failed to look up the admin")
+	}
+
+	var (
+		name     = utstring.Env("ADMIN_NAME", "Admin")
+		email    = utstring.Env("ADMIN_EMAIL")
+		password = utstring.Env("ADMIN_PASSWORD")
+	)
+
+	if email == "" || password == "" {
+		log.Println("No admin seeded, ADMIN_EMAIL and ADMIN_PASSWORD are required to create the first admin")
+		return nil
+	}
+
+	if err := helper.CheckPassword(password, email, name); err != nil {
+		return serror.NewFromErrorc(err, "ADMIN_PASSWORD does not satisfy the password policy")
+	}
+
+	err = db.Create(&models.User{
+		Name:                  name,
+		Email:                 email,
+		Password:              password,
+		Role:                  models.RoleAdmin,
+		PasswordResetRequired: true,
+	}).Error
+	if err != nil {
+		return serror.NewFromErrorc(err, "Failed to seed the admin")
+	}
+
+	log.Println("Admin seeded successfully")
+	return nil
+}
@@ -8,15 +8,29 @@ import (
 )
 
 func (cfg *Config) InitService() (errx serror.SError) {
-	userRepo := repository.NewUserRepository(cfg.DB)
-	userUsecase := usecase.NewUserUsecase(userRepo)
+	auditRepo := repository.NewAuditRepository(cfg.DB)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
 
+	userRepo := repository.NewUserRepository(cfg.DB)
 	otpRepo := repository.NewOtpRepository(cfg.DB)
-	otpUsecase := usecase.NewOtpUsecase(otpRepo, userRepo)
+	apiKeyRepo := repository.NewAPIKeyRepository(cfg.DB)
+
+	otpNotifier, errx := otpNotifier()
+	if errx != nil {
+		return errx
+	}
+
+	userUsecase := usecase.NewUserUsecase(userRepo, otpRepo, auditUsecase)
+	otpUsecase := usecase.NewOtpUsecase(otpRepo, userRepo, otpNotifier, auditUsecase)
+	adminUsecase := usecase.NewAdminUsecase(userRepo)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, userRepo, auditUsecase)
 
 	route := rest.CreateHandler(
 		userUsecase,
 		otpUsecase,
+		auditUsecase,
+		adminUsecase,
+		apiKeyUsecase,
 	)
 
 	cfg.Server = route
@@ -3,7 +3,6 @@ module codepair-sinarmas
 go 1.23.4
 
 require (
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gearintellix/serr v1.1.8
 	github.com/gearintellix/u2 v1.0.9
 	github.com/gin-contrib/cors v1.7.3
@@ -11,17 +10,18 @@ require (
 	github.com/gin-gonic/gin v1.10.0
 	github.com/go-errors/errors v1.5.1
 	github.com/go-playground/validator/v10 v10.23.0
+	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/golang/mock v1.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/lunixbochs/vtclean v1.0.0
 	github.com/rivo/uniseg v0.4.7
-	github.com/rollbar/rollbar-go v1.4.5
 	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/crypto v0.31.0
 	gopkg.in/DataDog/dd-trace-go.v1 v1.70.3
 	gorm.io/driver/postgres v1.5.11
 	gorm.io/gorm v1.25.12
+	gorm.io/plugin/dbresolver v1.5.3
 )
 
 require (
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKey struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	Name           string     `gorm:"not null;size:128" json:"name"`
+	Prefix         string     `gorm:"not null;size:32;uniqueIndex" json:"prefix"`
+	KeyHash        string     `gorm:"not null;size:128" json:"-"`
+	OwnerType      string     `gorm:"not null;size:32" json:"owner_type"`
+	OwnerUserID    *int64     `gorm:"index" json:"owner_user_id"`
+	ServiceAccount string     `gorm:"size:128" json:"service_account"`
+	ScopesRaw      string     `gorm:"column:scopes;not null;default:''" json:"-"`
+	Scopes         []string   `gorm:"-" json:"scopes"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedBy      int64      `gorm:"not null" json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name           string    `json:"name" validate:"required,max=128"`
+	OwnerType      string    `json:"owner_type" validate:"required,oneof=user service_account"`
+	OwnerUserID    int64     `json:"owner_user_id" validate:"required_if=OwnerType user"`
+	ServiceAccount string    `json:"service_account" validate:"required_if=OwnerType service_account,max=128"`
+	Scopes         []string  `json:"scopes" validate:"required,min=1,dive,oneof=audit:read users:read users:write"`
+	ExpiresAt      time.Time `json:"expires_at" validate:"required"`
+}
+
+type CreateAPIKeyResponse struct {
+	// Key is the plain API key, it is only returned once
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
+
+type APIKeyFilter struct {
+	OwnerUserID    int64
+	IncludeRevoked bool
+	Page           int
+	Size           int
+}
+
+// Active reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, v := range k.Scopes {
+		if v == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ScopesRaw = strings.Join(k.Scopes, " ")
+	return
+}
+
+func (k *APIKey) AfterFind(tx *gorm.DB) (err error) {
+	k.Scopes = strings.Fields(k.ScopesRaw)
+	return
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditEvent struct {
+	ID        int64                  `gorm:"primaryKey" json:"id"`
+	ActorID   int64                  `gorm:"not null;index" json:"actor_id"`
+	TargetID  int64                  `gorm:"not null;index" json:"target_id"`
+	Action    string                 `gorm:"not null;size:64;index" json:"action"`
+	IP        string                 `gorm:"size:64" json:"ip"`
+	RequestID string                 `gorm:"size:64;index" json:"request_id"`
+	Diff      json.RawMessage        `gorm:"type:jsonb;not null" json:"diff"`
+	Changes   map[string]AuditChange `gorm:"-" json:"-"`
+	CreatedAt time.Time              `gorm:"index" json:"created_at"`
+}
+
+// AuditChange is a single field change recorded in an audit event diff
+type AuditChange struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+type AuditEventFilter struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Action    string
+	ActorID   int64
+	TargetID  int64
+	Page      int
+	Size      int
+}
+
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.Changes == nil {
+		e.Changes = map[string]AuditChange{}
+	}
+
+	e.Diff, err = json.Marshal(e.Changes)
+	return
+}
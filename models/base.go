@@ -7,8 +7,27 @@ type ResponseSuccess struct {
 }
 
 type ResponseError struct {
-	Message string      `json:"message"`
-	Error   interface{} `json:"errors,omitempty"`
+	Code      int         `json:"code,omitempty"`
+	Key       string      `json:"key,omitempty"`
+	Message   string      `json:"message"`
+	Error     interface{} `json:"errors,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// MediaTypeProblemJSON is the RFC 7807 problem details media type
+const MediaTypeProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 error response, Errors carries field level validation errors
+type ProblemDetails struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Key       string      `json:"key,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	TraceID   string      `json:"trace_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
 }
 
 type PaginationResponse struct {
@@ -21,3 +40,31 @@ type PaginationResponse struct {
 	LastPage     int   `json:"last_page"`
 	CurrentCount int   `json:"current_count"`
 }
+
+func NewPaginationResponse(page, size int, totalCount int64, currentCount int) PaginationResponse {
+	totalPages := 0
+	if size > 0 {
+		totalPages = int((totalCount + int64(size) - 1) / int64(size))
+	}
+
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	nextPage := page + 1
+	if nextPage > lastPage {
+		nextPage = lastPage
+	}
+
+	return PaginationResponse{
+		CurrentPage:  page,
+		PageSize:     size,
+		TotalCount:   totalCount,
+		TotalPages:   totalPages,
+		FirstPage:    1,
+		NextPage:     nextPage,
+		LastPage:     lastPage,
+		CurrentCount: currentCount,
+	}
+}
@@ -6,3 +6,64 @@ const (
 	// NotificationTypeSMS is a constant for SMS notification type
 	NotificationTypeSMS = "sms"
 )
+
+const (
+	// OTPPurposeVerification is the purpose of the OTPs checked by the OTP validation endpoint
+	OTPPurposeVerification = "verification"
+	// OTPPurposePasswordReset is the purpose of the OTPs only accepted by the password reset
+	OTPPurposePasswordReset = "password_reset"
+)
+
+// OTPMaxFailedAttempts is the number of wrong codes after which an OTP is invalidated
+const OTPMaxFailedAttempts = 5
+
+const (
+	// RoleUser is a constant for regular user role
+	RoleUser = "user"
+	// RoleAdmin is a constant for administrator role
+	RoleAdmin = "admin"
+	// RoleService is a constant for service accounts authenticating with an API key
+	RoleService = "service"
+)
+
+const (
+	// ScopeAuditRead allows reading the audit trail
+	ScopeAuditRead = "audit:read"
+	// ScopeUsersRead allows listing users
+	ScopeUsersRead = "users:read"
+	// ScopeUsersWrite allows managing users
+	ScopeUsersWrite = "users:write"
+)
+
+const (
+	// AuditActionRegister is recorded when a user registers
+	AuditActionRegister = "user.register"
+	// AuditActionLogin is recorded when a user logs in successfully
+	AuditActionLogin = "user.login"
+	// AuditActionLoginFailed is recorded when a login attempt is rejected
+	AuditActionLoginFailed = "user.login_failed"
+	// AuditActionPasswordChange is recorded when a user password is changed
+	AuditActionPasswordChange = "user.password_change"
+	// AuditActionRoleChange is recorded when a user role is changed
+	AuditActionRoleChange = "user.role_change"
+	// AuditActionSuspend is recorded when an admin suspends a user
+	AuditActionSuspend = "user.suspend"
+	// AuditActionUnsuspend is recorded when an admin lifts a user suspension
+	AuditActionUnsuspend = "user.unsuspend"
+	// AuditActionForcePasswordReset is recorded when an admin forces a user to reset their password
+	AuditActionForcePasswordReset = "user.force_password_reset"
+	// AuditActionRevokeSessions is recorded when an admin revokes every session of a user
+	AuditActionRevokeSessions = "user.revoke_sessions"
+	// AuditActionOTPIssue is recorded when an OTP is issued
+	AuditActionOTPIssue = "otp.issue"
+	// AuditActionOTPValidate is recorded when an OTP is validated successfully
+	AuditActionOTPValidate = "otp.validate"
+	// AuditActionOTPValidateFailed is recorded when an OTP validation is rejected
+	AuditActionOTPValidateFailed = "otp.validate_failed"
+	// AuditActionAPIKeyCreate is recorded when an admin creates an API key
+	AuditActionAPIKeyCreate = "api_key.create"
+	// AuditActionAPIKeyRevoke is recorded when an admin revokes an API key
+	AuditActionAPIKeyRevoke = "api_key.revoke"
+	// AuditActionLogLevelChange is recorded when an admin changes a log level at runtime
+	AuditActionLogLevelChange = "logger.level_change"
+)
@@ -0,0 +1,15 @@
+package models
+
+type LogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=debug log info warn critical"`
+	// LayerName scopes the level to squads created with this layer, empty sets the global level
+	LayerName string `json:"layer_name" validate:"max=128"`
+	// Interceptor sets the minimum level of the active interceptor instead of the logger
+	Interceptor bool `json:"interceptor" validate:"excluded_with=LayerName"`
+}
+
+type LogLevelsResponse struct {
+	Global      string            `json:"global"`
+	Interceptor string            `json:"interceptor"`
+	Layers      map[string]string `json:"layers"`
+}
@@ -9,21 +9,19 @@ type OTPLog struct {
 	UserID           int64  `gorm:"not null"`
 	OTPCode          string `gorm:"not null"`
 	NotificationType string `gorm:"not null"`
+	Purpose          string `gorm:"not null;default:verification"`
 	Status           string `gorm:"not null"`
+	FailedAttempts   int    `gorm:"not null;default:0"`
 	CreatedAt        time.Time
 	ExpiredAt        time.Time
 }
 
 type OTPRequest struct {
-	UserID int64 `json:"user_id"`
+	Email   string `json:"email" validate:"required,email"`
+	Purpose string `json:"purpose" validate:"required,oneof=verification password_reset"`
 }
 
 type OTPValidateRequest struct {
-	UserID int64  `json:"user_id"`
-	OTP    string `json:"otp"`
-}
-
-type OTPResponse struct {
-	UserID int64  `json:"user_id"`
-	OTP    string `json:"otp"`
+	Email string `json:"email" validate:"required,email"`
+	OTP   string `json:"otp" validate:"required"`
 }
@@ -14,15 +14,20 @@ type User struct {
 	Email       string    `gorm:"not null;" json:"email"`
 	PhoneNumber string    `gorm:"not null;" json:"phone_number"`
 	Password    string    `gorm:"not null;" json:"-"`
+	Role        string    `gorm:"not null;size:32;default:user" json:"role"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	PasswordResetRequired bool       `gorm:"not null;default:false" json:"password_reset_required"`
+	TokenVersion          int64      `gorm:"not null;default:0" json:"-"`
 }
 
 type RegisterUser struct {
 	Name        string `json:"name" validate:"required"`
 	Email       string `json:"email" validate:"required,email"`
 	PhoneNumber string `json:"phone_number" validate:"phone_number"`
-	Password    string `json:"password" validate:"required,min=6"`
+	Password    string `json:"password" validate:"required,password_policy"`
 }
 
 type LoginUser struct {
@@ -30,6 +35,22 @@ type LoginUser struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ResetPasswordRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	OTP      string `json:"otp" validate:"required"`
+	Password string `json:"password" validate:"required,password_policy"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type UserFilter struct {
+	Search string
+	Page   int
+	Size   int
+}
+
 type UserInfo struct {
 	UserID int    `json:"user_id"`
 	Name   string `json:"name"`
@@ -42,6 +63,11 @@ type LoginResponse struct {
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
-	u.Password = helper.HashPassword(u.Password)
+	hash, errx := helper.HashPassword(u.Password)
+	if errx != nil {
+		return errx
+	}
+
+	u.Password = hash
 	return
 }
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType type
+type FieldType int
+
+const (
+	// FieldTypeAny field holding any value, structs and maps are marshaled by the structured interceptors
+	FieldTypeAny FieldType = iota
+
+	// FieldTypeString field
+	FieldTypeString
+
+	// FieldTypeInt field
+	FieldTypeInt
+
+	// FieldTypeDuration field
+	FieldTypeDuration
+
+	// FieldTypeError field
+	FieldTypeError
+)
+
+// FieldKeyError is the key of the fields created with Error
+const FieldKeyError = "error"
+
+// Field is a typed key value attached to the log entries with With, unlike the squad tags
+// its value is neither limited in length nor turned into a string
+type Field struct {
+	Key      string
+	Type     FieldType
+	String   string
+	Int      int64
+	Duration time.Duration
+	Err      error
+	Any      interface{}
+}
+
+// String returns a string field
+func String(key string, value string) Field {
+	return Field{Key: key, Type: FieldTypeString, String: value}
+}
+
+// Int returns an integer field
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: FieldTypeInt, Int: int64(value)}
+}
+
+// Int64 returns an integer field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: FieldTypeInt, Int: value}
+}
+
+// Duration returns a duration field
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: FieldTypeDuration, Duration: value}
+}
+
+// Error returns an error field under FieldKeyError
+func Error(err error) Field {
+	return NamedError(FieldKeyError, err)
+}
+
+// NamedError returns an error field under key
+func NamedError(key string, err error) Field {
+	return Field{Key: key, Type: FieldTypeError, Err: err}
+}
+
+// Any returns a field holding value as it is
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Type: FieldTypeAny, Any: value}
+}
+
+// Value returns the field value, durations and errors are given as their string form
+func (ox Field) Value() interface{} {
+	switch ox.Type {
+	case FieldTypeString:
+		return ox.String
+
+	case FieldTypeInt:
+		return ox.Int
+
+	case FieldTypeDuration:
+		return ox.Duration.String()
+
+	case FieldTypeError:
+		if ox.Err == nil {
+			return nil
+		}
+
+		return ox.Err.Error()
+	}
+
+	return ox.Any
+}
+
+// Text returns the field value as printed by the plain text interceptors
+func (ox Field) Text() string {
+	switch vx := ox.Value().(type) {
+	case string:
+		if vx == "" || strings.ContainsAny(vx, " \t\n\"=") {
+			return strconv.Quote(vx)
+		}
+
+		return vx
+
+	case nil:
+		return "<nil>"
+
+	default:
+		return fmt.Sprintf("%v", vx)
+	}
+}
+
+// private
+
+// mergeFields returns the fields followed by the extra ones, an extra field replaces the field having its key
+func mergeFields(fields []Field, extra []Field) []Field {
+	res := make([]Field, 0, len(fields)+len(extra))
+	for _, v := range fields {
+		if !hasField(extra, v.Key) {
+			res = append(res, v)
+		}
+	}
+
+	for i, v := range extra {
+		if !hasField(extra[i+1:], v.Key) {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func hasField(fields []Field, key string) bool {
+	for _, v := range fields {
+		if v.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldValues returns the fields as a map, it is nil when there are none
+func fieldValues(fields []Field) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	res := make(map[string]interface{}, len(fields))
+	for _, v := range fields {
+		res[v.Key] = v.Value()
+	}
+
+	return res
+}
+
+// fieldsText returns the fields as space separated key=value pairs
+func fieldsText(fields []Field) string {
+	pairs := make([]string, len(fields))
+	for i, v := range fields {
+		pairs[i] = v.Key + "=" + v.Text()
+	}
+
+	return strings.Join(pairs, " ")
+}
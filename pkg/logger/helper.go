@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"syscall"
 
@@ -17,6 +18,28 @@ func init() {
 	}
 }
 
+// SetDefault to replace the default logger instance, the previous instance gets flushed and closed
+func SetDefault(log Logger) error {
+	if log == nil {
+		panic("Null logger")
+	}
+
+	err := log.Startup()
+	if err != nil {
+		return err
+	}
+
+	previous := defaultInstance
+	defaultInstance = log
+
+	return previous.Close()
+}
+
+// Default returns the default logger instance
+func Default() Logger {
+	return defaultInstance
+}
+
 // SetInterceptor to set log interceptor
 func SetInterceptor(intercept LogInterceptor) {
 	if intercept == nil {
@@ -26,11 +49,41 @@ func SetInterceptor(intercept LogInterceptor) {
 	defaultInstance.SetInterceptor(intercept)
 }
 
+// SetRedactor to replace the default logger redactor, nil disables the redaction
+func SetRedactor(redactor *Redactor) {
+	defaultInstance.SetRedactor(redactor)
+}
+
 // CreateSquad returns the logger squad instance
 func CreateSquad(ctx context.Context, layerName string) LogSquad {
 	return defaultInstance.CreateSquad(ctx, layerName)
 }
 
+// SetLevel to set the global minimum level of the default logger
+func SetLevel(lvl ErrorLevel) {
+	defaultInstance.SetLevel(lvl)
+}
+
+// SetLayerLevel to override the minimum level of squads created with layerName
+func SetLayerLevel(layerName string, lvl ErrorLevel) {
+	defaultInstance.SetLayerLevel(layerName, lvl)
+}
+
+// ClearLayerLevel to remove the layerName override
+func ClearLayerLevel(layerName string) {
+	defaultInstance.ClearLayerLevel(layerName)
+}
+
+// Debug to logging debug level
+func Debug(msg interface{}) {
+	defaultInstance.Debug(msg)
+}
+
+// Debugf to logging debug level with function
+func Debugf(msg string, args ...interface{}) {
+	defaultInstance.Debugf(msg, args...)
+}
+
 // Info to logging info level
 func Info(msg interface{}) {
 	defaultInstance.Info(msg)
@@ -76,7 +129,18 @@ func Panic(msg interface{}) {
 	defaultInstance.Panic(msg)
 }
 
-func exit() {
+// With returns a squad of the default logger instance adding fields to every entry
+func With(fields ...Field) LogSquad {
+	return defaultInstance.With(fields...)
+}
+
+// exit closes log so the queued entries reach the file and the sinks, then interrupts the process to run
+// the graceful shutdown, the process exits right away when the signal cannot be sent
+func exit(log Logger) {
+	if err := log.Close(); err != nil {
+		DefaultStderr(fmt.Sprintf("Failed to close the logger, details: %v", err))
+	}
+
 	err := syscall.Kill(syscall.Getpid(), syscall.SIGINT)
 	if err != nil {
 		os.Exit(1)
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy decides which entry is discarded when an async queue is full
+type DropPolicy int
+
+const (
+	// DropNewest discards the incoming entry
+	DropNewest DropPolicy = 1 + iota
+
+	// DropOldest discards the longest queued entry to make room for the incoming one
+	DropOldest
+)
+
+// DefaultAsyncQueueSize is used when no queue size is configured
+const DefaultAsyncQueueSize = 1024
+
+type (
+	// AsyncOptions type
+	AsyncOptions struct {
+		// QueueSize is how many entries may wait to be processed
+		QueueSize int
+		// DropPolicy is applied once the queue is full, DropNewest by default
+		DropPolicy DropPolicy
+		// OnDrop is called with every discarded entry, it must not block
+		OnDrop func(lvl ErrorLevel, msg string)
+	}
+
+	// AsyncStats holds the async queue counters
+	AsyncStats struct {
+		Capacity  int    `json:"capacity"`
+		Queued    int    `json:"queued"`
+		Enqueued  uint64 `json:"enqueued"`
+		Processed uint64 `json:"processed"`
+		Dropped   uint64 `json:"dropped"`
+	}
+
+	// Async interceptor processing entries in the background
+	Async interface {
+		LogDispatcher
+		// Stats returns the queue counters
+		Stats() AsyncStats
+		// Unwrap returns the wrapped interceptor
+		Unwrap() LogInterceptor
+		// Close to stop accepting entries and wait until the queue is drained
+		Close() error
+	}
+
+	asyncEntry struct {
+		args     LogInterceptorTranslateArguments
+		msg      string
+		dispatch bool
+	}
+
+	asyncInterceptorObj struct {
+		mu        sync.RWMutex
+		closed    bool
+		queue     chan asyncEntry
+		done      chan struct{}
+		policy    DropPolicy
+		onDrop    func(lvl ErrorLevel, msg string)
+		intercept LogInterceptor
+
+		enqueued  uint64
+		processed uint64
+		dropped   uint64
+	}
+)
+
+// AsyncInterceptor wraps intercept so Process returns immediately and the entry is processed by a background worker,
+// Translate still runs on the caller goroutine
+func AsyncInterceptor(intercept LogInterceptor, opt AsyncOptions) Async {
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = DefaultAsyncQueueSize
+	}
+
+	if opt.DropPolicy == 0 {
+		opt.DropPolicy = DropNewest
+	}
+
+	ox := &asyncInterceptorObj{
+		queue:     make(chan asyncEntry, opt.QueueSize),
+		done:      make(chan struct{}),
+		policy:    opt.DropPolicy,
+		onDrop:    opt.OnDrop,
+		intercept: intercept,
+	}
+
+	go ox.run()
+	return ox
+}
+
+func (ox *asyncInterceptorObj) Translate(args LogInterceptorTranslateArguments) string {
+	return ox.intercept.Translate(args)
+}
+
+func (ox *asyncInterceptorObj) Process(lvl ErrorLevel, msg string) {
+	ox.enqueue(asyncEntry{args: LogInterceptorTranslateArguments{Level: lvl}, msg: msg})
+}
+
+// Dispatch queues the untranslated entry too, so a wrapped LogDispatcher still gets the payload
+func (ox *asyncInterceptorObj) Dispatch(args LogInterceptorTranslateArguments, msg string) {
+	ox.enqueue(asyncEntry{args: args, msg: msg, dispatch: true})
+}
+
+func (ox *asyncInterceptorObj) Stats() AsyncStats {
+	return AsyncStats{
+		Capacity:  cap(ox.queue),
+		Queued:    len(ox.queue),
+		Enqueued:  atomic.LoadUint64(&ox.enqueued),
+		Processed: atomic.LoadUint64(&ox.processed),
+		Dropped:   atomic.LoadUint64(&ox.dropped),
+	}
+}
+
+func (ox *asyncInterceptorObj) Unwrap() LogInterceptor {
+	return ox.intercept
+}
+
+func (ox *asyncInterceptorObj) Close() error {
+	ox.mu.Lock()
+	if !ox.closed {
+		ox.closed = true
+		close(ox.queue)
+	}
+	ox.mu.Unlock()
+
+	<-ox.done
+	return nil
+}
+
+// private
+
+func (ox *asyncInterceptorObj) run() {
+	defer close(ox.done)
+
+	for entry := range ox.queue {
+		if entry.dispatch {
+			dispatch(ox.intercept, entry.args, entry.msg)
+		} else {
+			ox.intercept.Process(entry.args.Level, entry.msg)
+		}
+
+		atomic.AddUint64(&ox.processed, 1)
+	}
+}
+
+func (ox *asyncInterceptorObj) enqueue(entry asyncEntry) {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	if ox.closed {
+		ox.drop(entry)
+		return
+	}
+
+	for {
+		select {
+		case ox.queue <- entry:
+			atomic.AddUint64(&ox.enqueued, 1)
+			return
+
+		default:
+		}
+
+		if ox.policy != DropOldest {
+			ox.drop(entry)
+			return
+		}
+
+		select {
+		case oldest := <-ox.queue:
+			ox.drop(oldest)
+
+		default:
+		}
+	}
+}
+
+func (ox *asyncInterceptorObj) drop(entry asyncEntry) {
+	atomic.AddUint64(&ox.dropped, 1)
+
+	if ox.onDrop != nil {
+		ox.onDrop(entry.args.Level, entry.msg)
+	}
+}
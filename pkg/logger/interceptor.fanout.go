@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"io"
+
+	"codepair-sinarmas/pkg/serror"
+)
+
+type (
+	// FanoutSink is a single destination of the fanout interceptor
+	FanoutSink struct {
+		// Name identifies the sink, it must be unique within the fanout
+		Name string
+		// Interceptor translates and processes the entries of this sink
+		Interceptor LogInterceptor
+		// Level is the sink minimum level, every level is processed when empty
+		Level ErrorLevel
+	}
+
+	// LogDispatcher is an interceptor needing the untranslated entry, the logger calls Dispatch instead of Process
+	LogDispatcher interface {
+		LogInterceptor
+		Dispatch(args LogInterceptorTranslateArguments, msg string)
+	}
+
+	// Fanout interceptor dispatching every entry to multiple sinks
+	Fanout interface {
+		LogDispatcher
+		// Sink returns the named sink
+		Sink(name string) (LeveledInterceptor, bool)
+		// SetSinkLevel to change the named sink minimum level
+		SetSinkLevel(name string, lvl ErrorLevel) serror.SError
+		// SinkLevels returns the minimum level of every sink
+		SinkLevels() map[string]ErrorLevel
+		// Stats returns the queue counters of every async sink
+		Stats() map[string]AsyncStats
+		// Close to close every sink holding resources
+		Close() error
+	}
+
+	fanoutSinkObj struct {
+		name      string
+		intercept LeveledInterceptor
+	}
+
+	fanoutInterceptorObj struct {
+		sinks []fanoutSinkObj
+	}
+)
+
+// FanoutInterceptor to create an interceptor dispatching to sinks, the first sink translation is the one written to the log file
+func FanoutInterceptor(sinks ...FanoutSink) (obj Fanout, errx serror.SError) {
+	if len(sinks) == 0 {
+		return obj, serror.New("Fanout interceptor needs at least one sink")
+	}
+
+	fanout := &fanoutInterceptorObj{}
+	names := map[string]bool{}
+
+	for _, v := range sinks {
+		switch {
+		case v.Name == "":
+			return obj, serror.New("Fanout sink name is required")
+
+		case names[v.Name]:
+			return obj, serror.Newf("Fanout sink %s is duplicated", v.Name)
+
+		case v.Interceptor == nil:
+			return obj, serror.Newf("Fanout sink %s has no interceptor", v.Name)
+
+		case v.Level != "" && !v.Level.IsValid():
+			return obj, serror.Newf("Fanout sink %s has an unknown level %s", v.Name, v.Level)
+		}
+
+		lvl := v.Level
+		if lvl == "" {
+			lvl = ErrorLevelDebug
+		}
+
+		names[v.Name] = true
+		fanout.sinks = append(fanout.sinks, fanoutSinkObj{
+			name:      v.Name,
+			intercept: &leveledInterceptorObj{level: lvl, intercept: v.Interceptor},
+		})
+	}
+
+	return fanout, errx
+}
+
+func (ox *fanoutInterceptorObj) Translate(args LogInterceptorTranslateArguments) string {
+	return ox.sinks[0].intercept.Translate(args)
+}
+
+// Process sends the same msg to every sink, Dispatch is used by the logger so each sink translates on its own
+func (ox *fanoutInterceptorObj) Process(lvl ErrorLevel, msg string) {
+	for _, v := range ox.sinks {
+		v.intercept.Process(lvl, msg)
+	}
+}
+
+func (ox *fanoutInterceptorObj) Dispatch(args LogInterceptorTranslateArguments, msg string) {
+	for i, v := range ox.sinks {
+		if !v.intercept.MinLevel().Allows(args.Level) {
+			continue
+		}
+
+		// the first translation is redacted by the logger, the other sinks are redacted here
+		m := msg
+		if i > 0 {
+			m = args.redactor.String(v.intercept.Translate(args))
+		}
+
+		dispatch(v.intercept.Unwrap(), args, m)
+	}
+}
+
+func (ox *fanoutInterceptorObj) Sink(name string) (LeveledInterceptor, bool) {
+	for _, v := range ox.sinks {
+		if v.name == name {
+			return v.intercept, true
+		}
+	}
+
+	return nil, false
+}
+
+func (ox *fanoutInterceptorObj) SetSinkLevel(name string, lvl ErrorLevel) serror.SError {
+	if !lvl.IsValid() {
+		return serror.Newf("Unknown level %s", lvl)
+	}
+
+	sink, ok := ox.Sink(name)
+	if !ok {
+		return serror.Newf("Fanout sink %s not found", name)
+	}
+
+	sink.SetMinLevel(lvl)
+	return nil
+}
+
+func (ox *fanoutInterceptorObj) SinkLevels() map[string]ErrorLevel {
+	res := make(map[string]ErrorLevel, len(ox.sinks))
+	for _, v := range ox.sinks {
+		res[v.name] = v.intercept.MinLevel()
+	}
+
+	return res
+}
+
+func (ox *fanoutInterceptorObj) Stats() map[string]AsyncStats {
+	res := map[string]AsyncStats{}
+	for _, v := range ox.sinks {
+		if cur, ok := v.intercept.Unwrap().(Async); ok {
+			res[v.name] = cur.Stats()
+		}
+	}
+
+	return res
+}
+
+func (ox *fanoutInterceptorObj) Close() (err error) {
+	for _, v := range ox.sinks {
+		if cur, ok := v.intercept.Unwrap().(io.Closer); ok {
+			if errs := cur.Close(); errs != nil && err == nil {
+				err = errs
+			}
+		}
+	}
+
+	return err
+}
+
+// dispatch hands a translated entry to intercept, passing the untranslated one along when it is a LogDispatcher
+func dispatch(intercept LogInterceptor, args LogInterceptorTranslateArguments, msg string) {
+	if cur, ok := intercept.(LogDispatcher); ok {
+		cur.Dispatch(args, msg)
+		return
+	}
+
+	intercept.Process(args.Level, msg)
+}
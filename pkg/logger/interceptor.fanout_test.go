@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type prefixInterceptor struct {
+	recordInterceptor
+	prefix string
+}
+
+func (ox *prefixInterceptor) Translate(args LogInterceptorTranslateArguments) string {
+	return fmt.Sprintf("%s|%s:%v", ox.prefix, args.Level, args.Payload)
+}
+
+func TestFanoutInterceptor_Options(t *testing.T) {
+	record := &recordInterceptor{}
+
+	testTable := []struct {
+		name  string
+		sinks []FanoutSink
+	}{
+		{name: "no sink"},
+		{name: "no name", sinks: []FanoutSink{{Interceptor: record}}},
+		{name: "no interceptor", sinks: []FanoutSink{{Name: "console"}}},
+		{name: "duplicated", sinks: []FanoutSink{{Name: "console", Interceptor: record}, {Name: "console", Interceptor: record}}},
+		{name: "unknown level", sinks: []FanoutSink{{Name: "console", Interceptor: record, Level: "verbose"}}},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errx := FanoutInterceptor(tc.sinks...)
+			assert.NotNil(t, errx)
+		})
+	}
+}
+
+func TestFanoutInterceptor_Dispatch(t *testing.T) {
+	var (
+		console = &prefixInterceptor{prefix: "console"}
+		json    = &prefixInterceptor{prefix: "json"}
+		remote  = &prefixInterceptor{prefix: "remote"}
+	)
+
+	fanout, errx := FanoutInterceptor(
+		FanoutSink{Name: "console", Interceptor: console},
+		FanoutSink{Name: "json", Interceptor: json, Level: ErrorLevelInfo},
+		FanoutSink{Name: "remote", Interceptor: remote, Level: ErrorLevelCritical},
+	)
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Level: ErrorLevelDebug, Interceptor: fanout})
+	log.Debug("a")
+	log.Info("b")
+	log.Err("c")
+
+	assert.Equal(t, []string{"console|debug:a", "console|info:b", "console|critical:c"}, console.flush())
+	assert.Equal(t, []string{"json|info:b", "json|critical:c"}, json.flush())
+	assert.Equal(t, []string{"remote|critical:c"}, remote.flush())
+
+	assert.Nil(t, fanout.SetSinkLevel("remote", ErrorLevelInfo))
+	assert.NotNil(t, fanout.SetSinkLevel("missing", ErrorLevelInfo))
+	assert.Equal(t, map[string]ErrorLevel{
+		"console": ErrorLevelDebug,
+		"json":    ErrorLevelInfo,
+		"remote":  ErrorLevelInfo,
+	}, fanout.SinkLevels())
+
+	log.SetInterceptorLevel(ErrorLevelInfo)
+	log.Debug("d")
+	log.Info("e")
+
+	assert.Equal(t, []string{"console|info:e"}, console.flush())
+	assert.Equal(t, []string{"remote|info:e"}, remote.flush())
+}
+
+type blockingInterceptor struct {
+	recordInterceptor
+	release chan struct{}
+}
+
+func (ox *blockingInterceptor) Process(lvl ErrorLevel, msg string) {
+	<-ox.release
+	ox.recordInterceptor.Process(lvl, msg)
+}
+
+func TestAsyncInterceptor_DropPolicy(t *testing.T) {
+	testTable := []struct {
+		name   string
+		policy DropPolicy
+		want   []string
+	}{
+		{name: "drop newest", policy: DropNewest, want: []string{"0", "1", "2"}},
+		{name: "drop oldest", policy: DropOldest, want: []string{"0", "3", "4"}},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				sink    = &blockingInterceptor{release: make(chan struct{})}
+				dropped []string
+			)
+
+			async := AsyncInterceptor(sink, AsyncOptions{
+				QueueSize:  2,
+				DropPolicy: tc.policy,
+				OnDrop: func(lvl ErrorLevel, msg string) {
+					dropped = append(dropped, msg)
+				},
+			})
+
+			// the worker holds "0" while the queue fills up
+			async.Process(ErrorLevelInfo, "0")
+			for async.Stats().Queued != 0 {
+				runtime.Gosched()
+			}
+
+			for i := 1; i < 5; i++ {
+				async.Process(ErrorLevelInfo, fmt.Sprint(i))
+			}
+
+			stats := async.Stats()
+			assert.Equal(t, 2, stats.Queued)
+			assert.Equal(t, uint64(2), stats.Dropped)
+			assert.Len(t, dropped, 2)
+
+			close(sink.release)
+			assert.Nil(t, async.Close())
+			assert.Equal(t, tc.want, sink.flush())
+
+			async.Process(ErrorLevelInfo, "late")
+			stats = async.Stats()
+			assert.Equal(t, uint64(3), stats.Processed)
+			assert.Equal(t, uint64(3), stats.Dropped)
+		})
+	}
+}
+
+func TestLogger_CloseDrainsAsyncSinks(t *testing.T) {
+	remote := &recordInterceptor{}
+	async := AsyncInterceptor(remote, AsyncOptions{QueueSize: 16})
+
+	fanout, errx := FanoutInterceptor(
+		FanoutSink{Name: "console", Interceptor: &recordInterceptor{}},
+		FanoutSink{Name: "remote", Interceptor: async, Level: ErrorLevelWarning},
+	)
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Interceptor: fanout})
+	for i := 0; i < 10; i++ {
+		log.Warnf("entry %d", i)
+	}
+
+	assert.Nil(t, log.Close())
+	assert.Len(t, remote.flush(), 10)
+	assert.Equal(t, uint64(10), fanout.Stats()["remote"].Processed)
+}
+
+func TestFanoutInterceptor_Redaction(t *testing.T) {
+	// the sinks add the same sensitive value while translating
+	var (
+		console = &prefixInterceptor{prefix: "console token=abc"}
+		json    = &prefixInterceptor{prefix: "json token=abc"}
+	)
+
+	fanout, errx := FanoutInterceptor(
+		FanoutSink{Name: "console", Interceptor: console},
+		FanoutSink{Name: "json", Interceptor: json},
+	)
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Level: ErrorLevelDebug, Interceptor: fanout, Redactor: DefaultRedactor()})
+	log.Info("login")
+
+	assert.Equal(t, []string{"console token=[REDACTED]"}, console.flush())
+	assert.Equal(t, []string{"json token=[REDACTED]"}, json.flush())
+}
@@ -3,20 +3,62 @@ package logger
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"runtime"
 	"strings"
 	"time"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FJSONProfile selects the layout of the fjson entries
+type FJSONProfile string
+
+const (
+	// FJSONProfileDefault writes the key, name, version, level, timestamp, tags and payload of the entry
+	FJSONProfileDefault FJSONProfile = "default"
+	// FJSONProfileECS writes Elastic Common Schema fields
+	FJSONProfileECS FJSONProfile = "ecs"
+	// FJSONProfileGCP writes Google Cloud Logging structured fields
+	FJSONProfileGCP FJSONProfile = "gcp"
 )
 
+// ecsVersion is the Elastic Common Schema version the ecs profile follows
+const ecsVersion = "8.11"
+
 type (
 	// FJSONOptions type
 	FJSONOptions struct {
-		Key      string `json:"key" valid:"required"`
-		Name     string `json:"name" valid:"required"`
-		Version  string `json:"version" valid:"required"`
-		Printing bool   `json:"printing"`
+		Key      string       `json:"key" validate:"required"`
+		Name     string       `json:"name" validate:"required"`
+		Version  string       `json:"version" validate:"required"`
+		Printing bool         `json:"printing"`
+		Profile  FJSONProfile `json:"profile" validate:"omitempty,oneof=default ecs gcp"`
+		// Fields renames the profile fields, empty names keep the profile ones
+		Fields FJSONFields `json:"fields"`
+		// Caller adds the file, line and function which logged the entry, or where the logged error was created
+		Caller bool `json:"caller"`
+		// Clock returns the entry timestamp, time.Now when nil
+		Clock func() time.Time `json:"-"`
 	}
 
-	// FJSON interceptor
+	// FJSONFields are the names of the fjson fields, the ecs and gcp profiles use them for the non standard ones only
+	FJSONFields struct {
+		Key       string `json:"key"`
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+		Level     string `json:"level"`
+		Timestamp string `json:"timestamp"`
+		Message   string `json:"message"`
+		Tags      string `json:"tags"`
+		Fields    string `json:"fields"`
+		Payload   string `json:"payload"`
+		Caller    string `json:"caller"`
+	}
+
+	// FJSON interceptor writing one JSON object per entry, disabling it falls back to the plain text lines
 	FJSON interface {
 		LogInterceptor
 		IsEnabled() bool
@@ -30,26 +72,123 @@ type (
 		Version  string
 		Printing bool
 		Enabled  bool
+		Profile  FJSONProfile
+		Fields   FJSONFields
+		Caller   bool
+		clock    func() time.Time
+	}
+
+	fjsonCaller struct {
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+		Function string `json:"function"`
 	}
 )
 
+// fjsonProfileFields are the field names of each profile
+var fjsonProfileFields = map[FJSONProfile]FJSONFields{
+	FJSONProfileDefault: {
+		Key:       "key",
+		Name:      "name",
+		Version:   "version",
+		Level:     "level",
+		Timestamp: "timestamp",
+		Message:   "message",
+		Tags:      "tags",
+		Fields:    "fields",
+		Payload:   "payload",
+		Caller:    "caller",
+	},
+	FJSONProfileECS: {
+		Key:       "service.node.name",
+		Name:      "service.name",
+		Version:   "service.version",
+		Level:     "log.level",
+		Timestamp: "@timestamp",
+		Message:   "message",
+		Tags:      "labels",
+		Fields:    "fields",
+		Payload:   "payload",
+		Caller:    "log.origin",
+	},
+	FJSONProfileGCP: {
+		Key:       "serviceKey",
+		Name:      "serviceContext",
+		Version:   "serviceContext",
+		Level:     "severity",
+		Timestamp: "time",
+		Message:   "message",
+		Tags:      "logging.googleapis.com/labels",
+		Fields:    "fields",
+		Payload:   "payload",
+		Caller:    "logging.googleapis.com/sourceLocation",
+	},
+}
+
+// gcpSeverities maps the levels to the Cloud Logging severities
+var gcpSeverities = map[ErrorLevel]string{
+	ErrorLevelDebug:    "DEBUG",
+	ErrorLevelLog:      "DEFAULT",
+	ErrorLevelInfo:     "INFO",
+	ErrorLevelWarning:  "WARNING",
+	ErrorLevelCritical: "ERROR",
+}
+
+// FJSONInterceptor to create JSON lines interceptor
+func FJSONInterceptor(opt FJSONOptions) (obj FJSON, errx serror.SError) {
+	validate := validator.New()
+	if err := validate.Struct(opt); err != nil {
+		errx = serror.NewFromErrorc(err, "Invalid fjson options")
+		return obj, errx
+	}
+
+	if opt.Profile == "" {
+		opt.Profile = FJSONProfileDefault
+	}
+
+	fields := fjsonProfileFields[opt.Profile]
+	overrides := reflect.ValueOf(opt.Fields)
+	target := reflect.ValueOf(&fields).Elem()
+	for i := 0; i < overrides.NumField(); i++ {
+		if v := overrides.Field(i).String(); v != "" {
+			target.Field(i).SetString(v)
+		}
+	}
+
+	clock := opt.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
+	obj = &fjsonInterceptorObj{
+		Key:      opt.Key,
+		Name:     opt.Name,
+		Version:  opt.Version,
+		Printing: opt.Printing,
+		Enabled:  true,
+		Profile:  opt.Profile,
+		Fields:   fields,
+		Caller:   opt.Caller,
+		clock:    clock,
+	}
+	return obj, errx
+}
+
 func (ox fjsonInterceptorObj) Translate(args LogInterceptorTranslateArguments) string {
-	data := struct {
-		Key       string            `json:"key"`
-		Name      string            `json:"name"`
-		Version   string            `json:"version"`
-		Level     string            `json:"level"`
-		Timestamp time.Time         `json:"timestamp"`
-		Tags      map[string]string `json:"tags,omitempty"`
-		Payload   interface{}       `json:"payload"`
-	}{
-		Key:       ox.Key,
-		Name:      ox.Name,
-		Version:   ox.Version,
-		Level:     strings.ToUpper(string(args.Level)),
-		Timestamp: time.Now(),
-		Tags:      args.Tags,
-		Payload:   args.Payload,
+	if !ox.Enabled {
+		return DefaultTranslate(args, 2)
+	}
+
+	var data map[string]interface{}
+	switch ox.Profile {
+	case FJSONProfileECS:
+		data = ox.ecsEntry(args)
+
+	case FJSONProfileGCP:
+		data = ox.gcpEntry(args)
+
+	default:
+		data = ox.defaultEntry(args)
 	}
 
 	byt, err := json.Marshal(data)
@@ -86,3 +225,198 @@ func (ox *fjsonInterceptorObj) StopPrinting() {
 func (ox *fjsonInterceptorObj) StartPrinting() {
 	ox.Printing = true
 }
+
+// private
+
+func (ox fjsonInterceptorObj) defaultEntry(args LogInterceptorTranslateArguments) map[string]interface{} {
+	data := map[string]interface{}{
+		ox.Fields.Key:       ox.Key,
+		ox.Fields.Name:      ox.Name,
+		ox.Fields.Version:   ox.Version,
+		ox.Fields.Level:     strings.ToUpper(string(args.Level)),
+		ox.Fields.Timestamp: ox.clock(),
+		ox.Fields.Message:   payloadMessage(args.Payload),
+		ox.Fields.Payload:   args.Payload,
+	}
+
+	if len(args.Tags) > 0 {
+		data[ox.Fields.Tags] = args.Tags
+	}
+
+	if len(args.Fields) > 0 {
+		data[ox.Fields.Fields] = fieldValues(args.Fields)
+	}
+
+	// plain errors have nothing exported to marshal
+	if _, ok := args.Payload.(json.Marshaler); !ok {
+		if err, ok := args.Payload.(error); ok {
+			data[ox.Fields.Payload] = err.Error()
+		}
+	}
+
+	if ox.Caller {
+		data[ox.Fields.Caller] = fjsonCallerOf(args.Payload)
+	}
+
+	return data
+}
+
+func (ox fjsonInterceptorObj) ecsEntry(args LogInterceptorTranslateArguments) map[string]interface{} {
+	data := map[string]interface{}{
+		ox.Fields.Timestamp: ox.clock().UTC().Format(time.RFC3339Nano),
+		ox.Fields.Level:     string(args.Level),
+		ox.Fields.Message:   payloadMessage(args.Payload),
+		ox.Fields.Key:       ox.Key,
+		ox.Fields.Name:      ox.Name,
+		ox.Fields.Version:   ox.Version,
+		"ecs.version":       ecsVersion,
+	}
+
+	if len(args.Tags) > 0 {
+		data[ox.Fields.Tags] = args.Tags
+	}
+
+	if len(args.Fields) > 0 {
+		data[ox.Fields.Fields] = fieldValues(args.Fields)
+	}
+
+	switch vx := args.Payload.(type) {
+	case serror.SError:
+		data["error.message"] = vx.Error()
+		data["error.type"] = vx.Type()
+		data["error.stack_trace"] = fjsonStackTrace(vx)
+		if key := vx.Key(); key != "" && key != "-" {
+			data["error.code"] = key
+		}
+		if comments := vx.CommentStack(); len(comments) > 0 {
+			data["error.comments"] = comments
+		}
+
+	case error:
+		data["error.message"] = vx.Error()
+		data["error.type"] = errorTypeName(vx)
+
+	case string:
+
+	default:
+		data[ox.Fields.Payload] = vx
+	}
+
+	if ox.Caller {
+		caller := fjsonCallerOf(args.Payload)
+		data[ox.Fields.Caller+".file.name"] = caller.File
+		data[ox.Fields.Caller+".file.line"] = caller.Line
+		data[ox.Fields.Caller+".function"] = caller.Function
+	}
+
+	return data
+}
+
+func (ox fjsonInterceptorObj) gcpEntry(args LogInterceptorTranslateArguments) map[string]interface{} {
+	data := map[string]interface{}{
+		ox.Fields.Timestamp: ox.clock().UTC().Format(time.RFC3339Nano),
+		ox.Fields.Level:     gcpSeverities[args.Level],
+		ox.Fields.Message:   payloadMessage(args.Payload),
+		ox.Fields.Key:       ox.Key,
+	}
+
+	service := map[string]string{"service": ox.Name, "version": ox.Version}
+	if ox.Fields.Name == ox.Fields.Version {
+		data[ox.Fields.Name] = service
+	} else {
+		data[ox.Fields.Name] = ox.Name
+		data[ox.Fields.Version] = ox.Version
+	}
+
+	if len(args.Tags) > 0 {
+		data[ox.Fields.Tags] = args.Tags
+	}
+
+	if len(args.Fields) > 0 {
+		data[ox.Fields.Fields] = fieldValues(args.Fields)
+	}
+
+	switch vx := args.Payload.(type) {
+	case serror.SError:
+		// Error Reporting groups the entries having a stack trace, the event type makes it pick up the others
+		data["@type"] = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+		data["stack_trace"] = vx.Error() + "\n" + fjsonStackTrace(vx)
+		if key := vx.Key(); key != "" && key != "-" {
+			data["errorKey"] = key
+		}
+		if comments := vx.CommentStack(); len(comments) > 0 {
+			data["errorComments"] = comments
+		}
+
+	case error, string:
+
+	default:
+		data[ox.Fields.Payload] = vx
+	}
+
+	if ox.Caller {
+		caller := fjsonCallerOf(args.Payload)
+		data[ox.Fields.Caller] = map[string]interface{}{
+			"file":     caller.File,
+			"line":     fmt.Sprintf("%d", caller.Line),
+			"function": caller.Function,
+		}
+	}
+
+	return data
+}
+
+// fjsonStackTrace formats the error frames the way Go prints a panic, innermost first
+func fjsonStackTrace(errx serror.SError) string {
+	var lines []string
+	for _, v := range errorFrames(errx) {
+		fn := v.Function
+		if v.Package != "" {
+			fn = v.Package + "." + fn
+		}
+
+		lines = append(lines, fmt.Sprintf("%s\n\t%s:%d", fn, v.File, v.Line))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// fjsonCallerOf returns where an error payload was created, or the first frame outside of the logger
+func fjsonCallerOf(payload interface{}) fjsonCaller {
+	if errx, ok := payload.(serror.SError); ok {
+		if frames := errorFrames(errx); len(frames) > 0 {
+			return fjsonCaller{File: frames[0].File, Line: frames[0].Line, Function: frames[0].Function}
+		}
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isLoggerFrame(frame) {
+			fn := frame.Function
+			if i := strings.LastIndex(fn, "/"); i >= 0 {
+				fn = fn[i+1:]
+			}
+
+			return fjsonCaller{File: frame.File, Line: frame.Line, Function: fn}
+		}
+
+		if !more {
+			return fjsonCaller{}
+		}
+	}
+}
+
+var loggerPkgPath = reflect.TypeOf(fjsonInterceptorObj{}).PkgPath()
+
+// isLoggerFrame reports whether frame belongs to the logger itself, its tests excluded
+func isLoggerFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	return strings.HasPrefix(frame.Function, loggerPkgPath+".") || strings.HasPrefix(frame.Function, "runtime.")
+}
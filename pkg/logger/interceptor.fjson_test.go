@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+// fjsonTestError has fixed frames so the golden files do not depend on the machine running the tests
+func fjsonTestError() serror.SError {
+	errx := serror.NewFromPayload(serror.Payload{
+		Code:     http.StatusConflict,
+		Key:      "user.email_taken",
+		Message:  "Email already registered",
+		Comments: []string{"[usecase][Register] Failed to register, [email: b***@example.com]"},
+		Frames: []serror.Frame{
+			{Function: "(*UserUsecase).Register", Package: "codepair-sinarmas/service/usecase", File: "/service/usecase/user.go", Line: 50},
+			{Function: "(*Handler).Register", Package: "codepair-sinarmas/service/handler/rest", File: "/service/handler/rest/handler.user.go", Line: 34},
+		},
+	})
+
+	return errx
+}
+
+func TestFJSONInterceptor_Options(t *testing.T) {
+	testCases := []struct {
+		name  string
+		opt   FJSONOptions
+		valid bool
+	}{
+		{name: "valid", opt: FJSONOptions{Key: "api-1", Name: "codepair-sinarmas", Version: "1.0.0"}, valid: true},
+		{name: "valid profile", opt: FJSONOptions{Key: "api-1", Name: "codepair-sinarmas", Version: "1.0.0", Profile: FJSONProfileGCP}, valid: true},
+		{name: "missing name", opt: FJSONOptions{Key: "api-1", Version: "1.0.0"}},
+		{name: "missing version", opt: FJSONOptions{Key: "api-1", Name: "codepair-sinarmas"}},
+		{name: "unknown profile", opt: FJSONOptions{Key: "api-1", Name: "codepair-sinarmas", Version: "1.0.0", Profile: "splunk"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, errx := FJSONInterceptor(tc.opt)
+			if tc.valid {
+				assert.Nil(t, errx)
+				assert.True(t, obj.IsEnabled())
+				return
+			}
+
+			assert.NotNil(t, errx)
+			assert.Nil(t, obj)
+		})
+	}
+}
+
+func TestFJSONInterceptor_Golden(t *testing.T) {
+	clock := func() time.Time {
+		return time.Date(2024, time.March, 5, 8, 30, 15, 123000000, time.UTC)
+	}
+
+	testCases := []struct {
+		name string
+		opt  FJSONOptions
+	}{
+		{name: "default", opt: FJSONOptions{Profile: FJSONProfileDefault, Caller: true}},
+		{name: "ecs", opt: FJSONOptions{Profile: FJSONProfileECS, Caller: true}},
+		{name: "gcp", opt: FJSONOptions{Profile: FJSONProfileGCP, Caller: true}},
+		{name: "fields", opt: FJSONOptions{Fields: FJSONFields{Level: "lvl", Timestamp: "ts", Tags: "ctx", Payload: "data"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opt.Key, tc.opt.Name, tc.opt.Version, tc.opt.Clock = "api-1", "codepair-sinarmas", "1.4.2", clock
+
+			obj, errx := FJSONInterceptor(tc.opt)
+			assert.Nil(t, errx)
+
+			tags := map[string]string{LogSquadTagLayerName: "usecase", LogSquadTagRequestID: "req-1"}
+			lines := []string{
+				obj.Translate(LogInterceptorTranslateArguments{Level: ErrorLevelInfo, Tags: tags, Payload: "user registered", Fields: []Field{
+					String("email", "b***@example.com"), Int("attempts", 2), Duration("elapsed", 1500*time.Millisecond),
+				}}),
+				obj.Translate(LogInterceptorTranslateArguments{Level: ErrorLevelWarning, Payload: errors.New("smtp unavailable")}),
+				obj.Translate(LogInterceptorTranslateArguments{Level: ErrorLevelCritical, Tags: tags, Payload: fjsonTestError()}),
+				obj.Translate(LogInterceptorTranslateArguments{Level: ErrorLevelDebug, Payload: map[string]int{"attempts": 3}}),
+			}
+
+			// the caller of the plain payloads is this file, only its name is stable across machines
+			_, file, _, _ := runtime.Caller(0)
+			output := strings.ReplaceAll(strings.Join(lines, "\n")+"\n", filepath.Dir(file)+"/", "")
+
+			golden := filepath.Join("testdata", "fjson", tc.name+".golden")
+			if *updateGolden {
+				assert.Nil(t, os.WriteFile(golden, []byte(output), 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), output)
+		})
+	}
+}
+
+func TestFJSONInterceptor_Disabled(t *testing.T) {
+	obj, errx := FJSONInterceptor(FJSONOptions{Key: "api-1", Name: "codepair-sinarmas", Version: "1.0.0"})
+	assert.Nil(t, errx)
+
+	obj.Disable()
+	msg := obj.Translate(LogInterceptorTranslateArguments{Level: ErrorLevelInfo, Payload: "plain"})
+	assert.False(t, strings.HasPrefix(msg, "{"))
+	assert.Contains(t, msg, "plain")
+}
@@ -3,7 +3,9 @@ package logger
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,7 +18,11 @@ type (
 	LogInterceptorTranslateArguments struct {
 		Level   ErrorLevel
 		Tags    map[string]string
+		Fields  []Field
 		Payload interface{}
+
+		// redactor is the one having redacted the arguments, it masks the translations made outside of the logger
+		redactor *Redactor
 	}
 
 	// LogInterceptor interface
@@ -67,6 +73,9 @@ func DefaultTranslate(args LogInterceptorTranslateArguments, traceSkip int) stri
 	}
 
 	coloredMsg = tagMsg + "} " + coloredMsg
+	if len(args.Fields) > 0 {
+		coloredMsg += " " + fieldsText(args.Fields)
+	}
 
 	// formating
 	var (
@@ -76,6 +85,7 @@ func DefaultTranslate(args LogInterceptorTranslateArguments, traceSkip int) stri
 			Label string
 			Color utstring.Color
 		}{
+			ErrorLevelDebug:    {"DEBUG", utstring.DARK_GRAY},
 			ErrorLevelInfo:     {"INFO", utstring.LIGHT_BLUE},
 			ErrorLevelLog:      {"LOG", utstring.LIGHT_GRAY},
 			ErrorLevelWarning:  {"WARN", utstring.LIGHT_YELLOW},
@@ -120,6 +130,71 @@ func DefaultTransform(args LogInterceptorTranslateArguments, traceSkip int) (pla
 	return
 }
 
+// errorFrame is a stack frame of a logged error, shared by the structured interceptors
+type errorFrame struct {
+	File     string
+	Package  string
+	Function string
+	Line     int
+	InApp    bool
+}
+
+// errorFrames returns the errx stack frames, innermost first
+func errorFrames(errx serror.SError) []errorFrame {
+	var (
+		goroot = runtime.GOROOT()
+		frames = errx.StackFrames()
+		res    = make([]errorFrame, 0, len(frames))
+	)
+
+	for _, v := range frames {
+		res = append(res, errorFrame{
+			File:     v.File,
+			Package:  v.Package,
+			Function: v.Name,
+			Line:     v.LineNumber,
+			InApp:    !strings.Contains(v.File, "/pkg/mod/") && (goroot == "" || !strings.HasPrefix(v.File, goroot)),
+		})
+	}
+
+	return res
+}
+
+// errorTypeName returns the type of err, looking through the redaction wrapper
+func errorTypeName(err error) string {
+	if cur, ok := err.(*redactedError); ok {
+		err = cur.err
+	}
+
+	return reflect.TypeOf(err).String()
+}
+
+// payloadMessage returns the plain message of a log payload
+func payloadMessage(payload interface{}) string {
+	switch vx := payload.(type) {
+	case serror.SError:
+		return vx.Error()
+
+	case error:
+		return vx.Error()
+
+	case string:
+		return vx
+	}
+
+	return fmt.Sprintf("%v", payload)
+}
+
+func sortedTagNames(tags map[string]string) []string {
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
 // DefaultProcess for default processing
 func DefaultProcess(lvl ErrorLevel, msg string) {
 	if msg == "" {
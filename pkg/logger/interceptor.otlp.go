@@ -0,0 +1,315 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utstring"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lunixbochs/vtclean"
+)
+
+// DefaultExportTimeout is used by the exporting interceptors when no timeout is configured
+const DefaultExportTimeout = 5 * time.Second
+
+type (
+	// OTLPOptions type
+	OTLPOptions struct {
+		// Endpoint is the collector logs URL, such as http://localhost:4318/v1/logs
+		Endpoint       string            `json:"endpoint" validate:"required,url"`
+		ServiceName    string            `json:"service_name" validate:"required"`
+		ServiceVersion string            `json:"service_version"`
+		Headers        map[string]string `json:"headers"`
+		Level          ErrorLevel        `json:"level" validate:"required,oneof=debug log info warn critical"`
+		Timeout        time.Duration     `json:"timeout"`
+		Printing       bool              `json:"printing"`
+		Client         *http.Client      `json:"-"`
+	}
+
+	// OTLP interceptor exporting entries as OpenTelemetry log records over HTTP
+	OTLP interface {
+		LogDispatcher
+		IsEnabled() bool
+		Enable()
+		Disable()
+	}
+
+	otlpInterceptorObj struct {
+		Endpoint  string
+		Headers   map[string]string
+		Level     ErrorLevel
+		Printing  bool
+		Enabled   bool
+		client    *http.Client
+		resource  otlpResource
+		scopeName string
+	}
+
+	otlpAnyValue struct {
+		StringValue *string         `json:"stringValue,omitempty"`
+		IntValue    *string         `json:"intValue,omitempty"`
+		ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	}
+
+	otlpArrayValue struct {
+		Values []otlpAnyValue `json:"values"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpLogRecord struct {
+		TimeUnixNano         string         `json:"timeUnixNano"`
+		ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+		SeverityNumber       int            `json:"severityNumber"`
+		SeverityText         string         `json:"severityText"`
+		Body                 otlpAnyValue   `json:"body"`
+		Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+
+	otlpRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+)
+
+// otlpSeverities maps the levels to the OpenTelemetry severity numbers
+var otlpSeverities = map[ErrorLevel]struct {
+	Number int
+	Text   string
+}{
+	ErrorLevelDebug:    {5, "DEBUG"},
+	ErrorLevelLog:      {9, "INFO"},
+	ErrorLevelInfo:     {10, "INFO2"},
+	ErrorLevelWarning:  {13, "WARN"},
+	ErrorLevelCritical: {17, "ERROR"},
+}
+
+// OTLPInterceptor to create OpenTelemetry logs interceptor
+func OTLPInterceptor(opt OTLPOptions) (obj OTLP, errx serror.SError) {
+	validate := validator.New()
+	if err := validate.Struct(opt); err != nil {
+		errx = serror.NewFromErrorc(err, "Invalid OTLP options")
+		return obj, errx
+	}
+
+	client := opt.Client
+	if client == nil {
+		client = &http.Client{Timeout: durationOr(opt.Timeout, DefaultExportTimeout)}
+	}
+
+	attributes := []otlpKeyValue{
+		otlpString("service.name", opt.ServiceName),
+		otlpString("deployment.environment", utstring.Env("APP_ENV", "local")),
+	}
+	if opt.ServiceVersion != "" {
+		attributes = append(attributes, otlpString("service.version", opt.ServiceVersion))
+	}
+
+	obj = &otlpInterceptorObj{
+		Endpoint:  opt.Endpoint,
+		Headers:   opt.Headers,
+		Level:     opt.Level,
+		Printing:  opt.Printing,
+		Enabled:   true,
+		client:    client,
+		resource:  otlpResource{Attributes: attributes},
+		scopeName: reflect.TypeOf(otlpInterceptorObj{}).PkgPath(),
+	}
+	return obj, errx
+}
+
+func (otlpInterceptorObj) Translate(args LogInterceptorTranslateArguments) string {
+	return DefaultTranslate(args, 2)
+}
+
+// Process exports msg as the record body, Dispatch is preferred since it keeps the error fields
+func (ox otlpInterceptorObj) Process(lvl ErrorLevel, msg string) {
+	ox.Dispatch(LogInterceptorTranslateArguments{Level: lvl, Payload: vtclean.Clean(msg, false)}, msg)
+}
+
+func (ox otlpInterceptorObj) Dispatch(args LogInterceptorTranslateArguments, msg string) {
+	if ox.Printing {
+		DefaultProcess(args.Level, msg)
+	}
+
+	if !ox.Enabled || !ox.Level.Allows(args.Level) {
+		return
+	}
+
+	req := otlpRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: ox.resource,
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: ox.scopeName},
+				LogRecords: []otlpLogRecord{otlpRecord(args, time.Now())},
+			}},
+		}},
+	}
+
+	byt, err := json.Marshal(req)
+	if err != nil {
+		DefaultStderr(fmt.Sprintf("Failed to encode OTLP logs, details: %v", err))
+		return
+	}
+
+	postExport(ox.client, ox.Endpoint, "application/json", ox.Headers, byt)
+}
+
+func (ox otlpInterceptorObj) IsEnabled() bool {
+	return ox.Enabled
+}
+
+func (ox *otlpInterceptorObj) Enable() {
+	ox.Enabled = true
+}
+
+func (ox *otlpInterceptorObj) Disable() {
+	ox.Enabled = false
+}
+
+// private
+
+func otlpRecord(args LogInterceptorTranslateArguments, now time.Time) otlpLogRecord {
+	var (
+		severity = otlpSeverities[args.Level]
+		ts       = strconv.FormatInt(now.UnixNano(), 10)
+		record   = otlpLogRecord{
+			TimeUnixNano:         ts,
+			ObservedTimeUnixNano: ts,
+			SeverityNumber:       severity.Number,
+			SeverityText:         severity.Text,
+			Body:                 otlpStringValue(payloadMessage(args.Payload)),
+		}
+	)
+
+	for _, k := range sortedTagNames(args.Tags) {
+		record.Attributes = append(record.Attributes, otlpString("tag."+k, args.Tags[k]))
+	}
+
+	for _, v := range args.Fields {
+		if v.Type == FieldTypeInt {
+			record.Attributes = append(record.Attributes, otlpInt("field."+v.Key, v.Int))
+			continue
+		}
+
+		record.Attributes = append(record.Attributes, otlpString("field."+v.Key, payloadMessage(v.Value())))
+	}
+
+	switch vx := args.Payload.(type) {
+	case serror.SError:
+		record.Attributes = append(record.Attributes,
+			otlpString("exception.type", vx.Type()),
+			otlpString("exception.message", vx.Error()),
+			otlpString("exception.stacktrace", otlpStacktrace(errorFrames(vx))),
+			otlpString("code.filepath", vx.File()),
+			otlpInt("code.lineno", int64(vx.Line())),
+			otlpString("code.function", vx.FN()),
+			otlpString("error.key", vx.Key()),
+			otlpInt("error.code", int64(vx.Code())),
+		)
+
+		if comments := vx.CommentStack(); len(comments) > 0 {
+			values := make([]otlpAnyValue, len(comments))
+			for i, v := range comments {
+				values[i] = otlpStringValue(v)
+			}
+
+			record.Attributes = append(record.Attributes, otlpKeyValue{
+				Key:   "error.comments",
+				Value: otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}},
+			})
+		}
+
+	case error:
+		record.Attributes = append(record.Attributes,
+			otlpString("exception.type", errorTypeName(vx)),
+			otlpString("exception.message", vx.Error()),
+		)
+	}
+
+	return record
+}
+
+func otlpStacktrace(frames []errorFrame) string {
+	var sb strings.Builder
+	for _, v := range frames {
+		fmt.Fprintf(&sb, "%s.%s\n\t%s:%d\n", v.Package, v.Function, v.File, v.Line)
+	}
+
+	return sb.String()
+}
+
+func otlpStringValue(value string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &value}
+}
+
+func otlpString(key string, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpStringValue(value)}
+}
+
+// otlpInt encodes int64 as a string, as the OTLP JSON mapping requires
+func otlpInt(key string, value int64) otlpKeyValue {
+	str := strconv.FormatInt(value, 10)
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{IntValue: &str}}
+}
+
+// postExport sends body to url, failures are printed since logging them would loop back here
+func postExport(client *http.Client, url string, contentType string, headers map[string]string, body []byte) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		DefaultStderr(fmt.Sprintf("Failed to create export request to %s, details: %v", url, err))
+		return
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		DefaultStderr(fmt.Sprintf("Failed to export logs to %s, details: %v", url, err))
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		DefaultStderr(fmt.Sprintf("Failed to export logs to %s, status: %s", url, res.Status))
+	}
+}
+
+func durationOr(val time.Duration, def time.Duration) time.Duration {
+	if val <= 0 {
+		return def
+	}
+
+	return val
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collector records every request body sent to it
+func collector(t *testing.T) (*httptest.Server, <-chan *http.Request, <-chan []byte) {
+	var (
+		requests = make(chan *http.Request, 16)
+		bodies   = make(chan []byte, 16)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		byt, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+
+		requests <- r
+		bodies <- byt
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, requests, bodies
+}
+
+func otlpAttributes(record otlpLogRecord) map[string]otlpAnyValue {
+	res := map[string]otlpAnyValue{}
+	for _, v := range record.Attributes {
+		res[v.Key] = v.Value
+	}
+
+	return res
+}
+
+func TestOTLPInterceptor_Options(t *testing.T) {
+	_, errx := OTLPInterceptor(OTLPOptions{ServiceName: "api", Level: ErrorLevelInfo})
+	assert.NotNil(t, errx)
+
+	_, errx = OTLPInterceptor(OTLPOptions{Endpoint: "http://localhost:4318/v1/logs", ServiceName: "api", Level: "verbose"})
+	assert.NotNil(t, errx)
+}
+
+func TestOTLPInterceptor_Dispatch(t *testing.T) {
+	srv, requests, bodies := collector(t)
+
+	otlp, errx := OTLPInterceptor(OTLPOptions{
+		Endpoint:       srv.URL + "/v1/logs",
+		ServiceName:    "codepair",
+		ServiceVersion: "1.2.3",
+		Headers:        map[string]string{"Authorization": "Bearer collector"},
+		Level:          ErrorLevelWarning,
+	})
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Interceptor: otlp})
+
+	log.Info("below the interceptor level")
+
+	errx = serror.Newik(http.StatusServiceUnavailable, "db_unavailable", "database is down")
+	errx.AddComments("[repository][GetUser] while querying user")
+	squad := log.CreateSquad(context.Background(), "repository")
+	squad.SetTag("requestID", "req-1")
+	squad.Err(errx)
+
+	req := <-requests
+	assert.Equal(t, "/v1/logs", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer collector", req.Header.Get("Authorization"))
+
+	var payload otlpRequest
+	assert.Nil(t, json.Unmarshal(<-bodies, &payload))
+	assert.Len(t, payload.ResourceLogs, 1)
+
+	resource := otlpAttributes(otlpLogRecord{Attributes: payload.ResourceLogs[0].Resource.Attributes})
+	assert.Equal(t, "codepair", *resource["service.name"].StringValue)
+	assert.Equal(t, "1.2.3", *resource["service.version"].StringValue)
+
+	records := payload.ResourceLogs[0].ScopeLogs[0].LogRecords
+	assert.Len(t, records, 1)
+	assert.Equal(t, 17, records[0].SeverityNumber)
+	assert.Equal(t, "ERROR", records[0].SeverityText)
+	assert.Equal(t, "database is down", *records[0].Body.StringValue)
+
+	attributes := otlpAttributes(records[0])
+	assert.Equal(t, "db_unavailable", *attributes["error.key"].StringValue)
+	assert.Equal(t, "503", *attributes["error.code"].IntValue)
+	assert.Equal(t, "req-1", *attributes["tag.requestID"].StringValue)
+	assert.Contains(t, *attributes["exception.stacktrace"].StringValue, "TestOTLPInterceptor_Dispatch")
+	assert.NotEmpty(t, *attributes["code.function"].StringValue)
+	assert.Len(t, attributes["error.comments"].ArrayValue.Values, 2)
+	assert.Contains(t, *attributes["error.comments"].ArrayValue.Values[1].StringValue, "[repository][GetUser] while querying user")
+
+	otlp.Disable()
+	log.Err("disabled")
+	assert.False(t, otlp.IsEnabled())
+	assert.Len(t, requests, 0)
+}
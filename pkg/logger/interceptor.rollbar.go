@@ -1,39 +1,146 @@
 package logger
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
 	"codepair-sinarmas/pkg/serror"
-	"codepair-sinarmas/pkg/utils/utarray"
 	"codepair-sinarmas/pkg/utils/utstring"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/lunixbochs/vtclean"
-	rollbar "github.com/rollbar/rollbar-go"
+)
+
+const (
+	// DefaultRollbarEndpoint is the Rollbar items API
+	DefaultRollbarEndpoint = "https://api.rollbar.com/api/1/item/"
+
+	rollbarNotifierName    = "codepair-sinarmas-logger"
+	rollbarNotifierVersion = "1.0"
 )
 
 type (
 	// RollbarOptions type
 	RollbarOptions struct {
-		Key     string     `json:"key" valid:"required"`
-		Name    string     `json:"name" valid:"required"`
-		Token   string     `json:"token" valid:"required"`
-		Version string     `json:"version" valid:"required"`
-		Level   ErrorLevel `json:"level" valid:"required"`
+		// Key is the server host reported with the items
+		Key         string        `json:"key" validate:"required"`
+		Name        string        `json:"name" validate:"required"`
+		Token       string        `json:"token" validate:"required"`
+		Version     string        `json:"version" validate:"required"`
+		Level       ErrorLevel    `json:"level" validate:"required,oneof=debug log info warn critical"`
+		Environment string        `json:"environment"`
+		Endpoint    string        `json:"endpoint" validate:"omitempty,url"`
+		Timeout     time.Duration `json:"timeout"`
+		Client      *http.Client  `json:"-"`
+		// Printing also prints the entries to stdout, it is off by default whereas the previous
+		// interceptor always printed, enable it when the rollbar interceptor is used alone
+		Printing bool `json:"printing"`
 	}
 
-	// Rollbar interceptor
+	// Rollbar interceptor sending entries as Rollbar items
 	Rollbar interface {
-		LogInterceptor
+		LogDispatcher
 		IsEnabled() bool
 		Enable()
 		Disable()
 	}
 
 	rollbarInterceptorObj struct {
-		Level   ErrorLevel
-		Enabled bool
+		Key         string
+		Name        string
+		Version     string
+		Environment string
+		Level       ErrorLevel
+		Printing    bool
+		Enabled     bool
+		token       string
+		endpoint    string
+		client      *http.Client
+	}
+
+	rollbarItem struct {
+		AccessToken string      `json:"access_token"`
+		Data        rollbarData `json:"data"`
+	}
+
+	rollbarData struct {
+		UUID        string                 `json:"uuid"`
+		Environment string                 `json:"environment"`
+		Level       string                 `json:"level"`
+		Timestamp   int64                  `json:"timestamp"`
+		CodeVersion string                 `json:"code_version,omitempty"`
+		Platform    string                 `json:"platform"`
+		Language    string                 `json:"language"`
+		Title       string                 `json:"title,omitempty"`
+		Context     string                 `json:"context,omitempty"`
+		Fingerprint string                 `json:"fingerprint,omitempty"`
+		Body        rollbarBody            `json:"body"`
+		Person      *rollbarPerson         `json:"person,omitempty"`
+		Request     *rollbarRequest        `json:"request,omitempty"`
+		Server      rollbarServer          `json:"server"`
+		Custom      map[string]interface{} `json:"custom,omitempty"`
+		Notifier    rollbarNotifier        `json:"notifier"`
+	}
+
+	rollbarBody struct {
+		Trace   *rollbarTrace   `json:"trace,omitempty"`
+		Message *rollbarMessage `json:"message,omitempty"`
+	}
+
+	rollbarTrace struct {
+		Frames    []rollbarFrame   `json:"frames"`
+		Exception rollbarException `json:"exception"`
+	}
+
+	rollbarFrame struct {
+		Filename string `json:"filename"`
+		Lineno   int    `json:"lineno"`
+		Method   string `json:"method"`
+	}
+
+	rollbarException struct {
+		Class   string `json:"class"`
+		Message string `json:"message"`
+	}
+
+	rollbarMessage struct {
+		Body string `json:"body"`
+	}
+
+	rollbarPerson struct {
+		ID       string `json:"id"`
+		Username string `json:"username,omitempty"`
+	}
+
+	rollbarRequest struct {
+		URL     string            `json:"url,omitempty"`
+		Method  string            `json:"method,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+	}
+
+	rollbarServer struct {
+		Host string `json:"host"`
+	}
+
+	rollbarNotifier struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
 	}
 )
 
+// rollbarLevels maps the levels to the Rollbar item levels
+var rollbarLevels = map[ErrorLevel]string{
+	ErrorLevelDebug:    "debug",
+	ErrorLevelLog:      "debug",
+	ErrorLevelInfo:     "info",
+	ErrorLevelWarning:  "warning",
+	ErrorLevelCritical: "critical",
+}
+
 // RollbarInterceptor to create rollbar interceptor
 func RollbarInterceptor(opt RollbarOptions) (obj Rollbar, errx serror.SError) {
 	validate := validator.New()
@@ -42,14 +149,22 @@ func RollbarInterceptor(opt RollbarOptions) (obj Rollbar, errx serror.SError) {
 		return obj, errx
 	}
 
-	rollbar.SetToken(opt.Token)
-	rollbar.SetEnvironment(utstring.Env("APP_ENV", "local"))
-	rollbar.SetCodeVersion(opt.Version)
-	rollbar.SetServerHost(opt.Key)
+	client := opt.Client
+	if client == nil {
+		client = &http.Client{Timeout: durationOr(opt.Timeout, DefaultExportTimeout)}
+	}
 
 	obj = &rollbarInterceptorObj{
-		Level:   opt.Level,
-		Enabled: true,
+		Key:         opt.Key,
+		Name:        opt.Name,
+		Version:     opt.Version,
+		Environment: utstring.Chains(opt.Environment, utstring.Env("APP_ENV", "local")),
+		Level:       opt.Level,
+		Printing:    opt.Printing,
+		Enabled:     true,
+		token:       opt.Token,
+		endpoint:    utstring.Chains(opt.Endpoint, DefaultRollbarEndpoint),
+		client:      client,
 	}
 	return obj, errx
 }
@@ -58,45 +173,32 @@ func (rollbarInterceptorObj) Translate(args LogInterceptorTranslateArguments) st
 	return DefaultTranslate(args, 2)
 }
 
+// Process sends msg as the item message, Dispatch is preferred since it keeps the error frames and the tags
 func (ox rollbarInterceptorObj) Process(lvl ErrorLevel, msg string) {
-	DefaultProcess(lvl, msg)
+	ox.Dispatch(LogInterceptorTranslateArguments{Level: lvl, Payload: vtclean.Clean(msg, false)}, msg)
+}
 
-	rlvl := map[ErrorLevel]string{
-		ErrorLevelLog:      rollbar.DEBUG,
-		ErrorLevelInfo:     rollbar.INFO,
-		ErrorLevelWarning:  rollbar.WARN,
-		ErrorLevelCritical: rollbar.CRIT,
+func (ox rollbarInterceptorObj) Dispatch(args LogInterceptorTranslateArguments, msg string) {
+	if ox.Printing {
+		DefaultProcess(args.Level, msg)
 	}
 
-	if ox.Enabled {
-		allow := []ErrorLevel{
-			ErrorLevelDebug,
-		}
-
-		switch ox.Level {
-		case ErrorLevelLog:
-			allow = append(allow, ErrorLevelLog)
-			fallthrough
-
-		case ErrorLevelInfo:
-			allow = append(allow, ErrorLevelInfo)
-			fallthrough
-
-		case ErrorLevelWarning:
-			allow = append(allow, ErrorLevelWarning)
-			fallthrough
-
-		case ErrorLevelCritical:
-			allow = append(allow, ErrorLevelCritical)
-
-		default:
-			return
-		}
+	if !ox.Enabled || !ox.Level.Allows(args.Level) {
+		return
+	}
 
-		if utarray.IsExist(lvl, allow) {
-			rollbar.Log(rlvl[lvl], vtclean.Clean(msg, false))
-		}
+	byt, err := json.Marshal(rollbarItem{
+		AccessToken: ox.token,
+		Data:        ox.data(args, time.Now()),
+	})
+	if err != nil {
+		DefaultStderr(fmt.Sprintf("Failed to encode rollbar item, details: %v", err))
+		return
 	}
+
+	postExport(ox.client, ox.endpoint, "application/json", map[string]string{
+		"X-Rollbar-Access-Token": ox.token,
+	}, byt)
 }
 
 func (ox rollbarInterceptorObj) IsEnabled() bool {
@@ -110,3 +212,110 @@ func (ox *rollbarInterceptorObj) Enable() {
 func (ox *rollbarInterceptorObj) Disable() {
 	ox.Enabled = false
 }
+
+// private
+
+func (ox rollbarInterceptorObj) data(args LogInterceptorTranslateArguments, now time.Time) rollbarData {
+	data := rollbarData{
+		UUID:        rollbarUUID(),
+		Environment: ox.Environment,
+		Level:       rollbarLevels[args.Level],
+		Timestamp:   now.Unix(),
+		CodeVersion: ox.Version,
+		Platform:    "go",
+		Language:    "go",
+		Title:       payloadMessage(args.Payload),
+		Context:     args.Tags[LogSquadTagRoute],
+		Server:      rollbarServer{Host: ox.Key},
+		Custom:      map[string]interface{}{"service": ox.Name},
+		Notifier:    rollbarNotifier{Name: rollbarNotifierName, Version: rollbarNotifierVersion},
+	}
+
+	// the authenticated principal and the request are known through the squad tags and the error signature
+	if userID := args.Tags[LogSquadTagUserID]; userID != "" {
+		data.Person = &rollbarPerson{ID: userID, Username: args.Tags[LogSquadTagUsername]}
+	}
+
+	if args.Tags[LogSquadTagRoute] != "" || args.Tags[LogSquadTagMethod] != "" || args.Tags[LogSquadTagRequestID] != "" {
+		data.Request = &rollbarRequest{
+			URL:    args.Tags[LogSquadTagRoute],
+			Method: args.Tags[LogSquadTagMethod],
+		}
+
+		if requestID := args.Tags[LogSquadTagRequestID]; requestID != "" {
+			data.Request.Headers = map[string]string{"X-Request-ID": requestID}
+		}
+	}
+
+	for _, k := range sortedTagNames(args.Tags) {
+		switch k {
+		case LogSquadTagUserID, LogSquadTagUsername, LogSquadTagRoute, LogSquadTagMethod, LogSquadTagRequestID:
+			continue
+		}
+
+		data.Custom[k] = args.Tags[k]
+	}
+
+	for _, v := range args.Fields {
+		data.Custom[v.Key] = v.Value()
+	}
+
+	switch vx := args.Payload.(type) {
+	case serror.SError:
+		trace := &rollbarTrace{
+			Exception: rollbarException{Class: vx.Type(), Message: vx.Error()},
+		}
+
+		// rollbar expects the outermost frame first
+		frames := errorFrames(vx)
+		for i := len(frames) - 1; i >= 0; i-- {
+			method := frames[i].Function
+			if frames[i].Package != "" {
+				method = frames[i].Package + "." + method
+			}
+
+			trace.Frames = append(trace.Frames, rollbarFrame{
+				Filename: frames[i].File,
+				Lineno:   frames[i].Line,
+				Method:   method,
+			})
+		}
+
+		if len(trace.Frames) > 0 {
+			data.Body.Trace = trace
+		} else {
+			data.Body.Message = &rollbarMessage{Body: vx.Error()}
+		}
+
+		data.Custom["code"] = vx.Code()
+		if comments := vx.CommentStack(); len(comments) > 0 {
+			data.Custom["comments"] = comments
+		}
+
+		if key := vx.Key(); key != "" && key != "-" {
+			data.Custom["key"] = key
+			data.Fingerprint = key
+		}
+
+	case error:
+		data.Body.Message = &rollbarMessage{Body: vx.Error()}
+		data.Custom["error_type"] = errorTypeName(vx)
+
+	default:
+		data.Body.Message = &rollbarMessage{Body: payloadMessage(vx)}
+	}
+
+	return data
+}
+
+func rollbarUUID() string {
+	byt := make([]byte, 16)
+	_, _ = rand.Read(byt)
+
+	// version 4, variant 10
+	byt[6] = (byt[6] & 0x0f) | 0x40
+	byt[8] = (byt[8] & 0x3f) | 0x80
+
+	str := hex.EncodeToString(byt)
+	return str[0:8] + "-" + str[8:12] + "-" + str[12:16] + "-" + str[16:20] + "-" + str[20:]
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbarInterceptor_Options(t *testing.T) {
+	testTable := []struct {
+		name string
+		opt  RollbarOptions
+	}{
+		{name: "empty", opt: RollbarOptions{}},
+		{name: "no token", opt: RollbarOptions{Key: "api-1", Name: "codepair", Version: "1.0.0", Level: ErrorLevelWarning}},
+		{name: "unknown level", opt: RollbarOptions{Key: "api-1", Name: "codepair", Token: "secret", Version: "1.0.0", Level: "fatal"}},
+		{name: "invalid endpoint", opt: RollbarOptions{Key: "api-1", Name: "codepair", Token: "secret", Version: "1.0.0", Level: ErrorLevelWarning, Endpoint: "rollbar"}},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errx := RollbarInterceptor(tc.opt)
+			assert.NotNil(t, errx)
+		})
+	}
+}
+
+func TestRollbarInterceptor_Dispatch(t *testing.T) {
+	srv, requests, bodies := collector(t)
+
+	rollbar, errx := RollbarInterceptor(RollbarOptions{
+		Key:         "api-1",
+		Name:        "codepair",
+		Token:       "secret",
+		Version:     "1.2.3",
+		Level:       ErrorLevelWarning,
+		Environment: "staging",
+		Endpoint:    srv.URL + "/api/1/item/",
+	})
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Interceptor: rollbar})
+
+	log.Info("below the interceptor level")
+
+	errx = serror.Newik(http.StatusConflict, "user.email_taken", "email already registered")
+	errx.AddComments("[usecase][Register] while creating user")
+	errx.SetSignature(serror.Signature{RequestID: "req-1", UserID: "7", Route: "/v1/users", Method: http.MethodPost, Layer: "usecase"})
+
+	squad := log.CreateSquad(nil, "handler")
+	squad.SetTag(LogSquadTagUsername, "budi")
+	squad.With(Int("attempts", 2)).Warn(errx)
+
+	req := <-requests
+	assert.Equal(t, "/api/1/item/", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "secret", req.Header.Get("X-Rollbar-Access-Token"))
+
+	var item rollbarItem
+	assert.Nil(t, json.Unmarshal(<-bodies, &item))
+	assert.Equal(t, "secret", item.AccessToken)
+
+	data := item.Data
+	assert.Len(t, data.UUID, 36)
+	assert.Equal(t, "warning", data.Level)
+	assert.Equal(t, "staging", data.Environment)
+	assert.Equal(t, "1.2.3", data.CodeVersion)
+	assert.Equal(t, "api-1", data.Server.Host)
+	assert.Equal(t, "user.email_taken", data.Fingerprint)
+	assert.Equal(t, "/v1/users", data.Context)
+	assert.Equal(t, &rollbarPerson{ID: "7", Username: "budi"}, data.Person)
+	assert.Equal(t, &rollbarRequest{URL: "/v1/users", Method: http.MethodPost, Headers: map[string]string{"X-Request-ID": "req-1"}}, data.Request)
+	assert.Equal(t, "user.email_taken", data.Custom["key"])
+	assert.Equal(t, float64(http.StatusConflict), data.Custom["code"])
+	assert.Equal(t, float64(2), data.Custom["attempts"])
+	assert.Equal(t, "usecase", data.Custom[LogSquadTagErrorLayer])
+	assert.Len(t, data.Custom["comments"], 2)
+
+	assert.Nil(t, data.Body.Message)
+	assert.Equal(t, "email already registered", data.Body.Trace.Exception.Message)
+	frames := data.Body.Trace.Frames
+	assert.NotEmpty(t, frames)
+	assert.Contains(t, frames[len(frames)-1].Method, "TestRollbarInterceptor_Dispatch")
+
+	log.Err("database is down")
+
+	item = rollbarItem{}
+	<-requests
+	assert.Nil(t, json.Unmarshal(<-bodies, &item))
+	assert.Equal(t, "critical", item.Data.Level)
+	assert.Equal(t, &rollbarMessage{Body: "database is down"}, item.Data.Body.Message)
+	assert.Nil(t, item.Data.Body.Trace)
+	assert.Nil(t, item.Data.Person)
+	assert.Empty(t, item.Data.Fingerprint)
+
+	rollbar.Disable()
+	log.Err("disabled")
+	assert.False(t, rollbar.IsEnabled())
+	assert.Len(t, requests, 0)
+}
+
+func TestRollbarInterceptor_RemoteFrames(t *testing.T) {
+	srv, _, bodies := collector(t)
+
+	rollbar, errx := RollbarInterceptor(RollbarOptions{
+		Key:      "api-1",
+		Name:     "codepair",
+		Token:    "secret",
+		Version:  "1.2.3",
+		Level:    ErrorLevelCritical,
+		Endpoint: srv.URL,
+	})
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Interceptor: AsyncInterceptor(rollbar, AsyncOptions{QueueSize: 4})})
+	log.Err(serror.NewFromPayload(serror.Payload{
+		Code:    http.StatusBadGateway,
+		Message: "upstream failed",
+		Frames: []serror.Frame{
+			{Function: "(*Client).Do", Package: "codepair-sinarmas/pkg/upstream", File: "/pkg/upstream/client.go", Line: 42},
+			{Function: "main", Package: "main", File: "/main.go", Line: 10},
+		},
+	}))
+	assert.Nil(t, log.Close())
+
+	var item rollbarItem
+	assert.Nil(t, json.Unmarshal(<-bodies, &item))
+	assert.Equal(t, []rollbarFrame{
+		{Filename: "/main.go", Lineno: 10, Method: "main.main"},
+		{Filename: "/pkg/upstream/client.go", Lineno: 42, Method: "codepair-sinarmas/pkg/upstream.(*Client).Do"},
+	}, item.Data.Body.Trace.Frames)
+}
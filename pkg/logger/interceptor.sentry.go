@@ -0,0 +1,290 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"time"
+
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utstring"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lunixbochs/vtclean"
+)
+
+const sentryClientName = "codepair-sinarmas-logger/1.0"
+
+type (
+	// SentryOptions type
+	SentryOptions struct {
+		// DSN is the Sentry project DSN, such as https://<key>@sentry.example.com/<project>
+		DSN         string        `json:"dsn" validate:"required,url"`
+		Release     string        `json:"release"`
+		Environment string        `json:"environment"`
+		ServerName  string        `json:"server_name"`
+		Level       ErrorLevel    `json:"level" validate:"required,oneof=debug log info warn critical"`
+		Timeout     time.Duration `json:"timeout"`
+		Printing    bool          `json:"printing"`
+		Client      *http.Client  `json:"-"`
+	}
+
+	// Sentry interceptor sending entries as Sentry envelopes
+	Sentry interface {
+		LogDispatcher
+		IsEnabled() bool
+		Enable()
+		Disable()
+	}
+
+	sentryInterceptorObj struct {
+		DSN         string
+		Release     string
+		Environment string
+		ServerName  string
+		Level       ErrorLevel
+		Printing    bool
+		Enabled     bool
+		client      *http.Client
+		endpoint    string
+		auth        string
+	}
+
+	sentryFrame struct {
+		Filename string `json:"filename"`
+		Function string `json:"function"`
+		Module   string `json:"module"`
+		Lineno   int    `json:"lineno"`
+		InApp    bool   `json:"in_app"`
+	}
+
+	sentryStacktrace struct {
+		Frames []sentryFrame `json:"frames"`
+	}
+
+	sentryException struct {
+		Type       string            `json:"type"`
+		Value      string            `json:"value"`
+		Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+	}
+
+	sentryExceptions struct {
+		Values []sentryException `json:"values"`
+	}
+
+	sentryEvent struct {
+		EventID     string                 `json:"event_id"`
+		Timestamp   string                 `json:"timestamp"`
+		Level       string                 `json:"level"`
+		Platform    string                 `json:"platform"`
+		Logger      string                 `json:"logger"`
+		ServerName  string                 `json:"server_name,omitempty"`
+		Release     string                 `json:"release,omitempty"`
+		Environment string                 `json:"environment,omitempty"`
+		Message     map[string]string      `json:"message"`
+		Tags        map[string]string      `json:"tags,omitempty"`
+		Fingerprint []string               `json:"fingerprint,omitempty"`
+		Extra       map[string]interface{} `json:"extra,omitempty"`
+		Exception   *sentryExceptions      `json:"exception,omitempty"`
+	}
+)
+
+// sentryLevels maps the levels to the Sentry event levels
+var sentryLevels = map[ErrorLevel]string{
+	ErrorLevelDebug:    "debug",
+	ErrorLevelLog:      "info",
+	ErrorLevelInfo:     "info",
+	ErrorLevelWarning:  "warning",
+	ErrorLevelCritical: "error",
+}
+
+// SentryInterceptor to create Sentry interceptor
+func SentryInterceptor(opt SentryOptions) (obj Sentry, errx serror.SError) {
+	validate := validator.New()
+	if err := validate.Struct(opt); err != nil {
+		errx = serror.NewFromErrorc(err, "Invalid sentry options")
+		return obj, errx
+	}
+
+	dsn, err := url.Parse(opt.DSN)
+	if err != nil {
+		errx = serror.NewFromErrorc(err, "Invalid sentry DSN")
+		return obj, errx
+	}
+
+	var (
+		key       = dsn.User.Username()
+		projectID = path.Base(dsn.Path)
+	)
+	if key == "" || projectID == "" || projectID == "/" || projectID == "." {
+		errx = serror.New("Sentry DSN must contain a public key and a project ID")
+		return obj, errx
+	}
+
+	client := opt.Client
+	if client == nil {
+		client = &http.Client{Timeout: durationOr(opt.Timeout, DefaultExportTimeout)}
+	}
+
+	serverName := opt.ServerName
+	if serverName == "" {
+		serverName, _ = os.Hostname()
+	}
+
+	endpoint := url.URL{
+		Scheme: dsn.Scheme,
+		Host:   dsn.Host,
+		Path:   path.Join(path.Dir(dsn.Path), "api", projectID, "envelope") + "/",
+	}
+
+	obj = &sentryInterceptorObj{
+		DSN:         opt.DSN,
+		Release:     opt.Release,
+		Environment: utstring.Chains(opt.Environment, utstring.Env("APP_ENV", "local")),
+		ServerName:  serverName,
+		Level:       opt.Level,
+		Printing:    opt.Printing,
+		Enabled:     true,
+		client:      client,
+		endpoint:    endpoint.String(),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", key, sentryClientName),
+	}
+	return obj, errx
+}
+
+func (sentryInterceptorObj) Translate(args LogInterceptorTranslateArguments) string {
+	return DefaultTranslate(args, 2)
+}
+
+// Process sends msg as the event message, Dispatch is preferred since it keeps the error fields
+func (ox sentryInterceptorObj) Process(lvl ErrorLevel, msg string) {
+	ox.Dispatch(LogInterceptorTranslateArguments{Level: lvl, Payload: vtclean.Clean(msg, false)}, msg)
+}
+
+func (ox sentryInterceptorObj) Dispatch(args LogInterceptorTranslateArguments, msg string) {
+	if ox.Printing {
+		DefaultProcess(args.Level, msg)
+	}
+
+	if !ox.Enabled || !ox.Level.Allows(args.Level) {
+		return
+	}
+
+	event := ox.event(args, time.Now())
+
+	byt, err := json.Marshal(event)
+	if err != nil {
+		DefaultStderr(fmt.Sprintf("Failed to encode sentry event, details: %v", err))
+		return
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, `{"event_id":%q,"sent_at":%q,"dsn":%q}`+"\n", event.EventID, event.Timestamp, ox.DSN)
+	fmt.Fprintf(&body, `{"type":"event","length":%d}`+"\n", len(byt))
+	body.Write(byt)
+	body.WriteString("\n")
+
+	postExport(ox.client, ox.endpoint, "application/x-sentry-envelope", map[string]string{
+		"X-Sentry-Auth": ox.auth,
+	}, body.Bytes())
+}
+
+func (ox sentryInterceptorObj) IsEnabled() bool {
+	return ox.Enabled
+}
+
+func (ox *sentryInterceptorObj) Enable() {
+	ox.Enabled = true
+}
+
+func (ox *sentryInterceptorObj) Disable() {
+	ox.Enabled = false
+}
+
+// private
+
+func (ox sentryInterceptorObj) event(args LogInterceptorTranslateArguments, now time.Time) sentryEvent {
+	event := sentryEvent{
+		EventID:     sentryEventID(),
+		Timestamp:   now.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevels[args.Level],
+		Platform:    "go",
+		Logger:      reflect.TypeOf(ox).PkgPath(),
+		ServerName:  ox.ServerName,
+		Release:     ox.Release,
+		Environment: ox.Environment,
+		Message:     map[string]string{"formatted": payloadMessage(args.Payload)},
+		Tags:        map[string]string{},
+	}
+
+	for k, v := range args.Tags {
+		event.Tags[k] = v
+	}
+
+	var exception *sentryException
+	switch vx := args.Payload.(type) {
+	case serror.SError:
+		exception = &sentryException{Type: vx.Type(), Value: vx.Error()}
+
+		// sentry expects the outermost frame first
+		frames := errorFrames(vx)
+		if len(frames) > 0 {
+			exception.Stacktrace = &sentryStacktrace{}
+
+			for i := len(frames) - 1; i >= 0; i-- {
+				exception.Stacktrace.Frames = append(exception.Stacktrace.Frames, sentryFrame{
+					Filename: frames[i].File,
+					Function: frames[i].Function,
+					Module:   frames[i].Package,
+					Lineno:   frames[i].Line,
+					InApp:    frames[i].InApp,
+				})
+			}
+		}
+
+		event.Extra = map[string]interface{}{
+			"code":     vx.Code(),
+			"comments": vx.CommentStack(),
+		}
+
+		if key := vx.Key(); key != "" && key != "-" {
+			event.Tags["error.key"] = key
+			event.Fingerprint = []string{key}
+		}
+
+	case error:
+		exception = &sentryException{Type: errorTypeName(vx), Value: vx.Error()}
+	}
+
+	for _, v := range args.Fields {
+		if event.Extra == nil {
+			event.Extra = map[string]interface{}{}
+		}
+
+		event.Extra[v.Key] = v.Value()
+	}
+
+	if exception != nil {
+		event.Exception = &sentryExceptions{Values: []sentryException{*exception}}
+	}
+
+	if len(event.Tags) == 0 {
+		event.Tags = nil
+	}
+
+	return event
+}
+
+func sentryEventID() string {
+	byt := make([]byte, 16)
+	_, _ = rand.Read(byt)
+
+	return hex.EncodeToString(byt)
+}
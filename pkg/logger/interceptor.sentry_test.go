@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentryInterceptor_Options(t *testing.T) {
+	testTable := []struct {
+		name string
+		dsn  string
+	}{
+		{name: "empty", dsn: ""},
+		{name: "no key", dsn: "http://sentry.local/42"},
+		{name: "no project", dsn: "http://key@sentry.local"},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errx := SentryInterceptor(SentryOptions{DSN: tc.dsn, Level: ErrorLevelWarning})
+			assert.NotNil(t, errx)
+		})
+	}
+}
+
+func TestSentryInterceptor_Dispatch(t *testing.T) {
+	srv, requests, bodies := collector(t)
+	dsn := strings.Replace(srv.URL, "://", "://public@", 1) + "/42"
+
+	sentry, errx := SentryInterceptor(SentryOptions{
+		DSN:         dsn,
+		Release:     "1.2.3",
+		Environment: "staging",
+		ServerName:  "api-1",
+		Level:       ErrorLevelWarning,
+	})
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Interceptor: sentry})
+
+	log.Info("below the interceptor level")
+
+	errx = serror.Newik(http.StatusConflict, "email_taken", "email already registered")
+	errx.AddComments("[usecase][Register] while creating user")
+	log.Warn(errx)
+
+	req := <-requests
+	assert.Equal(t, "/api/42/envelope/", req.URL.Path)
+	assert.Equal(t, "application/x-sentry-envelope", req.Header.Get("Content-Type"))
+	assert.Contains(t, req.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+	lines := bytes.Split(bytes.TrimSpace(<-bodies), []byte("\n"))
+	assert.Len(t, lines, 3)
+
+	var header struct {
+		EventID string `json:"event_id"`
+		DSN     string `json:"dsn"`
+	}
+	assert.Nil(t, json.Unmarshal(lines[0], &header))
+	assert.Equal(t, dsn, header.DSN)
+	assert.Len(t, header.EventID, 32)
+
+	var item struct {
+		Type   string `json:"type"`
+		Length int    `json:"length"`
+	}
+	assert.Nil(t, json.Unmarshal(lines[1], &item))
+	assert.Equal(t, "event", item.Type)
+	assert.Equal(t, len(lines[2]), item.Length)
+
+	var event sentryEvent
+	assert.Nil(t, json.Unmarshal(lines[2], &event))
+	assert.Equal(t, header.EventID, event.EventID)
+	assert.Equal(t, "warning", event.Level)
+	assert.Equal(t, "1.2.3", event.Release)
+	assert.Equal(t, "staging", event.Environment)
+	assert.Equal(t, "api-1", event.ServerName)
+	assert.Equal(t, "email already registered", event.Message["formatted"])
+	assert.Equal(t, []string{"email_taken"}, event.Fingerprint)
+	assert.Equal(t, "email_taken", event.Tags["error.key"])
+	assert.Equal(t, float64(http.StatusConflict), event.Extra["code"])
+	assert.Len(t, event.Extra["comments"], 2)
+
+	assert.Len(t, event.Exception.Values, 1)
+	frames := event.Exception.Values[0].Stacktrace.Frames
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "TestSentryInterceptor_Dispatch", frames[len(frames)-1].Function)
+	assert.True(t, frames[len(frames)-1].InApp)
+
+	sentry.Disable()
+	log.Err("disabled")
+	assert.False(t, sentry.IsEnabled())
+	assert.Len(t, requests, 0)
+}
+
+func TestSentryInterceptor_AsyncDispatch(t *testing.T) {
+	srv, _, bodies := collector(t)
+
+	sentry, errx := SentryInterceptor(SentryOptions{
+		DSN:   strings.Replace(srv.URL, "://", "://public@", 1) + "/42",
+		Level: ErrorLevelCritical,
+	})
+	assert.Nil(t, errx)
+
+	log := Construct(Options{Interceptor: AsyncInterceptor(sentry, AsyncOptions{QueueSize: 4})})
+	log.Err(serror.Newik(http.StatusInternalServerError, "db_unavailable", "database is down"))
+	assert.Nil(t, log.Close())
+
+	lines := bytes.Split(bytes.TrimSpace(<-bodies), []byte("\n"))
+
+	var event sentryEvent
+	assert.Nil(t, json.Unmarshal(lines[2], &event))
+	assert.Equal(t, []string{"db_unavailable"}, event.Fingerprint)
+}
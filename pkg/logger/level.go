@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultLevel is the global minimum level used when nothing is configured
+const DefaultLevel = ErrorLevelLog
+
+var errorLevelSeverities = map[ErrorLevel]int{
+	ErrorLevelDebug:    0,
+	ErrorLevelLog:      1,
+	ErrorLevelInfo:     2,
+	ErrorLevelWarning:  3,
+	ErrorLevelCritical: 4,
+}
+
+// ParseErrorLevel returns the level named s, "error" and "warning" are accepted as aliases
+func ParseErrorLevel(s string) (ErrorLevel, error) {
+	lvl := ErrorLevel(strings.ToLower(strings.TrimSpace(s)))
+	switch lvl {
+	case "error", "err":
+		lvl = ErrorLevelCritical
+	case "warning":
+		lvl = ErrorLevelWarning
+	}
+
+	if !lvl.IsValid() {
+		return "", fmt.Errorf("unknown log level %q", s)
+	}
+
+	return lvl, nil
+}
+
+// IsValid reports whether the level is known
+func (lvl ErrorLevel) IsValid() bool {
+	_, ok := errorLevelSeverities[lvl]
+	return ok
+}
+
+// Allows reports whether an entry of level entry passes the lvl threshold
+func (lvl ErrorLevel) Allows(entry ErrorLevel) bool {
+	min, ok := errorLevelSeverities[lvl]
+	if !ok {
+		return true
+	}
+
+	return errorLevelSeverities[entry] >= min
+}
+
+type (
+	// LeveledInterceptor is an interceptor dropping entries below its own minimum level
+	LeveledInterceptor interface {
+		LogDispatcher
+		// MinLevel returns the interceptor minimum level
+		MinLevel() ErrorLevel
+		// SetMinLevel to change the interceptor minimum level
+		SetMinLevel(lvl ErrorLevel)
+		// Unwrap returns the wrapped interceptor
+		Unwrap() LogInterceptor
+	}
+
+	leveledInterceptorObj struct {
+		mu        sync.RWMutex
+		level     ErrorLevel
+		intercept LogInterceptor
+	}
+)
+
+// WithMinLevel wraps intercept so Process skips entries below lvl
+func WithMinLevel(intercept LogInterceptor, lvl ErrorLevel) LeveledInterceptor {
+	if cur, ok := intercept.(LeveledInterceptor); ok {
+		cur.SetMinLevel(lvl)
+		return cur
+	}
+
+	return &leveledInterceptorObj{
+		level:     lvl,
+		intercept: intercept,
+	}
+}
+
+func (ox *leveledInterceptorObj) Translate(args LogInterceptorTranslateArguments) string {
+	return ox.intercept.Translate(args)
+}
+
+func (ox *leveledInterceptorObj) Process(lvl ErrorLevel, msg string) {
+	if !ox.MinLevel().Allows(lvl) {
+		return
+	}
+
+	ox.intercept.Process(lvl, msg)
+}
+
+func (ox *leveledInterceptorObj) Dispatch(args LogInterceptorTranslateArguments, msg string) {
+	if !ox.MinLevel().Allows(args.Level) {
+		return
+	}
+
+	dispatch(ox.intercept, args, msg)
+}
+
+func (ox *leveledInterceptorObj) MinLevel() ErrorLevel {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	return ox.level
+}
+
+func (ox *leveledInterceptorObj) SetMinLevel(lvl ErrorLevel) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.level = lvl
+}
+
+func (ox *leveledInterceptorObj) Unwrap() LogInterceptor {
+	return ox.intercept
+}
+
+// levelFilter holds the global minimum level and the per layerName overrides
+type levelFilter struct {
+	mu     sync.RWMutex
+	global ErrorLevel
+	layers map[string]ErrorLevel
+}
+
+func (ox *levelFilter) level() ErrorLevel {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	if ox.global == "" {
+		return DefaultLevel
+	}
+
+	return ox.global
+}
+
+func (ox *levelFilter) setLevel(lvl ErrorLevel) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.global = lvl
+}
+
+func (ox *levelFilter) layerLevels() map[string]ErrorLevel {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	res := make(map[string]ErrorLevel, len(ox.layers))
+	for k, v := range ox.layers {
+		res[k] = v
+	}
+
+	return res
+}
+
+func (ox *levelFilter) setLayerLevel(layerName string, lvl ErrorLevel) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	if ox.layers == nil {
+		ox.layers = make(map[string]ErrorLevel)
+	}
+
+	ox.layers[layerName] = lvl
+}
+
+func (ox *levelFilter) clearLayerLevel(layerName string) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	delete(ox.layers, layerName)
+}
+
+// allows checks the layerName override first then the global level
+func (ox *levelFilter) allows(layerName string, lvl ErrorLevel) bool {
+	ox.mu.RLock()
+	min, ok := ox.layers[layerName]
+	if !ok || layerName == "" {
+		min = ox.global
+	}
+	ox.mu.RUnlock()
+
+	if min == "" {
+		min = DefaultLevel
+	}
+
+	return min.Allows(lvl)
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordInterceptor struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (ox *recordInterceptor) Translate(args LogInterceptorTranslateArguments) string {
+	return fmt.Sprintf("%s:%v", args.Level, args.Payload)
+}
+
+func (ox *recordInterceptor) Process(lvl ErrorLevel, msg string) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.entries = append(ox.entries, msg)
+}
+
+func (ox *recordInterceptor) flush() []string {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	res := ox.entries
+	ox.entries = nil
+	return res
+}
+
+func TestParseErrorLevel(t *testing.T) {
+	testTable := []struct {
+		input     string
+		want      ErrorLevel
+		wantError bool
+	}{
+		{input: "debug", want: ErrorLevelDebug},
+		{input: " INFO ", want: ErrorLevelInfo},
+		{input: "warning", want: ErrorLevelWarning},
+		{input: "error", want: ErrorLevelCritical},
+		{input: "verbose", wantError: true},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.input, func(t *testing.T) {
+			lvl, err := ParseErrorLevel(tc.input)
+			if tc.wantError {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, lvl)
+		})
+	}
+}
+
+func TestLogger_Level(t *testing.T) {
+	record := &recordInterceptor{}
+	log := Construct(Options{Level: ErrorLevelInfo, Interceptor: record})
+
+	log.Debug("hidden")
+	log.Log("hidden")
+	log.Info("shown")
+	log.Warnf("shown %d", 2)
+	assert.Equal(t, []string{"info:shown", "warn:shown 2"}, record.flush())
+
+	log.SetLevel(ErrorLevelDebug)
+	log.Debugf("shown %s", "now")
+	assert.Equal(t, []string{"debug:shown now"}, record.flush())
+}
+
+func TestLogger_LayerLevel(t *testing.T) {
+	record := &recordInterceptor{}
+	log := Construct(Options{Level: ErrorLevelWarning, Interceptor: record})
+
+	repo := log.CreateSquad(context.Background(), "repository")
+	usecase := log.CreateSquad(context.Background(), "usecase")
+
+	log.SetLayerLevel("repository", ErrorLevelDebug)
+	repo.Debug("query")
+	usecase.Debug("hidden")
+	usecase.Info("hidden")
+	assert.Equal(t, []string{"debug:query"}, record.flush())
+	assert.Equal(t, map[string]ErrorLevel{"repository": ErrorLevelDebug}, log.LayerLevels())
+
+	log.ClearLayerLevel("repository")
+	repo.Debug("hidden")
+	repo.Warn("shown")
+	assert.Equal(t, []string{"warn:shown"}, record.flush())
+	assert.Empty(t, log.LayerLevels())
+}
+
+func TestLogger_InterceptorLevel(t *testing.T) {
+	record := &recordInterceptor{}
+	log := Construct(Options{Level: ErrorLevelDebug, Interceptor: record})
+	assert.Equal(t, ErrorLevelDebug, log.InterceptorLevel())
+
+	log.SetInterceptorLevel(ErrorLevelWarning)
+	log.SetInterceptorLevel(ErrorLevelInfo)
+	assert.Equal(t, ErrorLevelInfo, log.InterceptorLevel())
+	assert.Equal(t, record, log.Interceptor().(LeveledInterceptor).Unwrap())
+
+	log.Log("hidden")
+	log.Info("shown")
+	assert.Equal(t, []string{"info:shown"}, record.flush())
+}
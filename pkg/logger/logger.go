@@ -3,6 +3,8 @@ package logger
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"codepair-sinarmas/pkg/serror"
 	"codepair-sinarmas/pkg/utils/utinterface"
@@ -50,14 +52,35 @@ const (
 
 // Options struct
 type Options struct {
-	Mode        Mode           `valid:"-"`
-	Path        string         `valid:"-"`
-	Writing     bool           `valid:"-"`
-	FileFormat  string         `valid:"-"`
-	Interceptor LogInterceptor `valid:"-"`
+	Level         ErrorLevel     `valid:"-"`
+	Mode          Mode           `valid:"-"`
+	Path          string         `valid:"-"`
+	Writing       bool           `valid:"-"`
+	FileFormat    string         `valid:"-"`
+	MaxSize       int64          `valid:"-"`
+	MaxAge        time.Duration  `valid:"-"`
+	MaxBackups    int            `valid:"-"`
+	Compress      bool           `valid:"-"`
+	FlushInterval time.Duration  `valid:"-"`
+	Interceptor   LogInterceptor `valid:"-"`
+	// Redactor masks sensitive data before any interceptor sees it, DefaultRedactor is used when nil
+	Redactor *Redactor `valid:"-"`
+	// WriteQueueSize is how many messages may wait to be written to the log file, DefaultWriteQueueSize when 0
+	WriteQueueSize int `valid:"-"`
+	// WriteBufferSize is the log file write buffer size, DefaultWriteBufferSize when 0
+	WriteBufferSize int `valid:"-"`
+	// WritePolicy is applied once the write queue is full, WritePolicyBlock when 0
+	WritePolicy WritePolicy `valid:"-"`
+	// FsyncPolicy decides when the log file is synced, FsyncOnFlush when 0
+	FsyncPolicy FsyncPolicy `valid:"-"`
 }
 
 type logCommon interface {
+	// Debug to logging with debug level
+	Debug(msg interface{})
+	// Debugf to logging with string binding and debug level
+	Debugf(msg string, args ...interface{})
+
 	// Info to logging with info level
 	Info(msg interface{})
 	// Infof to logging with string binding and info level
@@ -80,13 +103,18 @@ type logCommon interface {
 
 	// Panic to logging panic error
 	Panic(msg interface{})
+
+	// With returns a squad adding fields to every entry, a field replaces the one already having its key
+	With(fields ...Field) LogSquad
 }
 
 type (
 	logger struct {
 		logWriterObj
+		levels            levelFilter
 		isReady           bool
 		activeInterceptor LogInterceptor
+		redactor          *Redactor
 	}
 
 	// Logger service
@@ -101,6 +129,26 @@ type (
 
 		// SetInterceptor to set the log interceptor
 		SetInterceptor(intercept LogInterceptor)
+		// Interceptor returns the active log interceptor
+		Interceptor() LogInterceptor
+		// SetInterceptorLevel to set the minimum level processed by the active interceptor
+		SetInterceptorLevel(lvl ErrorLevel)
+		// InterceptorLevel returns the minimum level processed by the active interceptor
+		InterceptorLevel() ErrorLevel
+
+		// SetRedactor to replace the redactor, nil disables the redaction
+		SetRedactor(redactor *Redactor)
+
+		// SetLevel to set the global minimum level
+		SetLevel(lvl ErrorLevel)
+		// Level returns the global minimum level
+		Level() ErrorLevel
+		// SetLayerLevel to override the minimum level of squads created with layerName
+		SetLayerLevel(layerName string, lvl ErrorLevel)
+		// ClearLayerLevel to remove the layerName override
+		ClearLayerLevel(layerName string)
+		// LayerLevels returns every layerName override
+		LayerLevels() map[string]ErrorLevel
 
 		// CreateSquad returns the new squad logger instance
 		CreateSquad(ctx context.Context, layerName string) LogSquad
@@ -115,15 +163,33 @@ func Construct(opt Options) Logger {
 		opt.Interceptor = DefaultInterceptor()
 	}
 
+	if opt.Redactor == nil {
+		opt.Redactor = DefaultRedactor()
+	}
+
 	log := &logger{
 		logWriterObj: logWriterObj{
-			Mode:       opt.Mode,
-			Path:       opt.Path,
-			isWriting:  opt.Writing,
-			FileFormat: utstring.Chains(opt.FileFormat, "log-%v.log"),
+			Mode:          opt.Mode,
+			Path:          opt.Path,
+			isWriting:     opt.Writing,
+			FileFormat:    utstring.Chains(opt.FileFormat, "log-%v.log"),
+			MaxSize:       opt.MaxSize,
+			MaxAge:        opt.MaxAge,
+			MaxBackups:    opt.MaxBackups,
+			Compress:      opt.Compress,
+			FlushInterval: opt.FlushInterval,
+			QueueSize:     opt.WriteQueueSize,
+			BufferSize:    opt.WriteBufferSize,
+			WritePolicy:   opt.WritePolicy,
+			FsyncPolicy:   opt.FsyncPolicy,
+		},
+
+		levels: levelFilter{
+			global: opt.Level,
 		},
 
 		activeInterceptor: opt.Interceptor,
+		redactor:          opt.Redactor,
 	}
 	return log
 }
@@ -143,14 +209,84 @@ func (ox *logger) Startup() (err error) {
 	return
 }
 
+// Close to close the log writer then the interceptor when it holds resources, such as async queues
+func (ox *logger) Close() (err error) {
+	err = ox.logWriterObj.Close()
+
+	intercept := ox.Interceptor()
+	if cur, ok := intercept.(LeveledInterceptor); ok {
+		intercept = cur.Unwrap()
+	}
+
+	if cur, ok := intercept.(io.Closer); ok {
+		if errs := cur.Close(); errs != nil && err == nil {
+			err = errs
+		}
+	}
+
+	return err
+}
+
 func (ox *logger) IsReady() bool {
 	return ox.isReady
 }
 
 func (ox *logger) SetInterceptor(intercept LogInterceptor) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
 	ox.activeInterceptor = intercept
 }
 
+func (ox *logger) Interceptor() LogInterceptor {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	return ox.activeInterceptor
+}
+
+func (ox *logger) SetRedactor(redactor *Redactor) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.redactor = redactor
+}
+
+func (ox *logger) SetInterceptorLevel(lvl ErrorLevel) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.activeInterceptor = WithMinLevel(ox.activeInterceptor, lvl)
+}
+
+func (ox *logger) InterceptorLevel() ErrorLevel {
+	if cur, ok := ox.Interceptor().(LeveledInterceptor); ok {
+		return cur.MinLevel()
+	}
+
+	return ErrorLevelDebug
+}
+
+func (ox *logger) SetLevel(lvl ErrorLevel) {
+	ox.levels.setLevel(lvl)
+}
+
+func (ox *logger) Level() ErrorLevel {
+	return ox.levels.level()
+}
+
+func (ox *logger) SetLayerLevel(layerName string, lvl ErrorLevel) {
+	ox.levels.setLayerLevel(layerName, lvl)
+}
+
+func (ox *logger) ClearLayerLevel(layerName string) {
+	ox.levels.clearLayerLevel(layerName)
+}
+
+func (ox *logger) LayerLevels() map[string]ErrorLevel {
+	return ox.levels.layerLevels()
+}
+
 func (ox *logger) CreateSquad(ctx context.Context, layerName string) LogSquad {
 	squad := &logSquadObj{
 		logger:    ox,
@@ -170,15 +306,28 @@ func (ox *logger) CreateSquad(ctx context.Context, layerName string) LogSquad {
 	return squad
 }
 
-func (ox *logger) Info(msg interface{}) {
-	lvl := ErrorLevelInfo
+func (ox *logger) With(fields ...Field) LogSquad {
+	return &logSquadObj{
+		logger: ox,
+		tags:   make(map[string]string),
+		fields: mergeFields(nil, fields),
+	}
+}
 
-	m := ox.activeInterceptor.Translate(LogInterceptorTranslateArguments{
-		Level:   lvl,
-		Payload: msg,
-	})
-	ox.activeInterceptor.Process(lvl, m)
-	_ = ox.write(m)
+func (ox *logger) Debug(msg interface{}) {
+	ox.process(ErrorLevelDebug, "", nil, nil, msg)
+}
+
+func (ox *logger) Debugf(msg string, args ...interface{}) {
+	if !ox.levels.allows("", ErrorLevelDebug) {
+		return
+	}
+
+	ox.Debug(fmt.Sprintf(msg, args...))
+}
+
+func (ox *logger) Info(msg interface{}) {
+	ox.process(ErrorLevelInfo, "", nil, nil, msg)
 }
 
 func (ox *logger) Infof(msg string, args ...interface{}) {
@@ -186,14 +335,7 @@ func (ox *logger) Infof(msg string, args ...interface{}) {
 }
 
 func (ox *logger) Log(msg interface{}) {
-	lvl := ErrorLevelLog
-
-	m := ox.activeInterceptor.Translate(LogInterceptorTranslateArguments{
-		Level:   lvl,
-		Payload: msg,
-	})
-	ox.activeInterceptor.Process(lvl, m)
-	_ = ox.write(m)
+	ox.process(ErrorLevelLog, "", nil, nil, msg)
 }
 
 func (ox *logger) Logf(msg string, args ...interface{}) {
@@ -201,14 +343,7 @@ func (ox *logger) Logf(msg string, args ...interface{}) {
 }
 
 func (ox *logger) Warn(msg interface{}) {
-	lvl := ErrorLevelWarning
-
-	m := ox.activeInterceptor.Translate(LogInterceptorTranslateArguments{
-		Level:   lvl,
-		Payload: msg,
-	})
-	ox.activeInterceptor.Process(lvl, m)
-	_ = ox.write(m)
+	ox.process(ErrorLevelWarning, "", nil, nil, msg)
 }
 
 func (ox *logger) Warnf(msg string, args ...interface{}) {
@@ -216,14 +351,7 @@ func (ox *logger) Warnf(msg string, args ...interface{}) {
 }
 
 func (ox *logger) Err(msg interface{}) {
-	lvl := ErrorLevelCritical
-
-	m := ox.activeInterceptor.Translate(LogInterceptorTranslateArguments{
-		Level:   lvl,
-		Payload: msg,
-	})
-	ox.activeInterceptor.Process(lvl, m)
-	_ = ox.write(m)
+	ox.process(ErrorLevelCritical, "", nil, nil, msg)
 }
 
 func (ox *logger) Errf(msg string, args ...interface{}) {
@@ -232,5 +360,62 @@ func (ox *logger) Errf(msg string, args ...interface{}) {
 
 func (ox *logger) Panic(msg interface{}) {
 	ox.Err(castToSError(msg, 1))
-	exit()
+	exit(ox)
+}
+
+// private
+
+// process filters by level then redacts, translates, processes and writes the entry
+func (ox *logger) process(lvl ErrorLevel, layerName string, tags map[string]string, fields []Field, msg interface{}) {
+	if !ox.levels.allows(layerName, lvl) {
+		return
+	}
+
+	ox.mu.Lock()
+	intercept, redactor := ox.activeInterceptor, ox.redactor
+	ox.mu.Unlock()
+
+	args := redactor.Arguments(LogInterceptorTranslateArguments{
+		Level:   lvl,
+		Tags:    signatureTags(tags, msg),
+		Fields:  fields,
+		Payload: msg,
+	})
+
+	m := redactor.String(intercept.Translate(args))
+	dispatch(intercept, args, m)
+	_ = ox.write(m)
+}
+
+// signatureTags returns tags completed with the signature of msg when it is a signed SError,
+// the tags of the squad win over the signature ones
+func signatureTags(tags map[string]string, msg interface{}) map[string]string {
+	errx, ok := msg.(serror.SError)
+	if !ok || errx == nil {
+		return tags
+	}
+
+	sig := errx.Signature()
+	if sig.IsZero() {
+		return tags
+	}
+
+	res := make(map[string]string, len(tags)+5)
+	for k, v := range map[string]string{
+		LogSquadTagRequestID:  sig.RequestID,
+		LogSquadTagRoute:      sig.Route,
+		LogSquadTagMethod:     sig.Method,
+		LogSquadTagUserID:     sig.UserID,
+		LogSquadTagErrorLayer: sig.Layer,
+	} {
+		if v != "" {
+			res[k] = v
+		}
+	}
+
+	for k, v := range tags {
+		res[k] = v
+	}
+
+	return res
 }
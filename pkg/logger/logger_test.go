@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tagInterceptor struct {
+	mu     sync.Mutex
+	tags   []map[string]string
+	fields [][]Field
+}
+
+func (ox *tagInterceptor) Translate(args LogInterceptorTranslateArguments) string {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.tags = append(ox.tags, args.Tags)
+	ox.fields = append(ox.fields, args.Fields)
+	return ""
+}
+
+func (ox *tagInterceptor) Process(lvl ErrorLevel, msg string) {}
+
+func TestLogger_SignatureTags(t *testing.T) {
+	record := &tagInterceptor{}
+	log := Construct(Options{Interceptor: record})
+
+	errx := serror.Newi(http.StatusNotFound, "user not found")
+	errx.SetSignature(serror.Signature{RequestID: "req-1", UserID: "7", Route: "/v1/users/:id", Method: http.MethodGet, Layer: "usecase"})
+
+	log.Err(errx)
+
+	squad := log.CreateSquad(nil, "handler")
+	squad.SetTag(LogSquadTagRequestID, "req-2")
+	squad.Err(errx)
+
+	log.Err(serror.New("unsigned"))
+
+	assert.Equal(t, []map[string]string{
+		{
+			LogSquadTagRequestID:  "req-1",
+			LogSquadTagUserID:     "7",
+			LogSquadTagRoute:      "/v1/users/:id",
+			LogSquadTagMethod:     http.MethodGet,
+			LogSquadTagErrorLayer: "usecase",
+		},
+		{
+			LogSquadTagRequestID:  "req-2",
+			LogSquadTagUserID:     "7",
+			LogSquadTagRoute:      "/v1/users/:id",
+			LogSquadTagMethod:     http.MethodGet,
+			LogSquadTagErrorLayer: "usecase",
+		},
+		nil,
+	}, record.tags)
+}
+
+func TestLogger_With(t *testing.T) {
+	record := &tagInterceptor{}
+	log := Construct(Options{Interceptor: record})
+
+	squad := log.CreateSquad(nil, "usecase")
+	squad.SetTag(LogSquadTagRequestID, "req-1")
+
+	withUser := squad.With(Int("userID", 7), String("email", "budi@example.com"))
+	withUser.With(Duration("elapsed", time.Second), Int("userID", 8)).Info("registered")
+	withUser.Layer("repository").Warn("slow query")
+	squad.Info("no fields")
+	log.With(Error(errors.New("smtp unavailable"))).Warn("mail not sent")
+
+	assert.Equal(t, [][]Field{
+		{String("email", "b***@example.com"), Duration("elapsed", time.Second), Int("userID", 8)},
+		{Int("userID", 7), String("email", "b***@example.com")},
+		nil,
+	}, record.fields[:3])
+	assert.Equal(t, "smtp unavailable", record.fields[3][0].Value())
+	assert.Equal(t, "req-1", record.tags[0][LogSquadTagRequestID])
+	assert.Equal(t, "repository", record.tags[1][LogSquadTagLayerName])
+}
+
+func TestField_Text(t *testing.T) {
+	testCases := []struct {
+		field Field
+		want  string
+	}{
+		{field: String("name", "budi"), want: "name=budi"},
+		{field: String("name", "budi santoso"), want: `name="budi santoso"`},
+		{field: String("name", ""), want: `name=""`},
+		{field: Int("attempts", 3), want: "attempts=3"},
+		{field: Duration("elapsed", 1500*time.Millisecond), want: "elapsed=1.5s"},
+		{field: Error(nil), want: "error=<nil>"},
+		{field: Any("ids", []int{1, 2}), want: "ids=[1 2]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, fieldsText([]Field{tc.field}))
+		})
+	}
+}
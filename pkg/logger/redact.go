@@ -0,0 +1,325 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"codepair-sinarmas/pkg/serror"
+)
+
+// RedactMask replaces every redacted value
+const RedactMask = "[REDACTED]"
+
+// DefaultRedactKeys are the keys whose values are always masked, a key matches when it contains one of them
+var DefaultRedactKeys = []string{"password", "otp", "token", "authorization", "secret"}
+
+type (
+	// RedactPattern masks every match of Regexp with the Replace result
+	RedactPattern struct {
+		Name    string
+		Regexp  *regexp.Regexp
+		Replace func(match string) string
+	}
+
+	// RedactOptions type
+	RedactOptions struct {
+		// Keys are matched case insensitively against map keys, struct fields and key:value or key=value pairs
+		Keys []string
+		// Patterns are applied to every string after the keys
+		Patterns []RedactPattern
+	}
+
+	// Redactor masks sensitive data in log payloads and messages
+	Redactor struct {
+		keys     []string
+		keyValue *regexp.Regexp
+		patterns []RedactPattern
+	}
+)
+
+var (
+	// RedactPatternEmail keeps the first character and the domain of an email address
+	RedactPatternEmail = RedactPattern{
+		Name:   "email",
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replace: func(match string) string {
+			at := strings.LastIndex(match, "@")
+			return match[:1] + "***" + match[at:]
+		},
+	}
+
+	// RedactPatternPhone keeps the last 3 digits of an international, 0 or 62 prefixed phone number
+	RedactPatternPhone = RedactPattern{
+		Name:   "phone",
+		Regexp: regexp.MustCompile(`(?:\+\d{8,15}|\b(?:0|62)\d{7,13})\b`),
+		Replace: func(match string) string {
+			return "***" + match[len(match)-3:]
+		},
+	}
+
+	// RedactPatternJWT masks a compact serialized JWT
+	RedactPatternJWT = RedactPattern{
+		Name:   "jwt",
+		Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		Replace: func(string) string {
+			return RedactMask
+		},
+	}
+)
+
+// NewRedactor to create a redactor, the patterns run in the given order
+func NewRedactor(opt RedactOptions) *Redactor {
+	ox := &Redactor{
+		patterns: opt.Patterns,
+	}
+
+	quoted := make([]string, 0, len(opt.Keys))
+	for _, v := range opt.Keys {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			ox.keys = append(ox.keys, v)
+			quoted = append(quoted, regexp.QuoteMeta(v))
+		}
+	}
+
+	if len(quoted) > 0 {
+		ox.keyValue = regexp.MustCompile(`(?i)("?[\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*"?\s*[:=]\s*)` +
+			`("[^"]*"|bearer\s+[^\s,;\[\]{}&"]+|[^\s,;\[\]{}&"]+)`)
+	}
+
+	return ox
+}
+
+// DefaultRedactor masks the DefaultRedactKeys, emails, phone numbers and JWTs
+func DefaultRedactor() *Redactor {
+	return NewRedactor(RedactOptions{
+		Keys:     DefaultRedactKeys,
+		Patterns: []RedactPattern{RedactPatternJWT, RedactPatternEmail, RedactPatternPhone},
+	})
+}
+
+// IsSensitiveKey reports whether the values of key must be masked
+func (ox *Redactor) IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, v := range ox.keys {
+		if strings.Contains(key, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String masks the sensitive key values and patterns of s
+func (ox *Redactor) String(s string) string {
+	if ox == nil || s == "" {
+		return s
+	}
+
+	if ox.keyValue != nil {
+		s = ox.keyValue.ReplaceAllStringFunc(s, func(match string) string {
+			sub := ox.keyValue.FindStringSubmatch(match)
+			if strings.HasPrefix(sub[2], `"`) {
+				return sub[1] + `"` + RedactMask + `"`
+			}
+
+			return sub[1] + RedactMask
+		})
+	}
+
+	for _, v := range ox.patterns {
+		s = v.Regexp.ReplaceAllStringFunc(s, v.Replace)
+	}
+
+	return s
+}
+
+// Payload returns a redacted copy of a log payload, maps and structs are converted through their JSON form
+func (ox *Redactor) Payload(payload interface{}) interface{} {
+	if ox == nil || payload == nil {
+		return payload
+	}
+
+	switch vx := payload.(type) {
+	case string:
+		return ox.String(vx)
+
+	case serror.SError:
+		return &redactedSError{SError: vx, redactor: ox}
+
+	case error:
+		return &redactedError{err: vx, redactor: ox}
+
+	case fmt.Stringer:
+		return ox.String(vx.String())
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(payload)).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		byt, err := json.Marshal(payload)
+		if err != nil {
+			return ox.String(fmt.Sprintf("%v", payload))
+		}
+
+		var data interface{}
+		decoder := json.NewDecoder(bytes.NewReader(byt))
+		decoder.UseNumber()
+		if err = decoder.Decode(&data); err != nil {
+			return ox.String(fmt.Sprintf("%v", payload))
+		}
+
+		return ox.value(data)
+	}
+
+	return payload
+}
+
+// Arguments returns args with a redacted payload and tags
+func (ox *Redactor) Arguments(args LogInterceptorTranslateArguments) LogInterceptorTranslateArguments {
+	if ox == nil {
+		return args
+	}
+
+	if len(args.Tags) > 0 {
+		tags := make(map[string]string, len(args.Tags))
+		for k, v := range args.Tags {
+			if ox.IsSensitiveKey(k) {
+				v = RedactMask
+			}
+
+			tags[k] = ox.String(v)
+		}
+
+		args.Tags = tags
+	}
+
+	if len(args.Fields) > 0 {
+		fields := make([]Field, len(args.Fields))
+		for i, v := range args.Fields {
+			fields[i] = ox.Field(v)
+		}
+
+		args.Fields = fields
+	}
+
+	args.Payload = ox.Payload(args.Payload)
+	args.redactor = ox
+	return args
+}
+
+// Field returns a redacted copy of field, the values of sensitive keys are masked whatever their type
+func (ox *Redactor) Field(field Field) Field {
+	if ox == nil {
+		return field
+	}
+
+	if ox.IsSensitiveKey(field.Key) {
+		return String(field.Key, RedactMask)
+	}
+
+	switch field.Type {
+	case FieldTypeString:
+		field.String = ox.String(field.String)
+
+	case FieldTypeError:
+		if field.Err != nil {
+			field.Err = ox.Payload(field.Err).(error)
+		}
+
+	case FieldTypeAny:
+		field.Any = ox.Payload(field.Any)
+	}
+
+	return field
+}
+
+// private
+
+func (ox *Redactor) value(data interface{}) interface{} {
+	switch vx := data.(type) {
+	case map[string]interface{}:
+		for k, v := range vx {
+			if ox.IsSensitiveKey(k) {
+				vx[k] = RedactMask
+				continue
+			}
+
+			vx[k] = ox.value(v)
+		}
+
+	case []interface{}:
+		for i, v := range vx {
+			vx[i] = ox.value(v)
+		}
+
+	case string:
+		return ox.String(vx)
+	}
+
+	return data
+}
+
+type redactedError struct {
+	err      error
+	redactor *Redactor
+}
+
+func (ox *redactedError) Error() string {
+	return ox.redactor.String(ox.err.Error())
+}
+
+func (ox *redactedError) Unwrap() error {
+	return ox.err
+}
+
+// redactedSError masks every message and comment of the wrapped error
+type redactedSError struct {
+	serror.SError
+	redactor *Redactor
+}
+
+func (ox *redactedSError) Error() string {
+	return ox.redactor.String(ox.SError.Error())
+}
+
+func (ox *redactedSError) Title() string {
+	return ox.redactor.String(ox.SError.Title())
+}
+
+func (ox *redactedSError) Comments() string {
+	return ox.redactor.String(ox.SError.Comments())
+}
+
+func (ox *redactedSError) CommentStack() []string {
+	comments := ox.SError.CommentStack()
+	res := make([]string, len(comments))
+	for i, v := range comments {
+		res[i] = ox.redactor.String(v)
+	}
+
+	return res
+}
+
+func (ox *redactedSError) String() string {
+	return ox.redactor.String(ox.SError.String())
+}
+
+func (ox *redactedSError) SimpleString() string {
+	return ox.redactor.String(ox.SError.SimpleString())
+}
+
+func (ox *redactedSError) ColoredString() string {
+	return ox.redactor.String(ox.SError.ColoredString())
+}
+
+func (ox *redactedSError) Unwrap() error {
+	return ox.SError
+}
+
+// MarshalJSON serializes the redacted message and comments
+func (ox *redactedSError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(serror.NewPayload(ox))
+}
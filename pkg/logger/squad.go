@@ -13,6 +13,7 @@ type logSquadObj struct {
 	logger    *logger
 	layerName string
 	tags      map[string]string
+	fields    []Field
 }
 
 type LogSquad interface {
@@ -20,12 +21,22 @@ type LogSquad interface {
 
 	// SetTag to set logger tag name and value
 	SetTag(name string, value interface{}) (ok bool)
+	// Layer returns a squad sharing a copy of the tags and the fields under another layerName
+	Layer(layerName string) LogSquad
 }
 
 const (
 	LogSquadTagLayerName      = "layerName"
 	LogSquadTagDatadogTraceID = "ddTraceID"
 	LogSquadTagDatadogSpanID  = "ddSpanID"
+
+	// tags filled from the signature of a logged SError when the squad does not carry them
+	LogSquadTagRequestID  = "requestID"
+	LogSquadTagRoute      = "route"
+	LogSquadTagMethod     = "method"
+	LogSquadTagUserID     = "userID"
+	LogSquadTagUsername   = "username"
+	LogSquadTagErrorLayer = "errorLayer"
 )
 
 var (
@@ -52,6 +63,53 @@ func (ox *logSquadObj) SetTag(name string, value interface{}) (ok bool) {
 	return
 }
 
+func (ox *logSquadObj) Layer(layerName string) LogSquad {
+	ox.logger.mu.Lock()
+	defer ox.logger.mu.Unlock()
+
+	tags := make(map[string]string, len(ox.tags)+1)
+	for k, v := range ox.tags {
+		tags[k] = v
+	}
+	tags[LogSquadTagLayerName] = layerName
+
+	return &logSquadObj{
+		logger:    ox.logger,
+		layerName: layerName,
+		tags:      tags,
+		fields:    ox.fields,
+	}
+}
+
+func (ox *logSquadObj) With(fields ...Field) LogSquad {
+	ox.logger.mu.Lock()
+	defer ox.logger.mu.Unlock()
+
+	tags := make(map[string]string, len(ox.tags))
+	for k, v := range ox.tags {
+		tags[k] = v
+	}
+
+	return &logSquadObj{
+		logger:    ox.logger,
+		layerName: ox.layerName,
+		tags:      tags,
+		fields:    mergeFields(ox.fields, fields),
+	}
+}
+
+func (ox *logSquadObj) Debug(msg interface{}) {
+	ox.process(ErrorLevelDebug, msg)
+}
+
+func (ox *logSquadObj) Debugf(msg string, args ...interface{}) {
+	if !ox.logger.levels.allows(ox.layerName, ErrorLevelDebug) {
+		return
+	}
+
+	ox.Debug(fmt.Sprintf(msg, args...))
+}
+
 func (ox *logSquadObj) Info(msg interface{}) {
 	ox.process(ErrorLevelInfo, msg)
 }
@@ -86,7 +144,7 @@ func (ox *logSquadObj) Errf(msg string, args ...interface{}) {
 
 func (ox *logSquadObj) Panic(msg interface{}) {
 	ox.Err(castToSError(msg, 1))
-	exit()
+	exit(ox.logger)
 }
 
 func (ox logSquadObj) isWellKnownTag(name string) bool {
@@ -98,11 +156,5 @@ func (ox logSquadObj) isWellKnownTag(name string) bool {
 }
 
 func (ox logSquadObj) process(lvl ErrorLevel, msg interface{}) {
-	finalMsg := ox.logger.activeInterceptor.Translate(LogInterceptorTranslateArguments{
-		Level:   lvl,
-		Tags:    ox.tags,
-		Payload: msg,
-	})
-	ox.logger.activeInterceptor.Process(lvl, finalMsg)
-	_ = ox.logger.write(finalMsg)
+	ox.logger.process(lvl, ox.layerName, ox.tags, ox.fields, msg)
 }
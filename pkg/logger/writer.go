@@ -1,26 +1,99 @@
 package logger
 
 import (
+	"bufio"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"codepair-sinarmas/pkg/utils/utpath"
 	"codepair-sinarmas/pkg/utils/uttime"
 )
 
+const (
+	// DefaultFlushInterval is used when no flush interval is configured
+	DefaultFlushInterval = 3 * time.Second
+	// DefaultWriteQueueSize is used when no write queue size is configured
+	DefaultWriteQueueSize = 4096
+	// DefaultWriteBufferSize is used when no write buffer size is configured
+	DefaultWriteBufferSize = 64 * 1024
+
+	rotatedFileTimeFormat = "20060102T150405.000"
+	compressedFileExt     = ".gz"
+)
+
+var fileFormatBind = regexp.MustCompile(`%[dmyhisv]`)
+
+// errWriterClosed is returned when writing to a closed log writer
+var errWriterClosed = errors.New("Logger writer closed")
+
+// WritePolicy decides what happens to a message written while the write queue is full
+type WritePolicy int
+
+const (
+	// WritePolicyBlock waits until the queue has room, the callers are slowed down to the disk pace
+	WritePolicyBlock WritePolicy = 1 + iota
+
+	// WritePolicyDrop discards the message, the callers never wait on the disk
+	WritePolicyDrop
+)
+
+// FsyncPolicy decides when the log file is synced to the disk
+type FsyncPolicy int
+
+const (
+	// FsyncOnFlush syncs on every flush interval, Flush and Close
+	FsyncOnFlush FsyncPolicy = 1 + iota
+
+	// FsyncAlways syncs after every batch of queued messages
+	FsyncAlways
+
+	// FsyncNever leaves the syncing to the operating system
+	FsyncNever
+)
+
+// WriterStats holds the log writer counters
+type WriterStats struct {
+	Capacity int    `json:"capacity"`
+	Queued   int    `json:"queued"`
+	Written  uint64 `json:"written"`
+	Bytes    uint64 `json:"bytes"`
+	Dropped  uint64 `json:"dropped"`
+	Syncs    uint64 `json:"syncs"`
+	Errors   uint64 `json:"errors"`
+}
+
 type logWriterObj struct {
 	mu          sync.Mutex
 	isWriting   bool
 	isReady     bool
 	filePath    string
 	fileName    string
+	fileSize    int64
 	fileStream  *os.File
-	writeQueues []string
+	buffer      *bufio.Writer
+	compressing sync.WaitGroup
+
+	// queueMu guards queue against being closed while a message is sent
+	queueMu  sync.RWMutex
+	queue    chan string
+	flushReq chan chan error
+	done     chan struct{}
+
+	written  uint64
+	bytes    uint64
+	dropped  uint64
+	syncs    uint64
+	failures uint64
 
 	// Path is a log file directory
 	Path string
@@ -28,6 +101,24 @@ type logWriterObj struct {
 	FileFormat string
 	// Mode is a log writing mode
 	Mode Mode
+	// MaxSize is the size in bytes a log file may reach before it gets rotated, 0 disables size based rotation
+	MaxSize int64
+	// MaxAge is how long rotated log files are kept, 0 keeps them forever
+	MaxAge time.Duration
+	// MaxBackups is how many rotated log files are kept, 0 keeps all of them
+	MaxBackups int
+	// Compress to gzip rotated log files
+	Compress bool
+	// FlushInterval is how often the buffered messages are written to the log file
+	FlushInterval time.Duration
+	// QueueSize is how many messages may wait to be written, DefaultWriteQueueSize when 0
+	QueueSize int
+	// BufferSize is the size of the file write buffer, DefaultWriteBufferSize when 0
+	BufferSize int
+	// WritePolicy is applied once the queue is full, WritePolicyBlock when 0
+	WritePolicy WritePolicy
+	// FsyncPolicy decides when the file is synced, FsyncOnFlush when 0
+	FsyncPolicy FsyncPolicy
 }
 
 type logWriter interface {
@@ -37,6 +128,12 @@ type logWriter interface {
 	StartWriting()
 	// StopWriting to disable the logger writing feature
 	StopWriting()
+	// Flush to write every queued message to the log file
+	Flush() error
+	// Close to stop the background writer, flush the queue and close the log file
+	Close() error
+	// WriterStats returns the log writer counters
+	WriterStats() WriterStats
 }
 
 func (ox *logWriterObj) IsWriting() bool {
@@ -51,75 +148,193 @@ func (ox *logWriterObj) StartWriting() {
 	ox.isWriting = true
 }
 
+func (ox *logWriterObj) Flush() error {
+	return ox.flush()
+}
+
+func (ox *logWriterObj) Close() (err error) {
+	ox.queueMu.Lock()
+	queue, done := ox.queue, ox.done
+	if queue != nil {
+		close(queue)
+		ox.queue = nil
+	}
+	ox.queueMu.Unlock()
+
+	// the background writer drains the queue then syncs and closes the file
+	if queue != nil {
+		<-done
+	}
+
+	ox.mu.Lock()
+	err = ox.closeFileLocked(true)
+	ox.fileName = ""
+	ox.isReady = false
+	ox.mu.Unlock()
+
+	ox.compressing.Wait()
+	return err
+}
+
+func (ox *logWriterObj) WriterStats() WriterStats {
+	ox.queueMu.RLock()
+	defer ox.queueMu.RUnlock()
+
+	return WriterStats{
+		Capacity: cap(ox.queue),
+		Queued:   len(ox.queue),
+		Written:  atomic.LoadUint64(&ox.written),
+		Bytes:    atomic.LoadUint64(&ox.bytes),
+		Dropped:  atomic.LoadUint64(&ox.dropped),
+		Syncs:    atomic.LoadUint64(&ox.syncs),
+		Errors:   atomic.LoadUint64(&ox.failures),
+	}
+}
+
 // private
 
 func (ox *logWriterObj) boot() (err error) {
 	if ox.IsWriting() {
-		var (
-			now = time.Now()
-
-			format      = ox.FileFormat
-			formatBinds = map[string]string{
-				"d": now.Format("02"),
-				"m": now.Format("01"),
-				"y": now.Format("2006"),
-				"h": now.Format("15"),
-				"i": now.Format("04"),
-				"s": now.Format("05"),
-				"v": "",
-			}
-		)
+		err = ox.switchPeriod()
+		if err != nil {
+			return
+		}
+	}
 
-		switch ox.Mode {
-		case ModeDaily:
-			formatBinds["v"] = now.Format("20060102")
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
 
-		case ModeMonthly:
-			formatBinds["v"] = now.Format("200601")
+	if !ox.isReady {
+		ox.isReady = true
 
-		case ModeYearly:
-			formatBinds["v"] = now.Format("2006")
+		interval := ox.FlushInterval
+		if interval <= 0 {
+			interval = DefaultFlushInterval
+		}
 
-		case ModePermanent:
-			formatBinds["v"] = ""
+		queueSize := ox.QueueSize
+		if queueSize <= 0 {
+			queueSize = DefaultWriteQueueSize
 		}
 
-		for k, v := range formatBinds {
-			format = strings.ReplaceAll(format, "%"+k, v)
+		ox.queueMu.Lock()
+		ox.queue = make(chan string, queueSize)
+		ox.flushReq = make(chan chan error)
+		ox.done = make(chan struct{})
+		go ox.run(interval, ox.queue, ox.flushReq, ox.done)
+		ox.queueMu.Unlock()
+	}
+
+	return
+}
+
+// switchPeriod opens the file of the current period, the previous period file is archived
+func (ox *logWriterObj) switchPeriod() (err error) {
+	var (
+		now = time.Now()
+
+		format      = ox.FileFormat
+		formatBinds = map[string]string{
+			"d": now.Format("02"),
+			"m": now.Format("01"),
+			"y": now.Format("2006"),
+			"h": now.Format("15"),
+			"i": now.Format("04"),
+			"s": now.Format("05"),
+			"v": "",
 		}
+	)
 
-		if ox.fileName != format {
-			ox.fileName = format
-			ox.filePath = filepath.Join(ox.Path, ox.fileName)
+	switch ox.Mode {
+	case ModeDaily:
+		formatBinds["v"] = now.Format("20060102")
 
-			if !utpath.IsExists(ox.Path) {
-				err = os.MkdirAll(ox.Path, os.ModePerm)
-				if err != nil {
-					return
-				}
-			}
+	case ModeMonthly:
+		formatBinds["v"] = now.Format("200601")
+
+	case ModeYearly:
+		formatBinds["v"] = now.Format("2006")
+
+	case ModePermanent:
+		formatBinds["v"] = ""
+	}
 
-			err = ox.open()
+	for k, v := range formatBinds {
+		format = strings.ReplaceAll(format, "%"+k, v)
+	}
+
+	ox.mu.Lock()
+	previousPath := ox.filePath
+	changed := ox.fileName != format || ox.fileStream == nil
+	if changed {
+		ox.fileName = format
+		ox.filePath = filepath.Join(ox.Path, ox.fileName)
+	}
+	ox.mu.Unlock()
+
+	if changed {
+		if !utpath.IsExists(ox.Path) {
+			err = os.MkdirAll(ox.Path, os.ModePerm)
 			if err != nil {
 				return
 			}
 		}
-	}
 
-	if !ox.isReady {
-		ox.isReady = true
+		err = ox.open()
+		if err != nil {
+			return
+		}
 
-		go func() {
-			for {
-				time.Sleep(3 * time.Second)
-				ox.flush()
-			}
-		}()
+		// time based rotation, the previous period file is now an archive
+		if previousPath != "" && previousPath != ox.filePath {
+			ox.archive(previousPath)
+		}
 	}
 
 	return
 }
 
+// run is the background writer, it alone writes to the file so the callers never wait on the disk
+func (ox *logWriterObj) run(interval time.Duration, queue <-chan string, flushReq <-chan chan error, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				return
+			}
+
+			ox.writeBatch(msg, queue)
+
+		case <-ticker.C:
+			if ox.IsWriting() {
+				if err := ox.switchPeriod(); err != nil {
+					ox.printf("Failed to open file %s, details: %+v", ox.filePath, err)
+				}
+			}
+
+			_ = ox.sync(ox.FsyncPolicy != FsyncNever)
+
+		case reply := <-flushReq:
+			// the messages queued before the request are written first
+			for n := len(queue); n > 0; n-- {
+				msg, ok := <-queue
+				if !ok {
+					break
+				}
+
+				ox.writeBatch(msg, nil)
+			}
+
+			reply <- ox.sync(ox.FsyncPolicy != FsyncNever)
+		}
+	}
+}
+
 func (ox *logWriterObj) open() (err error) {
 	if !ox.IsWriting() {
 		return nil
@@ -128,15 +343,54 @@ func (ox *logWriterObj) open() (err error) {
 	ox.mu.Lock()
 	defer ox.mu.Unlock()
 
-	if ox.fileStream != nil {
-		_ = ox.fileStream.Close()
-	}
+	return ox.openLocked()
+}
+
+func (ox *logWriterObj) openLocked() (err error) {
+	_ = ox.closeFileLocked(false)
 
 	ox.fileStream, err = os.OpenFile(ox.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return
 	}
 
+	bufferSize := ox.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultWriteBufferSize
+	}
+	ox.buffer = bufio.NewWriterSize(ox.fileStream, bufferSize)
+
+	ox.fileSize = 0
+	if info, errs := ox.fileStream.Stat(); errs == nil {
+		ox.fileSize = info.Size()
+	}
+
+	return
+}
+
+// closeFileLocked flushes the buffer then closes the active file, the caller must hold ox.mu
+func (ox *logWriterObj) closeFileLocked(fsync bool) (err error) {
+	if ox.fileStream == nil {
+		return nil
+	}
+
+	if ox.buffer != nil {
+		err = ox.buffer.Flush()
+	}
+
+	if fsync {
+		if errs := ox.fileStream.Sync(); errs != nil && err == nil {
+			err = errs
+		}
+		atomic.AddUint64(&ox.syncs, 1)
+	}
+
+	if errs := ox.fileStream.Close(); errs != nil && err == nil {
+		err = errs
+	}
+
+	ox.fileStream = nil
+	ox.buffer = nil
 	return
 }
 
@@ -145,20 +399,33 @@ func (ox *logWriterObj) write(msg string) (err error) {
 		return
 	}
 
-	if !ox.isReady {
-		err = errors.New("Logger not yet ready")
+	if msg == "" {
 		return
 	}
 
-	if msg == "" {
-		return
+	ox.queueMu.RLock()
+	defer ox.queueMu.RUnlock()
+
+	if ox.queue == nil {
+		if ox.done == nil {
+			return errors.New("Logger not yet ready")
+		}
+
+		atomic.AddUint64(&ox.dropped, 1)
+		return errWriterClosed
 	}
 
-	ox.mu.Lock()
-	defer ox.mu.Unlock()
+	if ox.WritePolicy == WritePolicyDrop {
+		select {
+		case ox.queue <- msg:
+		default:
+			atomic.AddUint64(&ox.dropped, 1)
+		}
 
-	ox.writeQueues = append(ox.writeQueues, msg)
+		return
+	}
 
+	ox.queue <- msg
 	return
 }
 
@@ -167,47 +434,331 @@ func (ox *logWriterObj) flush() (err error) {
 		return nil
 	}
 
+	// a closed writer stays closed, booting it again would restart the background writer
+	ox.queueMu.RLock()
+	closed := ox.queue == nil && ox.done != nil
+	ox.queueMu.RUnlock()
+
+	if closed {
+		return nil
+	}
+
 	err = ox.boot()
 	if err != nil {
 		return
 	}
 
+	ox.queueMu.RLock()
+	flushReq, done := ox.flushReq, ox.done
+	closed = ox.queue == nil
+	ox.queueMu.RUnlock()
+
+	if closed {
+		return errWriterClosed
+	}
+
+	reply := make(chan error, 1)
+	select {
+	case flushReq <- reply:
+		return <-reply
+
+	case <-done:
+		return errWriterClosed
+	}
+}
+
+// writeBatch writes msg and the messages already queued behind it, queue may be nil to write msg alone
+func (ox *logWriterObj) writeBatch(msg string, queue <-chan string) {
 	ox.mu.Lock()
-	defer ox.mu.Unlock()
+	missing := ox.fileStream == nil
+	ox.mu.Unlock()
 
-	lists := ox.writeQueues
-	ox.writeQueues = []string{}
+	if missing && ox.IsWriting() {
+		if err := ox.switchPeriod(); err != nil {
+			ox.printf("Failed to open file %s, details: %+v", ox.filePath, err)
+		}
+	}
 
-	defer func() {
-		if err != nil {
-			ox.writeQueues = append(lists, ox.writeQueues...)
+	ox.mu.Lock()
+	ox.writeLineLocked(msg)
+
+	if queue != nil {
+		for n := len(queue); n > 0; n-- {
+			next, ok := <-queue
+			if !ok {
+				break
+			}
+
+			ox.writeLineLocked(next)
 		}
-	}()
+	}
+	ox.mu.Unlock()
 
-	if len(lists) > 0 {
-		for _, v := range lists {
-			_, err = ox.fileStream.WriteString(fmt.Sprintf("%s\n", v))
-			if err != nil {
-				ox.printf("Failed to writing, details: %+v", err)
+	if ox.FsyncPolicy == FsyncAlways {
+		_ = ox.sync(true)
+	}
+}
 
-				errs := ox.open()
-				if errs != nil {
-					ox.printf("Failed to re-open file %s, details: %+v", ox.filePath, errs)
-				}
-				return err
+// writeLineLocked writes msg into the buffer, rotating the file first when it would outgrow MaxSize,
+// the caller must hold ox.mu
+func (ox *logWriterObj) writeLineLocked(msg string) {
+	if ox.buffer == nil {
+		atomic.AddUint64(&ox.dropped, 1)
+		return
+	}
+
+	size := int64(len(msg) + 1)
+
+	if ox.MaxSize > 0 && ox.fileSize > 0 && ox.fileSize+size > ox.MaxSize {
+		if err := ox.rotateLocked(); err != nil {
+			atomic.AddUint64(&ox.failures, 1)
+			ox.printf("Failed to rotate file %s, details: %+v", ox.filePath, err)
+
+			if ox.buffer == nil {
+				atomic.AddUint64(&ox.dropped, 1)
+				return
 			}
 		}
+	}
+
+	n, err := ox.buffer.WriteString(msg)
+	if err == nil {
+		err = ox.buffer.WriteByte('\n')
+		if err == nil {
+			n++
+		}
+	}
+
+	ox.fileSize += int64(n)
+	atomic.AddUint64(&ox.bytes, uint64(n))
+	if err != nil {
+		atomic.AddUint64(&ox.failures, 1)
+		atomic.AddUint64(&ox.dropped, 1)
+		ox.printf("Failed to writing, details: %+v", err)
+
+		// a failed bufio.Writer keeps failing, the file is opened again for the next messages
+		if errs := ox.openLocked(); errs != nil {
+			ox.printf("Failed to re-open file %s, details: %+v", ox.filePath, errs)
+		}
+		return
+	}
+
+	atomic.AddUint64(&ox.written, 1)
+}
+
+// sync writes the buffered messages to the file, then syncs it to the disk when fsync is set
+func (ox *logWriterObj) sync(fsync bool) (err error) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	if ox.buffer == nil {
+		return nil
+	}
+
+	err = ox.buffer.Flush()
+	if err != nil {
+		atomic.AddUint64(&ox.failures, 1)
+		ox.printf("Failed to flushing buffer, details: %+v", err)
+
+		if errs := ox.openLocked(); errs != nil {
+			ox.printf("Failed to re-open file %s, details: %+v", ox.filePath, errs)
+		}
+		return err
+	}
 
+	if fsync {
 		err = ox.fileStream.Sync()
+		atomic.AddUint64(&ox.syncs, 1)
 		if err != nil {
+			atomic.AddUint64(&ox.failures, 1)
 			ox.printf("Failed to flushing stream, details: %+v", err)
 			return err
 		}
 	}
 
+	return nil
+}
+
+// rotateLocked moves the active file aside and opens a fresh one, the caller must hold ox.mu
+func (ox *logWriterObj) rotateLocked() (err error) {
+	err = ox.closeFileLocked(true)
+	if err != nil {
+		return
+	}
+
+	var (
+		ext         = filepath.Ext(ox.filePath)
+		base        = fmt.Sprintf("%s-%s", strings.TrimSuffix(ox.filePath, ext), time.Now().Format(rotatedFileTimeFormat))
+		rotatedPath = base + ext
+	)
+
+	// rotations within the same millisecond must not overwrite an archive still being compressed
+	for i := 1; utpath.IsExists(rotatedPath) || utpath.IsExists(rotatedPath+compressedFileExt); i++ {
+		rotatedPath = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+
+	err = os.Rename(ox.filePath, rotatedPath)
+	if err != nil {
+		return
+	}
+
+	err = ox.openLocked()
+	if err != nil {
+		return
+	}
+
+	ox.archive(rotatedPath)
+	return
+}
+
+// archive compresses a rotated file when enabled then applies the retention policy, it runs in the background
+func (ox *logWriterObj) archive(path string) {
+	ox.compressing.Add(1)
+
+	go func() {
+		defer ox.compressing.Done()
+
+		if ox.Compress && !strings.HasSuffix(path, compressedFileExt) {
+			if err := compressFile(path); err != nil {
+				ox.printf("Failed to compress file %s, details: %+v", path, err)
+			}
+		}
+
+		if err := ox.prune(); err != nil {
+			ox.printf("Failed to prune log files, details: %+v", err)
+		}
+	}()
+}
+
+// prune removes rotated files older than MaxAge and the oldest ones beyond MaxBackups
+func (ox *logWriterObj) prune() error {
+	if ox.MaxAge <= 0 && ox.MaxBackups <= 0 {
+		return nil
+	}
+
+	files, err := ox.rotatedFiles()
+	if err != nil {
+		return err
+	}
+
+	var (
+		now    = time.Now()
+		remove []string
+		kept   int
+	)
+
+	for _, v := range files {
+		if (ox.MaxAge > 0 && now.Sub(v.modTime) > ox.MaxAge) || (ox.MaxBackups > 0 && kept >= ox.MaxBackups) {
+			remove = append(remove, v.path)
+			continue
+		}
+
+		kept++
+	}
+
+	for _, v := range remove {
+		if errs := os.Remove(v); errs != nil && !os.IsNotExist(errs) {
+			err = errs
+		}
+	}
+
 	return err
 }
 
+type rotatedFile struct {
+	path    string
+	modTime time.Time
+}
+
+// rotatedFiles lists every file produced by FileFormat except the active one, newest first
+func (ox *logWriterObj) rotatedFiles() (files []rotatedFile, err error) {
+	ox.mu.Lock()
+	activePath := ox.filePath
+	ox.mu.Unlock()
+
+	pattern := filepath.Join(ox.Path, fileFormatBind.ReplaceAllString(ox.FileFormat, "*"))
+	ext := filepath.Ext(pattern)
+	pattern = strings.TrimSuffix(pattern, ext) + "*" + ext + "*"
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return
+	}
+
+	for _, v := range matches {
+		if v == activePath || strings.HasSuffix(v, compressedFileExt+".tmp") {
+			continue
+		}
+
+		info, errs := os.Stat(v)
+		if errs != nil || info.IsDir() {
+			continue
+		}
+
+		files = append(files, rotatedFile{path: v, modTime: info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	return
+}
+
 func (ox *logWriterObj) printf(msg string, opts ...interface{}) {
 	fmt.Printf("[%s] ERR: %s\n", uttime.Format(uttime.DefaultDateTimeFormat, time.Now()), fmt.Sprintf(msg, opts...))
 }
+
+// compressFile gzips path into path.gz and removes the original
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return
+	}
+
+	tmpPath := path + compressedFileExt + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	gz.ModTime = info.ModTime()
+
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return
+	}
+
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return
+	}
+
+	if err = dst.Close(); err != nil {
+		return
+	}
+
+	if err = os.Chtimes(tmpPath, info.ModTime(), info.ModTime()); err != nil {
+		return
+	}
+
+	if err = os.Rename(tmpPath, path+compressedFileExt); err != nil {
+		return
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogWriter_SizeRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	writer := &logWriterObj{
+		isWriting:     true,
+		Path:          dir,
+		FileFormat:    "app-%v.log",
+		Mode:          ModePermanent,
+		MaxSize:       64,
+		Compress:      true,
+		FlushInterval: time.Hour,
+	}
+	assert.Nil(t, writer.boot())
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, writer.write(strings.Repeat("x", 40)))
+		assert.Nil(t, writer.Flush())
+	}
+	assert.Nil(t, writer.Close())
+
+	active, err := os.ReadFile(filepath.Join(dir, "app-.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat("x", 40)+"\n", string(active))
+
+	archives, _ := filepath.Glob(filepath.Join(dir, "app--*.log.gz"))
+	assert.Len(t, archives, 3)
+
+	for _, v := range archives {
+		f, err := os.Open(v)
+		assert.Nil(t, err)
+
+		gz, err := gzip.NewReader(f)
+		assert.Nil(t, err)
+
+		content, err := io.ReadAll(gz)
+		assert.Nil(t, err)
+		assert.Equal(t, strings.Repeat("x", 40)+"\n", string(content))
+		_ = f.Close()
+	}
+}
+
+func TestLogWriter_Retention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	for i, age := range []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 48 * time.Hour} {
+		path := filepath.Join(dir, "app-2024010"+string(rune('1'+i))+".log.gz")
+		assert.Nil(t, os.WriteFile(path, []byte("old"), 0644))
+		assert.Nil(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+
+	unrelated := filepath.Join(dir, "other.txt")
+	assert.Nil(t, os.WriteFile(unrelated, []byte("keep"), 0644))
+
+	writer := &logWriterObj{
+		Path:       dir,
+		FileFormat: "app-%v.log",
+		Mode:       ModeDaily,
+		MaxAge:     24 * time.Hour,
+		MaxBackups: 2,
+	}
+	assert.Nil(t, writer.prune())
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "app-20240101.log.gz"),
+		filepath.Join(dir, "app-20240102.log.gz"),
+		unrelated,
+	}, files)
+}
+
+func TestLogWriter_BackgroundFlusher(t *testing.T) {
+	dir := t.TempDir()
+
+	writer := &logWriterObj{
+		isWriting:     true,
+		Path:          dir,
+		FileFormat:    "app.log",
+		Mode:          ModePermanent,
+		FlushInterval: 10 * time.Millisecond,
+	}
+	assert.Nil(t, writer.boot())
+	assert.Nil(t, writer.write("hello"))
+
+	assert.Eventually(t, func() bool {
+		content, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+		return string(content) == "hello\n"
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, writer.Close())
+}
+
+func TestLogWriter_DropPolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	writer := &logWriterObj{
+		isWriting:     true,
+		Path:          dir,
+		FileFormat:    "app.log",
+		Mode:          ModePermanent,
+		FlushInterval: time.Hour,
+		QueueSize:     1,
+		WritePolicy:   WritePolicyDrop,
+	}
+	assert.Nil(t, writer.boot())
+
+	// holding the file lock stalls the background writer once it took the first message
+	writer.mu.Lock()
+	assert.Nil(t, writer.write("first"))
+	assert.Eventually(t, func() bool {
+		return writer.WriterStats().Queued == 0
+	}, time.Second, time.Millisecond)
+
+	assert.Nil(t, writer.write("second"))
+	assert.Nil(t, writer.write("third"))
+	writer.mu.Unlock()
+
+	assert.Nil(t, writer.Flush())
+	assert.Equal(t, WriterStats{Capacity: 1, Written: 2, Bytes: 13, Dropped: 1, Syncs: 1}, writer.WriterStats())
+
+	assert.Nil(t, writer.Close())
+	assert.Equal(t, errWriterClosed, writer.write("closed"))
+	assert.Nil(t, writer.Flush())
+	assert.Equal(t, errWriterClosed, writer.write("flushed"))
+
+	content, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, "first\nsecond\n", string(content))
+}
+
+func TestLogWriter_FsyncPolicy(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy FsyncPolicy
+		syncs  uint64
+	}{
+		{name: "on flush", policy: FsyncOnFlush, syncs: 1},
+		{name: "never", policy: FsyncNever, syncs: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := &logWriterObj{
+				isWriting:     true,
+				Path:          t.TempDir(),
+				FileFormat:    "app.log",
+				Mode:          ModePermanent,
+				FlushInterval: time.Hour,
+				FsyncPolicy:   tc.policy,
+			}
+			assert.Nil(t, writer.boot())
+
+			for i := 0; i < 10; i++ {
+				assert.Nil(t, writer.write("hello"))
+			}
+			assert.Nil(t, writer.Flush())
+
+			stats := writer.WriterStats()
+			assert.Equal(t, uint64(10), stats.Written)
+			assert.Equal(t, tc.syncs, stats.Syncs)
+			assert.Nil(t, writer.Close())
+		})
+	}
+
+	writer := &logWriterObj{
+		isWriting:     true,
+		Path:          t.TempDir(),
+		FileFormat:    "app.log",
+		Mode:          ModePermanent,
+		FlushInterval: time.Hour,
+		FsyncPolicy:   FsyncAlways,
+	}
+	assert.Nil(t, writer.boot())
+	assert.Nil(t, writer.write("hello"))
+
+	assert.Eventually(t, func() bool {
+		return writer.WriterStats().Syncs >= 1
+	}, time.Second, time.Millisecond)
+	assert.Nil(t, writer.Close())
+}
+
+func benchmarkLogWriter(b *testing.B, policy WritePolicy, fsync FsyncPolicy) {
+	writer := &logWriterObj{
+		isWriting:     true,
+		Path:          b.TempDir(),
+		FileFormat:    "bench.log",
+		Mode:          ModePermanent,
+		FlushInterval: 100 * time.Millisecond,
+		WritePolicy:   policy,
+		FsyncPolicy:   fsync,
+	}
+	if err := writer.boot(); err != nil {
+		b.Fatal(err)
+	}
+
+	msg := "[2024-03-05 08:30:15] INFO: {[usecase] requestID:0123456789abcdef;} user registered"
+
+	b.SetBytes(int64(len(msg) + 1))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = writer.write(msg)
+		}
+	})
+
+	if err := writer.Close(); err != nil {
+		b.Fatal(err)
+	}
+	b.StopTimer()
+
+	stats := writer.WriterStats()
+	b.ReportMetric(float64(stats.Dropped)/float64(b.N), "dropped/op")
+}
+
+func BenchmarkLogWriter_Block(b *testing.B) {
+	benchmarkLogWriter(b, WritePolicyBlock, FsyncOnFlush)
+}
+
+func BenchmarkLogWriter_Drop(b *testing.B) {
+	benchmarkLogWriter(b, WritePolicyDrop, FsyncOnFlush)
+}
+
+func BenchmarkLogWriter_FsyncAlways(b *testing.B) {
+	benchmarkLogWriter(b, WritePolicyBlock, FsyncAlways)
+}
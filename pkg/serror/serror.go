@@ -3,6 +3,7 @@ package serror
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"path"
 	"runtime"
 	"strings"
@@ -42,6 +43,8 @@ type (
 		AddCommentsx(skip int, msg ...string)
 		AddCommentfx(skip int, msg string, opts ...interface{})
 		Sign(ctx HCtx)
+		Signature() Signature
+		SetSignature(sig Signature)
 
 		// Deprecated: Use AddComments instead.
 		SetComments(note string)
@@ -61,8 +64,22 @@ type (
 		CreateErrorEx(err error, notes ...string) (errx SError)
 		SignError(errx SError) SError
 	}
+
+	// Signature identifies the request and layer an error was created in
+	Signature struct {
+		RequestID string `json:"request_id,omitempty"`
+		UserID    string `json:"user_id,omitempty"`
+		Route     string `json:"route,omitempty"`
+		Method    string `json:"method,omitempty"`
+		Layer     string `json:"layer,omitempty"`
+	}
 )
 
+// IsZero reports whether the signature is empty
+func (sig Signature) IsZero() bool {
+	return sig == Signature{}
+}
+
 var (
 	rootPaths []string
 )
@@ -169,7 +186,7 @@ func Newik(code int, key string, message string) SError {
 	stack := make([]uintptr, 50)
 	length := runtime.Callers(2, stack[:])
 
-	return construct(stack[:length], code, "-", errors.New(message), 0, "@")
+	return construct(stack[:length], code, key, errors.New(message), 0, "@")
 }
 
 // Newikf serror from error code, error key, and error message with function
@@ -380,6 +397,41 @@ func NewFromErrorsc(skip int, err error, note string) SError {
 	return construct(stack[:length], 0, "-", err, skip, note)
 }
 
+// NewFromPanic serror from a recovered panic value, the stack starts at the function which panicked
+func NewFromPanic(rec interface{}) SError {
+	stack := make([]uintptr, 50)
+	length := runtime.Callers(2, stack[:])
+	stack = stack[:length]
+
+	// drop the recover frames and the runtime panic machinery, such as sigpanic for nil dereferences
+	for i, pc := range stack {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			stack = stack[i+1:]
+			for len(stack) > 1 {
+				if fn = runtime.FuncForPC(stack[0] - 1); fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+					break
+				}
+				stack = stack[1:]
+			}
+			break
+		}
+	}
+
+	var err error
+	switch vx := rec.(type) {
+	case SError:
+		return vx
+
+	case error:
+		err = vx
+
+	default:
+		err = fmt.Errorf("%v", rec)
+	}
+
+	return construct(stack, http.StatusInternalServerError, "-", err, 0, "@")
+}
+
 // NewFromSErr serror from serr
 func NewFromSErr(err serr.SErr) SError {
 	stack := make([]uintptr, 50)
@@ -427,12 +479,17 @@ func StandardColorFormat() string {
 	return frmt
 }
 
-// IsEqual to check are error same or not
+// IsEqual to check are error same or not, either error matching the other through errors.Is is enough,
+// otherwise the causes are compared by message
 func IsEqual(a error, b error) bool {
 	if a == nil || b == nil {
 		return (a == b)
 	}
 
+	if errors.Is(a, b) || errors.Is(b, a) {
+		return true
+	}
+
 	if errx, ok := a.(SError); ok {
 		a = errx.Cause()
 	}
@@ -443,3 +500,48 @@ func IsEqual(a error, b error) bool {
 
 	return (a.Error() == b.Error())
 }
+
+// Join aggregates errs into a single SError, nil errors are skipped and nil is returned when none is left,
+// the result keeps the highest code, the first key and the first signature, and errors.Is and errors.As match any of errs
+func Join(errs ...error) SError {
+	var (
+		joined []error
+		code   int
+		key    = "-"
+		sig    Signature
+	)
+
+	for _, v := range errs {
+		if v == nil {
+			continue
+		}
+
+		joined = append(joined, v)
+
+		var errx SError
+		if errors.As(v, &errx) {
+			if errx.Code() > code {
+				code = errx.Code()
+			}
+
+			if k := errx.Key(); key == "-" && k != "" && k != "-" {
+				key = k
+			}
+
+			if sig.IsZero() {
+				sig = errx.Signature()
+			}
+		}
+	}
+
+	if len(joined) == 0 {
+		return nil
+	}
+
+	stack := make([]uintptr, 50)
+	length := runtime.Callers(2, stack[:])
+
+	res := construct(stack[:length], code, key, errors.Join(joined...), 0, "")
+	res.SetSignature(sig)
+	return res
+}
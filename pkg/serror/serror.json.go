@@ -0,0 +1,150 @@
+package serror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	gerr "github.com/go-errors/errors"
+)
+
+// JSONMaxFrames is how many stack frames are kept when an error is serialized
+var JSONMaxFrames = 20
+
+type (
+	// Payload is the JSON form of an SError, it is what services exchange and what the JSON logs carry
+	Payload struct {
+		Code      int        `json:"code,omitempty"`
+		Key       string     `json:"key,omitempty"`
+		Message   string     `json:"message"`
+		Type      string     `json:"type,omitempty"`
+		Comments  []string   `json:"comments,omitempty"`
+		Frames    []Frame    `json:"frames,omitempty"`
+		Signature *Signature `json:"signature,omitempty"`
+	}
+
+	// Frame is a serialized stack frame, File is relative to the registered root paths
+	Frame struct {
+		Function string `json:"function"`
+		Package  string `json:"package,omitempty"`
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+	}
+)
+
+// NewPayload returns the JSON form of errx, runtime frames are dropped and at most JSONMaxFrames are kept
+func NewPayload(errx SError) Payload {
+	res := Payload{
+		Code:     errx.Code(),
+		Key:      errx.Key(),
+		Message:  errx.Error(),
+		Comments: errx.CommentStack(),
+	}
+
+	if res.Key == "-" {
+		res.Key = ""
+	}
+
+	if errx.Cause() != nil {
+		res.Type = errx.Type()
+	}
+
+	if sig := errx.Signature(); !sig.IsZero() {
+		res.Signature = &sig
+	}
+
+	for _, v := range errx.StackFrames() {
+		if JSONMaxFrames > 0 && len(res.Frames) >= JSONMaxFrames {
+			break
+		}
+
+		if v.Package == "runtime" || v.Name == "" {
+			continue
+		}
+
+		res.Frames = append(res.Frames, Frame{
+			Function: v.Name,
+			Package:  v.Package,
+			File:     getPath(v.File),
+			Line:     v.LineNumber,
+		})
+	}
+
+	return res
+}
+
+// NewFromPayload rebuilds an SError from its JSON form, the frames are the ones of the service which created it
+func NewFromPayload(payload Payload) SError {
+	res := &serrorObj{
+		err:      errors.New(payload.Message),
+		key:      payload.Key,
+		code:     payload.Code,
+		comments: payload.Comments,
+		frames:   []gerr.StackFrame{},
+	}
+
+	if res.key == "" {
+		res.key = "-"
+	}
+
+	if payload.Signature != nil {
+		res.signature = *payload.Signature
+	}
+
+	for _, v := range payload.Frames {
+		res.frames = append(res.frames, gerr.StackFrame{
+			Name:       v.Function,
+			Package:    v.Package,
+			File:       v.File,
+			LineNumber: v.Line,
+		})
+	}
+
+	return res
+}
+
+// NewFromJSON rebuilds an SError from the JSON payload of an upstream service,
+// a body which is not an SError payload gives a 502 carrying the raw body as message
+func NewFromJSON(data []byte) SError {
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Message == "" {
+		msg := strings.TrimSpace(string(data))
+		if msg == "" {
+			msg = http.StatusText(http.StatusBadGateway)
+		}
+
+		res := Newsi(1, http.StatusBadGateway, msg)
+		if err != nil {
+			res.AddCommentsx(1, fmt.Sprintf("Failed to decode upstream error, details: %v", err))
+		}
+
+		return res
+	}
+
+	return NewFromPayload(payload)
+}
+
+// MarshalJSON function
+func (ox *serrorObj) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewPayload(ox))
+}
+
+// UnmarshalJSON function
+func (ox *serrorObj) UnmarshalJSON(data []byte) error {
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	*ox = *(NewFromPayload(payload).(*serrorObj))
+	return nil
+}
+
+// private
+
+// isRemote reports whether frames were received from another service, they have no program counter
+func isRemote(frames []gerr.StackFrame) bool {
+	return len(frames) > 0 && frames[0].ProgramCounter == 0
+}
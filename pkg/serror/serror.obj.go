@@ -1,6 +1,7 @@
 package serror
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -19,6 +20,8 @@ type (
 		comments []string
 		code     int
 
+		signature Signature
+
 		frames []gerr.StackFrame
 		stack  []uintptr
 	}
@@ -34,14 +37,20 @@ func construct(stack []uintptr, code int, key string, err error, skip int, note
 		if errx, ok := err.(SError); ok {
 			isSErr = true
 			res = &serrorObj{
-				err:      errx.Cause(),
-				key:      errx.Key(),
-				code:     errx.Code(),
-				comments: errx.CommentStack(),
+				err:       errx.Cause(),
+				key:       errx.Key(),
+				code:      errx.Code(),
+				comments:  errx.CommentStack(),
+				signature: errx.Signature(),
 			}
 
-			for _, v := range errx.StackFrames() {
-				res.stack = append(res.stack, v.ProgramCounter)
+			// frames received from another service have no program counter, they are kept as they are
+			if frames := errx.StackFrames(); isRemote(frames) {
+				res.frames = frames
+			} else {
+				for _, v := range frames {
+					res.stack = append(res.stack, v.ProgramCounter)
+				}
 			}
 
 			res.key = utstring.Chains(key, res.key)
@@ -234,6 +243,16 @@ func (ox *serrorObj) Sign(ctx HCtx) {
 	_ = ctx.SignError(ox)
 }
 
+// Signature function
+func (ox serrorObj) Signature() Signature {
+	return ox.signature
+}
+
+// SetSignature function
+func (ox *serrorObj) SetSignature(sig Signature) {
+	ox.signature = sig
+}
+
 // AddCommentfx function
 func (ox *serrorObj) AddCommentfx(skip int, msg string, opts ...interface{}) {
 	ox.setRawComment(fmt.Sprintf(msg, opts...), 1+skip)
@@ -285,6 +304,59 @@ func (ox serrorObj) IsEqual(err error) bool {
 	return IsEqual(ox.Cause(), err)
 }
 
+// Unwrap returns the cause, so errors.Is and errors.As see through SError
+func (ox *serrorObj) Unwrap() error {
+	return ox.err
+}
+
+// Is reports whether target is an SError sharing the same key or the same cause
+func (ox *serrorObj) Is(target error) bool {
+	cur, ok := target.(SError)
+	if !ok {
+		return false
+	}
+
+	if ox.key != "" && ox.key != "-" && ox.key == cur.Key() {
+		return true
+	}
+
+	return cur.Cause() != nil && errors.Is(ox.err, cur.Cause())
+}
+
+// As assigns the error to a *SError target
+func (ox *serrorObj) As(target interface{}) bool {
+	if cur, ok := target.(*SError); ok {
+		*cur = ox
+		return true
+	}
+
+	return false
+}
+
+// Format prints the message with %v and %s, and the location, comments and stack frames with %+v
+func (ox *serrorObj) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			fmt.Fprint(f, ox.String())
+			for _, v := range ox.StackFrames() {
+				fmt.Fprintf(f, "\n%s.%s\n\t%s:%d", v.Package, v.Name, v.File, v.LineNumber)
+			}
+			return
+		}
+
+		fmt.Fprint(f, ox.Error())
+
+	case 's':
+		fmt.Fprint(f, ox.Error())
+
+	case 'q':
+		fmt.Fprintf(f, "%q", ox.Error())
+	default:
+		fmt.Fprint(f, fmt.Sprintf("%"+string(verb), ox.Error()))
+	}
+}
+
 // private
 
 func (ox serrorObj) fParams() []interface{} {
@@ -0,0 +1,225 @@
+package serror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errNotFound = errors.New("record not found")
+
+func TestSError_Unwrap(t *testing.T) {
+	_, statErr := os.Stat("/definitely/missing/path")
+
+	testCases := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{
+			name:   "serror around a sentinel",
+			err:    NewFromError(errNotFound),
+			target: errNotFound,
+		},
+		{
+			name:   "stdlib wrap around serror around a sentinel",
+			err:    fmt.Errorf("while loading user: %w", NewFromErrori(http.StatusNotFound, errNotFound)),
+			target: errNotFound,
+		},
+		{
+			name:   "serror around a stdlib wrap around a sentinel",
+			err:    NewFromErrorc(fmt.Errorf("repository: %w", errNotFound), "while loading user"),
+			target: errNotFound,
+		},
+		{
+			name:   "serror rebuilt from another serror",
+			err:    NewFromError(NewFromError(fmt.Errorf("repository: %w", errNotFound))),
+			target: errNotFound,
+		},
+		{
+			name:   "serror around a path error",
+			err:    fmt.Errorf("boot: %w", NewFromError(statErr)),
+			target: fs.ErrNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, errors.Is(tc.err, tc.target))
+			assert.False(t, errors.Is(tc.err, errors.New(tc.target.Error())))
+
+			var errx SError
+			assert.True(t, errors.As(tc.err, &errx))
+		})
+	}
+
+	var pathErr *fs.PathError
+	assert.True(t, errors.As(fmt.Errorf("boot: %w", NewFromError(statErr)), &pathErr))
+	assert.Equal(t, "/definitely/missing/path", pathErr.Path)
+}
+
+func TestSError_Is(t *testing.T) {
+	errx := fmt.Errorf("handler: %w", Newik(http.StatusConflict, "email_taken", "email already registered"))
+
+	assert.True(t, errors.Is(errx, Newk("email_taken", "another message")))
+	assert.False(t, errors.Is(errx, Newk("phone_taken", "email already registered")))
+	assert.False(t, errors.Is(errx, New("email already registered")))
+
+	sentinel := NewFromError(errNotFound)
+	assert.True(t, errors.Is(NewFromErrorc(errNotFound, "while loading"), sentinel))
+
+	assert.True(t, IsEqual(errx, Newk("email_taken", "x")))
+	assert.True(t, IsEqual(New("same message"), errors.New("same message")))
+	assert.False(t, IsEqual(New("a"), nil))
+}
+
+func TestSError_Format(t *testing.T) {
+	errx := Newk("email_taken", "email already registered")
+	errx.AddComments("while registering")
+
+	assert.Equal(t, "email already registered", fmt.Sprintf("%v", errx))
+	assert.Equal(t, "email already registered", fmt.Sprintf("%s", errx))
+	assert.Equal(t, `"email already registered"`, fmt.Sprintf("%q", errx))
+	assert.Equal(t, fmt.Sprintf("%x", "email already registered"), fmt.Sprintf("%x", errx))
+	assert.Equal(t, "%!d(string=email already registered)", fmt.Sprintf("%d", errx))
+
+	detailed := fmt.Sprintf("%+v", errx)
+	assert.True(t, strings.HasPrefix(detailed, errx.String()))
+	assert.Contains(t, detailed, "while registering")
+	assert.Contains(t, detailed, "TestSError_Format")
+	assert.Greater(t, strings.Count(detailed, "\n"), 1)
+}
+
+func TestJoin(t *testing.T) {
+	assert.Nil(t, Join())
+	assert.Nil(t, Join(nil, nil))
+
+	errConflict := Newik(http.StatusConflict, "email_taken", "email already registered")
+	joined := Join(
+		nil,
+		fmt.Errorf("notify: %w", errNotFound),
+		errConflict,
+		Newik(http.StatusServiceUnavailable, "smtp_down", "smtp unavailable"),
+	)
+
+	assert.Equal(t, http.StatusServiceUnavailable, joined.Code())
+	assert.Equal(t, "email_taken", joined.Key())
+	assert.True(t, errors.Is(joined, errNotFound))
+	assert.True(t, errors.Is(joined, Newk("smtp_down", "")))
+	assert.Equal(t, "notify: record not found\nemail already registered\nsmtp unavailable", joined.Error())
+
+	var errx SError
+	assert.True(t, errors.As(fmt.Errorf("outer: %w", joined), &errx))
+	assert.Equal(t, joined, errx)
+
+	nested := Join(joined, errors.New("cleanup failed"))
+	assert.True(t, errors.Is(nested, errConflict))
+	assert.Equal(t, http.StatusServiceUnavailable, nested.Code())
+}
+
+func TestSError_Signature(t *testing.T) {
+	sig := Signature{RequestID: "req-1", UserID: "7", Route: "/v1/users/:id", Method: http.MethodGet, Layer: "usecase"}
+
+	errx := Newi(http.StatusNotFound, "user not found")
+	assert.True(t, errx.Signature().IsZero())
+
+	errx.SetSignature(sig)
+	assert.Equal(t, sig, errx.Signature())
+
+	wrapped := NewFromErrorc(errx, "while loading profile")
+	assert.Equal(t, sig, wrapped.Signature())
+
+	joined := Join(New("unsigned"), errx, NewFromError(errNotFound))
+	assert.Equal(t, sig, joined.Signature())
+}
+
+func TestSError_JSON(t *testing.T) {
+	errx := Newik(http.StatusConflict, "user.email_taken", "email already registered")
+	errx.AddComments("[usecase][Register] while checking email")
+	errx.SetSignature(Signature{RequestID: "req-1", Layer: "usecase"})
+
+	byt, err := json.Marshal(errx)
+	assert.Nil(t, err)
+
+	var payload Payload
+	assert.Nil(t, json.Unmarshal(byt, &payload))
+	assert.Equal(t, http.StatusConflict, payload.Code)
+	assert.Equal(t, "user.email_taken", payload.Key)
+	assert.Equal(t, "email already registered", payload.Message)
+	assert.Equal(t, errx.CommentStack(), payload.Comments)
+	assert.Equal(t, "TestSError_JSON", payload.Frames[0].Function)
+	for _, v := range payload.Frames {
+		assert.NotEqual(t, "runtime", v.Package)
+	}
+
+	remote := NewFromJSON(byt)
+	assert.Equal(t, errx.Code(), remote.Code())
+	assert.Equal(t, errx.Key(), remote.Key())
+	assert.Equal(t, errx.Error(), remote.Error())
+	assert.Equal(t, errx.CommentStack(), remote.CommentStack())
+	assert.Equal(t, errx.Signature(), remote.Signature())
+	assert.Equal(t, "TestSError_JSON", remote.FN())
+	assert.Equal(t, payload.Frames[0].Line, remote.Line())
+	assert.True(t, errors.Is(remote, errx))
+
+	// wrapping keeps the upstream frames and adds the local comment
+	wrapped := NewFromErrorc(remote, "while calling user service")
+	assert.Equal(t, "TestSError_JSON", wrapped.FN())
+	assert.Len(t, wrapped.CommentStack(), 3)
+
+	rebyt, err := json.Marshal(wrapped)
+	assert.Nil(t, err)
+	assert.Contains(t, string(rebyt), `"function":"TestSError_JSON"`)
+
+	var decoded SError = &serrorObj{}
+	assert.Nil(t, json.Unmarshal(byt, decoded))
+	assert.Equal(t, "user.email_taken", decoded.Key())
+
+	embedded, err := json.Marshal(struct {
+		Payload interface{} `json:"payload"`
+	}{Payload: errx})
+	assert.Nil(t, err)
+	assert.Contains(t, string(embedded), `"message":"email already registered"`)
+}
+
+func TestSError_JSONFrames(t *testing.T) {
+	previous := JSONMaxFrames
+	defer func() { JSONMaxFrames = previous }()
+
+	JSONMaxFrames = 1
+	byt, err := json.Marshal(New("boom"))
+	assert.Nil(t, err)
+
+	var payload Payload
+	assert.Nil(t, json.Unmarshal(byt, &payload))
+	assert.Len(t, payload.Frames, 1)
+	assert.Empty(t, payload.Key)
+}
+
+func TestNewFromJSON_InvalidPayload(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		expectedMessage string
+	}{
+		{name: "html", body: "<html>bad gateway</html>", expectedMessage: "<html>bad gateway</html>"},
+		{name: "unrelated json", body: `{"status":"down"}`, expectedMessage: `{"status":"down"}`},
+		{name: "empty", body: "", expectedMessage: "Bad Gateway"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errx := NewFromJSON([]byte(tc.body))
+			assert.Equal(t, http.StatusBadGateway, errx.Code())
+			assert.Equal(t, tc.expectedMessage, errx.Error())
+			assert.Equal(t, "TestNewFromJSON_InvalidPayload.func1", errx.FN())
+		})
+	}
+}
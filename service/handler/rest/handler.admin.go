@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetUsers(ctx *gin.Context) {
+	page, size := helper.ParsePaginationParams(ctx)
+
+	users, pagination, errx := h.adminUsecase.GetUsers(ctx.Request.Context(), &models.UserFilter{
+		Search: ctx.Query("search"),
+		Page:   page,
+		Size:   size,
+	})
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Data: users,
+		Meta: pagination,
+	})
+}
+
+func (h *Handler) SuspendUser(ctx *gin.Context) {
+	userID, errx := parseUserIDParam(ctx, "SuspendUser")
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	errx = h.adminUsecase.SuspendUser(ctx.Request.Context(), userID)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "User has been successfully suspended",
+	})
+}
+
+func (h *Handler) UnsuspendUser(ctx *gin.Context) {
+	userID, errx := parseUserIDParam(ctx, "UnsuspendUser")
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	errx = h.adminUsecase.UnsuspendUser(ctx.Request.Context(), userID)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "User has been successfully unsuspended",
+	})
+}
+
+func (h *Handler) ForcePasswordReset(ctx *gin.Context) {
+	userID, errx := parseUserIDParam(ctx, "ForcePasswordReset")
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	errx = h.adminUsecase.ForcePasswordReset(ctx.Request.Context(), userID)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "User is required to reset their password",
+	})
+}
+
+func (h *Handler) RevokeSessions(ctx *gin.Context) {
+	userID, errx := parseUserIDParam(ctx, "RevokeSessions")
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	errx = h.adminUsecase.RevokeSessions(ctx.Request.Context(), userID)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "User sessions have been successfully revoked",
+	})
+}
+
+func (h *Handler) ChangeRole(ctx *gin.Context) {
+	userID, errx := parseUserIDParam(ctx, "ChangeRole")
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	var request models.ChangeRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		errx = serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddComments("[handler][ChangeRole] while BodyJSONBind")
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	validate := helper.NewValidator()
+	err := validate.Struct(request)
+	if err != nil {
+		validationMessages := helper.BuildAndGetValidationMessage(err)
+		handleValidationError(ctx, validationMessages)
+
+		return
+	}
+
+	errx = h.adminUsecase.ChangeRole(ctx.Request.Context(), userID, &request)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "User role has been successfully changed",
+	})
+}
+
+func parseUserIDParam(ctx *gin.Context, fn string) (userID int64, errx serror.SError) {
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		errx = serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddCommentf("[handler][%s] while parsing user ID", fn)
+		return
+	}
+
+	return
+}
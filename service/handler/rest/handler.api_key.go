@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreateAPIKey(ctx *gin.Context) {
+	var (
+		request models.CreateAPIKeyRequest
+		errx    serror.SError
+	)
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		errx = serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddComments("[handler][CreateAPIKey] while BodyJSONBind")
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	validate := helper.NewValidator()
+	err := validate.Struct(request)
+	if err != nil {
+		validationMessages := helper.BuildAndGetValidationMessage(err)
+		handleValidationError(ctx, validationMessages)
+
+		return
+	}
+
+	res, errx := h.apiKeyUsecase.CreateAPIKey(ctx.Request.Context(), &request)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.ResponseSuccess{
+		Message: "API key has been successfully created, store it now since it will not be shown again",
+		Data:    res,
+	})
+}
+
+func (h *Handler) GetAPIKeys(ctx *gin.Context) {
+	ownerUserID, _ := strconv.ParseInt(ctx.Query("owner_user_id"), 10, 64)
+	includeRevoked, _ := strconv.ParseBool(ctx.Query("include_revoked"))
+	page, size := helper.ParsePaginationParams(ctx)
+
+	apiKeys, pagination, errx := h.apiKeyUsecase.GetAPIKeys(ctx.Request.Context(), &models.APIKeyFilter{
+		OwnerUserID:    ownerUserID,
+		IncludeRevoked: includeRevoked,
+		Page:           page,
+		Size:           size,
+	})
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Data: apiKeys,
+		Meta: pagination,
+	})
+}
+
+func (h *Handler) RevokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		errx := serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddComments("[handler][RevokeAPIKey] while parsing API key ID")
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	errx := h.apiKeyUsecase.RevokeAPIKey(ctx.Request.Context(), id)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "API key has been successfully revoked",
+	})
+}
@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetAuditEvents(ctx *gin.Context) {
+	var errx serror.SError
+
+	startDate, endDate, err := helper.ParseDateRange(ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		errx = serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddComments("[handler][GetAuditEvents] while ParseDateRange")
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	actorID, _ := strconv.ParseInt(ctx.Query("actor_id"), 10, 64)
+	targetID, _ := strconv.ParseInt(ctx.Query("target_id"), 10, 64)
+	page, size := helper.ParsePaginationParams(ctx)
+
+	events, pagination, errx := h.auditUsecase.GetAuditEvents(ctx.Request.Context(), &models.AuditEventFilter{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Action:    ctx.Query("action"),
+		ActorID:   actorID,
+		TargetID:  targetID,
+		Page:      page,
+		Size:      size,
+	})
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Data: events,
+		Meta: pagination,
+	})
+}
@@ -5,9 +5,9 @@ import (
 	"net/http"
 	"strings"
 
-	"codepair-sinarmas/models"
-	"codepair-sinarmas/pkg/logger"
 	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+	middlewares "codepair-sinarmas/service/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -15,27 +15,18 @@ import (
 )
 
 func handleError(ctx *gin.Context, statusCode int, errx serror.SError) (result gin.H) {
+	errx = helper.ErrorContextFromContext(ctx.Request.Context(), helper.LogLayerHandler).SignError(errx)
+
 	if statusCode == 0 || statusCode == http.StatusInternalServerError {
-		logger.Err(errx)
-		ctx.JSON(errx.Code(), models.ResponseError{
-			Message: "Internal server error",
-			Error:   errx.Error(),
-		})
-		return
+		helper.LoggerFromContext(ctx.Request.Context(), helper.LogLayerHandler).Err(errx)
 	}
 
-	ctx.JSON(errx.Code(), models.ResponseError{
-		Message: errx.Error(),
-	})
+	middlewares.AbortWithError(ctx, errx, nil)
 	return
 }
 
 func handleValidationError(ctx *gin.Context, validationErrors interface{}) (result gin.H) {
-	ctx.JSON(http.StatusUnprocessableEntity, models.ResponseError{
-		Message: "Validation error",
-		Error:   validationErrors,
-	})
-
+	middlewares.AbortWithError(ctx, helper.ERR_VALIDATION.New(), validationErrors)
 	return
 }
 
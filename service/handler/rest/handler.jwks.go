@@ -0,0 +1,14 @@
+package rest
+
+import (
+	"net/http"
+
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, helper.GetTokenKeySet().JWKS())
+}
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"net/http"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/logger"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetLogLevels(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Data: logLevels(),
+	})
+}
+
+func (h *Handler) SetLogLevel(ctx *gin.Context) {
+	var (
+		request models.LogLevelRequest
+		errx    serror.SError
+	)
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		errx = serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddComments("[handler][SetLogLevel] while BodyJSONBind")
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	validate := helper.NewValidator()
+	err := validate.Struct(request)
+	if err != nil {
+		validationMessages := helper.BuildAndGetValidationMessage(err)
+		handleValidationError(ctx, validationMessages)
+
+		return
+	}
+
+	var (
+		log   = logger.Default()
+		lvl   = logger.ErrorLevel(request.Level)
+		field = "global"
+		from  logger.ErrorLevel
+	)
+
+	switch {
+	case request.Interceptor:
+		field = "interceptor"
+		from = log.InterceptorLevel()
+		log.SetInterceptorLevel(lvl)
+
+	case request.LayerName != "":
+		field = "layers." + request.LayerName
+		from = log.LayerLevels()[request.LayerName]
+		log.SetLayerLevel(request.LayerName, lvl)
+
+	default:
+		from = log.Level()
+		log.SetLevel(lvl)
+	}
+
+	h.recordLogLevelChange(ctx, field, from, lvl)
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "Log level has been successfully changed",
+		Data:    logLevels(),
+	})
+}
+
+func (h *Handler) ClearLogLayerLevel(ctx *gin.Context) {
+	var (
+		log   = logger.Default()
+		layer = ctx.Param("layer")
+	)
+
+	from, ok := log.LayerLevels()[layer]
+	if !ok {
+		errx := helper.ERR_LOG_LAYER_NOT_FOUND.New()
+		errx.AddCommentf("[handler][ClearLogLayerLevel] while checking layer override, [layer: %s]", layer)
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	log.ClearLayerLevel(layer)
+	h.recordLogLevelChange(ctx, "layers."+layer, from, "")
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "Log level override has been successfully removed",
+		Data:    logLevels(),
+	})
+}
+
+func (h *Handler) recordLogLevelChange(ctx *gin.Context, field string, from, to logger.ErrorLevel) {
+	h.auditUsecase.Record(ctx.Request.Context(), &models.AuditEvent{
+		ActorID: helper.UserIDFromContext(ctx.Request.Context()),
+		Action:  models.AuditActionLogLevelChange,
+		Changes: map[string]models.AuditChange{
+			field: {From: from, To: to},
+		},
+	})
+}
+
+func logLevels() models.LogLevelsResponse {
+	log := logger.Default()
+
+	res := models.LogLevelsResponse{
+		Global:      string(log.Level()),
+		Interceptor: string(log.InterceptorLevel()),
+		Layers:      map[string]string{},
+	}
+
+	for k, v := range log.LayerLevels() {
+		res.Layers[k] = string(v)
+	}
+
+	return res
+}
@@ -8,7 +8,6 @@ import (
 	"codepair-sinarmas/service/helper"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 func (h *Handler) Register(ctx *gin.Context) {
@@ -24,7 +23,7 @@ func (h *Handler) Register(ctx *gin.Context) {
 		return
 	}
 
-	validate := validator.New()
+	validate := helper.NewValidator()
 	err := validate.Struct(request)
 	if err != nil {
 		validationMessages := helper.BuildAndGetValidationMessage(err)
@@ -33,7 +32,7 @@ func (h *Handler) Register(ctx *gin.Context) {
 		return
 	}
 
-	_, errx = h.userUsecase.Register(&request)
+	_, errx = h.userUsecase.Register(ctx.Request.Context(), &request)
 	if errx != nil {
 		handleError(ctx, errx.Code(), errx)
 		return
@@ -57,7 +56,7 @@ func (h *Handler) login(ctx *gin.Context) {
 		return
 	}
 
-	validate := validator.New()
+	validate := helper.NewValidator()
 	err := validate.Struct(request)
 	if err != nil {
 		validationMessages := helper.BuildAndGetValidationMessage(err)
@@ -66,7 +65,7 @@ func (h *Handler) login(ctx *gin.Context) {
 		return
 	}
 
-	res, errx := h.userUsecase.Login(&request)
+	res, errx := h.userUsecase.Login(ctx.Request.Context(), &request)
 	if errx != nil {
 		handleError(ctx, errx.Code(), errx)
 		return
@@ -91,7 +90,7 @@ func (h *Handler) RequestOTP(ctx *gin.Context) {
 		return
 	}
 
-	validate := validator.New()
+	validate := helper.NewValidator()
 	err := validate.Struct(request)
 	if err != nil {
 		validationMessages := helper.BuildAndGetValidationMessage(err)
@@ -100,14 +99,14 @@ func (h *Handler) RequestOTP(ctx *gin.Context) {
 		return
 	}
 
-	res, errx := h.otpUsecase.RequestOtp(&request)
+	errx = h.otpUsecase.RequestOtp(ctx.Request.Context(), &request)
 	if errx != nil {
 		handleError(ctx, errx.Code(), errx)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, models.ResponseSuccess{
-		Data: res,
+		Message: "If the email is registered, an OTP has been sent to it",
 	})
 }
 
@@ -124,7 +123,7 @@ func (h *Handler) ValidateOTP(ctx *gin.Context) {
 		return
 	}
 
-	validate := validator.New()
+	validate := helper.NewValidator()
 	err := validate.Struct(request)
 	if err != nil {
 		validationMessages := helper.BuildAndGetValidationMessage(err)
@@ -133,7 +132,7 @@ func (h *Handler) ValidateOTP(ctx *gin.Context) {
 		return
 	}
 
-	_, errx = h.otpUsecase.ValidateOtp(&request)
+	_, errx = h.otpUsecase.ValidateOtp(ctx.Request.Context(), &request)
 	if errx != nil {
 		handleError(ctx, errx.Code(), errx)
 		return
@@ -143,3 +142,36 @@ func (h *Handler) ValidateOTP(ctx *gin.Context) {
 		Message: "OTP has been successfully validated",
 	})
 }
+
+func (h *Handler) ResetPassword(ctx *gin.Context) {
+	var (
+		request models.ResetPasswordRequest
+		errx    serror.SError
+	)
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		errx = serror.NewFromErrori(http.StatusBadRequest, err)
+		errx.AddComments("[handler][ResetPassword] while BodyJSONBind")
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	validate := helper.NewValidator()
+	err := validate.Struct(request)
+	if err != nil {
+		validationMessages := helper.BuildAndGetValidationMessage(err)
+		handleValidationError(ctx, validationMessages)
+
+		return
+	}
+
+	errx = h.userUsecase.ResetPassword(ctx.Request.Context(), &request)
+	if errx != nil {
+		handleError(ctx, errx.Code(), errx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Message: "Password has been successfully reset",
+	})
+}
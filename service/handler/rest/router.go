@@ -3,32 +3,40 @@ package rest
 import (
 	"os"
 
+	"codepair-sinarmas/models"
 	api "codepair-sinarmas/service"
 	middlewares "codepair-sinarmas/service/middleware"
 
 	"github.com/gin-contrib/cors"
 	limits "github.com/gin-contrib/size"
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
 )
 
 type Handler struct {
-	userUsecase api.UserUsecase
-	otpUsecase  api.OTPUsecase
+	userUsecase   api.UserUsecase
+	otpUsecase    api.OTPUsecase
+	auditUsecase  api.AuditUsecase
+	adminUsecase  api.AdminUsecase
+	apiKeyUsecase api.APIKeyUsecase
 }
 
 func CreateHandler(
 	userUsecase api.UserUsecase,
 	otpUsecase api.OTPUsecase,
+	auditUsecase api.AuditUsecase,
+	adminUsecase api.AdminUsecase,
+	apiKeyUsecase api.APIKeyUsecase,
 ) *gin.Engine {
 	obj := Handler{
-		userUsecase: userUsecase,
-		otpUsecase:  otpUsecase,
+		userUsecase:   userUsecase,
+		otpUsecase:    otpUsecase,
+		auditUsecase:  auditUsecase,
+		adminUsecase:  adminUsecase,
+		apiKeyUsecase: apiKeyUsecase,
 	}
 
 	var maxSize int64 = 1024 * 1024 * 10 //10 MB
-	logger := log.New()
-	r := gin.Default()
+	r := gin.New()
 	mainRouter := r.Group("/v1")
 
 	gin.SetMode(gin.DebugMode)
@@ -38,10 +46,17 @@ func CreateHandler(
 
 	corsconfig := cors.DefaultConfig()
 	corsconfig.AllowAllOrigins = true
-	corsconfig.AddAllowHeaders("Authorization")
+	corsconfig.AddAllowHeaders("Authorization", middlewares.HeaderAPIKey)
+	r.Use(gin.Logger())
 	r.Use(cors.New(corsconfig))
+	// Recovery goes first so the request middlewares are covered, it reads the request ID they set once it recovers
+	r.Use(middlewares.Recovery())
+	r.Use(middlewares.RequestContext())
+	r.Use(middlewares.RequestLogger())
 	r.Use(limits.RequestSizeLimiter(maxSize))
-	r.Use(middlewares.ErrorHandler(logger))
+	r.Use(middlewares.ErrorHandler())
+
+	r.GET("/.well-known/jwks.json", obj.GetJWKS)
 
 	mainRouter.GET("/ping", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{
@@ -53,10 +68,33 @@ func CreateHandler(
 	mainRouter.POST("/login", obj.login)
 	mainRouter.POST("/otp-request", obj.RequestOTP)
 	mainRouter.POST("/otp-validate", obj.ValidateOTP)
+	mainRouter.POST("/password-reset", obj.ResetPassword)
 
 	authorizedRouter := mainRouter.Group("/")
-	authorizedRouter.Use(middlewares.Auth())
+	authorizedRouter.Use(middlewares.Auth(userUsecase, apiKeyUsecase))
+	{
+	}
+
+	// API keys reach the admin routes through their scopes, the API keys and log levels stay with the admins
+	adminRouter := mainRouter.Group("/admin")
+	adminRouter.Use(middlewares.Auth(userUsecase, apiKeyUsecase), middlewares.RoleGuard(models.RoleAdmin, models.RoleService))
 	{
+		adminRouter.GET("/audit-events", middlewares.ScopeGuard(models.ScopeAuditRead), obj.GetAuditEvents)
+
+		adminRouter.GET("/users", middlewares.ScopeGuard(models.ScopeUsersRead), obj.GetUsers)
+		adminRouter.PUT("/users/:id/suspend", middlewares.ScopeGuard(models.ScopeUsersWrite), obj.SuspendUser)
+		adminRouter.PUT("/users/:id/unsuspend", middlewares.ScopeGuard(models.ScopeUsersWrite), obj.UnsuspendUser)
+		adminRouter.POST("/users/:id/force-password-reset", middlewares.ScopeGuard(models.ScopeUsersWrite), obj.ForcePasswordReset)
+		adminRouter.POST("/users/:id/revoke-sessions", middlewares.ScopeGuard(models.ScopeUsersWrite), obj.RevokeSessions)
+		adminRouter.PUT("/users/:id/role", middlewares.RoleGuard(models.RoleAdmin), obj.ChangeRole)
+
+		adminRouter.GET("/api-keys", middlewares.RoleGuard(models.RoleAdmin), obj.GetAPIKeys)
+		adminRouter.POST("/api-keys", middlewares.RoleGuard(models.RoleAdmin), obj.CreateAPIKey)
+		adminRouter.DELETE("/api-keys/:id", middlewares.RoleGuard(models.RoleAdmin), obj.RevokeAPIKey)
+
+		adminRouter.GET("/log-levels", middlewares.RoleGuard(models.RoleAdmin), obj.GetLogLevels)
+		adminRouter.PUT("/log-levels", middlewares.RoleGuard(models.RoleAdmin), obj.SetLogLevel)
+		adminRouter.DELETE("/log-levels/layers/:layer", middlewares.RoleGuard(models.RoleAdmin), obj.ClearLogLayerLevel)
 	}
 
 	return r
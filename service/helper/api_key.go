@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"codepair-sinarmas/pkg/serror"
+)
+
+const (
+	// APIKeyPrefix marks every API key issued by this service
+	APIKeyPrefix = "csk"
+
+	apiKeyPublicLength = 6
+	apiKeySecretLength = 32
+)
+
+// GenerateAPIKey returns a new API key formatted as csk_<prefix>_<secret>, only the prefix and hash have to be stored
+func GenerateAPIKey() (key string, prefix string, hash string, errx serror.SError) {
+	public := make([]byte, apiKeyPublicLength)
+	if _, err := rand.Read(public); err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][GenerateAPIKey] Failed to generate key prefix")
+		return
+	}
+
+	secret := make([]byte, apiKeySecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][GenerateAPIKey] Failed to generate key secret")
+		return
+	}
+
+	prefix = APIKeyPrefix + "_" + hex.EncodeToString(public)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	hash = HashAPIKey(key)
+	return
+}
+
+// ParseAPIKeyPrefix returns the lookup prefix of key
+func ParseAPIKeyPrefix(key string) (prefix string, ok bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != APIKeyPrefix || len(parts[1]) != apiKeyPublicLength*2 || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[0] + "_" + parts[1], true
+}
+
+// HashAPIKey returns the hex encoded SHA-256 of key, API keys carry enough entropy to not need a slow hash
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareAPIKey reports whether key matches hash in constant time
+func CompareAPIKey(hash, key string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashAPIKey(key))) == 1
+}
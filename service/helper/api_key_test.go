@@ -0,0 +1,33 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, errx := GenerateAPIKey()
+	assert.Nil(t, errx)
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.NotContains(t, hash, key)
+
+	parsed, ok := ParseAPIKeyPrefix(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+
+	assert.True(t, CompareAPIKey(hash, key))
+	assert.False(t, CompareAPIKey(hash, key+"x"))
+
+	other, _, _, errx := GenerateAPIKey()
+	assert.Nil(t, errx)
+	assert.NotEqual(t, key, other)
+}
+
+func TestParseAPIKeyPrefix(t *testing.T) {
+	for _, key := range []string{"", "csk", "csk_abc_secret", "abc_0123456789ab_secret", "csk_0123456789ab_"} {
+		_, ok := ParseAPIKeyPrefix(key)
+		assert.False(t, ok, key)
+	}
+}
@@ -17,7 +17,9 @@ const (
 	// Utilities
 	JAKARTA_TIME_LOC = "Asia/Jakarta"
 	ERROR_STRING     = "ERROR:"
-	SALT             = 8
+
+	// Password Hashing
+	DEFAULT_BCRYPT_COST = 12
 
 	// Date Format
 	DATE_FORMAT_LONG            = "2006-01-02 15:04:05 -0700 MST"
@@ -0,0 +1,118 @@
+package helper
+
+import (
+	"context"
+	"strconv"
+
+	"codepair-sinarmas/pkg/logger"
+	"codepair-sinarmas/pkg/serror"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type contextKey string
+
+const (
+	ContextKeyRequestID contextKey = "request_id"
+	ContextKeyClientIP  contextKey = "client_ip"
+	ContextKeyPrincipal contextKey = "principal"
+	ContextKeyLogSquad  contextKey = "log_squad"
+)
+
+const (
+	LogLayerHTTP    = "http"
+	LogLayerHandler = "handler"
+	LogLayerUsecase = "usecase"
+)
+
+const (
+	LogTagRequestID = logger.LogSquadTagRequestID
+	LogTagRoute     = logger.LogSquadTagRoute
+	LogTagMethod    = logger.LogSquadTagMethod
+	LogTagUserID    = logger.LogSquadTagUserID
+	LogTagUsername  = logger.LogSquadTagUsername
+)
+
+func WithRequestMeta(ctx context.Context, requestID, clientIP string) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyRequestID, requestID)
+	return context.WithValue(ctx, ContextKeyClientIP, clientIP)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	requestID, _ := ctx.Value(ContextKeyRequestID).(string)
+	return requestID
+}
+
+// TraceIDFromContext returns the trace ID of the active span, empty when the request is not traced
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return strconv.FormatUint(span.Context().TraceID(), 10)
+}
+
+func ClientIPFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	clientIP, _ := ctx.Value(ContextKeyClientIP).(string)
+	return clientIP
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, ContextKeyPrincipal, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, failing with 401 when it is missing or invalid
+func PrincipalFromContext(ctx context.Context) (*Principal, serror.SError) {
+	if ctx == nil {
+		return nil, ERR_PRINCIPAL_MISSING.New()
+	}
+
+	principal, _ := ctx.Value(ContextKeyPrincipal).(*Principal)
+	return checkPrincipal(principal)
+}
+
+// UserIDFromContext returns the user ID of the authenticated principal, 0 for service accounts and anonymous requests
+func UserIDFromContext(ctx context.Context) int64 {
+	principal, errx := PrincipalFromContext(ctx)
+	if errx != nil {
+		return 0
+	}
+
+	return principal.UserID
+}
+
+func WithLogSquad(ctx context.Context, squad logger.LogSquad) context.Context {
+	return context.WithValue(ctx, ContextKeyLogSquad, squad)
+}
+
+// LoggerFromContext returns the request squad under layerName, so every line about one request shares its tags,
+// a fresh squad of the default logger is returned outside of a request
+func LoggerFromContext(ctx context.Context, layerName string) logger.LogSquad {
+	if squad, ok := requestLogSquad(ctx); ok {
+		return squad.Layer(layerName)
+	}
+
+	return logger.CreateSquad(ctx, layerName)
+}
+
+func requestLogSquad(ctx context.Context) (logger.LogSquad, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	squad, ok := ctx.Value(ContextKeyLogSquad).(logger.LogSquad)
+	return squad, ok
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"codepair-sinarmas/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type tagRecorder struct {
+	mu   sync.Mutex
+	tags []map[string]string
+}
+
+func (r *tagRecorder) Translate(args logger.LogInterceptorTranslateArguments) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tags = append(r.tags, args.Tags)
+	return ""
+}
+
+func (r *tagRecorder) Process(lvl logger.ErrorLevel, msg string) {}
+
+func TestLoggerFromContext(t *testing.T) {
+	record := &tagRecorder{}
+	log := logger.Construct(logger.Options{Interceptor: record})
+
+	squad := log.CreateSquad(context.Background(), LogLayerHTTP)
+	squad.SetTag(LogTagRequestID, "req-1")
+	squad.SetTag(LogTagRoute, "/v1/users/:id")
+
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+	ginCtx.Request = ginCtx.Request.WithContext(WithLogSquad(ginCtx.Request.Context(), squad))
+
+	SetPrincipal(ginCtx, &Principal{Type: PrincipalTypeUser, UserID: 7, Name: "budi", AuthMethod: AuthMethodJWT})
+
+	LoggerFromContext(ginCtx.Request.Context(), LogLayerUsecase).Info("first")
+	LoggerFromContext(ginCtx.Request.Context(), LogLayerHandler).Info("second")
+
+	assert.Equal(t, []map[string]string{
+		{
+			logger.LogSquadTagLayerName: LogLayerUsecase,
+			LogTagRequestID:             "req-1",
+			LogTagRoute:                 "/v1/users/:id",
+			LogTagUserID:                "7",
+			LogTagUsername:              "budi",
+		},
+		{
+			logger.LogSquadTagLayerName: LogLayerHandler,
+			LogTagRequestID:             "req-1",
+			LogTagRoute:                 "/v1/users/:id",
+			LogTagUserID:                "7",
+			LogTagUsername:              "budi",
+		},
+	}, record.tags)
+
+	assert.NotNil(t, LoggerFromContext(context.Background(), LogLayerUsecase))
+	assert.NotNil(t, LoggerFromContext(nil, LogLayerUsecase))
+}
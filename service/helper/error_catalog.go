@@ -0,0 +1,386 @@
+package helper
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codepair-sinarmas/pkg/serror"
+)
+
+// ErrorKey is a stable error identifier clients can rely on, it is sent as the response key
+type ErrorKey string
+
+// Language is a supported response language
+type Language string
+
+const (
+	LanguageEnglish    Language = "en"
+	LanguageIndonesian Language = "id"
+
+	// DefaultLanguage is used when the client accepts none of the supported languages
+	DefaultLanguage = LanguageEnglish
+)
+
+const (
+	// Common Error Keys
+	ERR_INTERNAL    ErrorKey = "common.internal"
+	ERR_BAD_REQUEST ErrorKey = "common.bad_request"
+	ERR_VALIDATION  ErrorKey = "common.validation"
+	ERR_PANIC       ErrorKey = "common.panic"
+
+	// Token Error Keys
+	ERR_TOKEN_MISSING           ErrorKey = "token.missing"
+	ERR_TOKEN_MALFORMED         ErrorKey = "token.malformed"
+	ERR_TOKEN_INVALID           ErrorKey = "token.invalid"
+	ERR_TOKEN_UNKNOWN_KEY       ErrorKey = "token.unknown_key"
+	ERR_TOKEN_INVALID_SIGNATURE ErrorKey = "token.invalid_signature"
+	ERR_TOKEN_EXPIRED           ErrorKey = "token.expired"
+	ERR_TOKEN_NOT_YET_VALID     ErrorKey = "token.not_yet_valid"
+	ERR_TOKEN_INVALID_ISSUER    ErrorKey = "token.invalid_issuer"
+	ERR_TOKEN_INVALID_AUDIENCE  ErrorKey = "token.invalid_audience"
+	ERR_TOKEN_INVALID_CLAIMS    ErrorKey = "token.invalid_claims"
+
+	// API Key Error Keys
+	ERR_API_KEY_INVALID          ErrorKey = "api_key.invalid"
+	ERR_API_KEY_EXPIRED          ErrorKey = "api_key.expired"
+	ERR_API_KEY_REVOKED          ErrorKey = "api_key.revoked"
+	ERR_API_KEY_NOT_FOUND        ErrorKey = "api_key.not_found"
+	ERR_API_KEY_ALREADY_REVOKED  ErrorKey = "api_key.already_revoked"
+	ERR_API_KEY_EXPIRY_IN_PAST   ErrorKey = "api_key.expiry_in_past"
+	ERR_API_KEY_OWNER_NOT_EXISTS ErrorKey = "api_key.owner_not_exists"
+
+	// Principal Error Keys
+	ERR_PRINCIPAL_MISSING   ErrorKey = "principal.missing"
+	ERR_PRINCIPAL_INVALID   ErrorKey = "principal.invalid"
+	ERR_PRINCIPAL_FORBIDDEN ErrorKey = "principal.forbidden"
+
+	// User Error Keys
+	ERR_USER_NOT_FOUND               ErrorKey = "user.not_found"
+	ERR_USER_EMAIL_TAKEN             ErrorKey = "user.email_taken"
+	ERR_USER_PASSWORD_MISMATCH       ErrorKey = "user.password_mismatch"
+	ERR_USER_SUSPENDED               ErrorKey = "user.suspended"
+	ERR_USER_PASSWORD_RESET_REQUIRED ErrorKey = "user.password_reset_required"
+	ERR_USER_SUSPEND_SELF            ErrorKey = "user.suspend_self"
+	ERR_USER_ALREADY_SUSPENDED       ErrorKey = "user.already_suspended"
+	ERR_USER_NOT_SUSPENDED           ErrorKey = "user.not_suspended"
+	ERR_USER_ROLE_CHANGE_SELF        ErrorKey = "user.role_change_self"
+	ERR_USER_ROLE_UNCHANGED          ErrorKey = "user.role_unchanged"
+	ERR_SESSION_REVOKED              ErrorKey = "session.revoked"
+
+	// OTP Error Keys
+	ERR_OTP_INVALID           ErrorKey = "otp.invalid"
+	ERR_OTP_EXPIRED           ErrorKey = "otp.expired"
+	ERR_OTP_ALREADY_VALIDATED ErrorKey = "otp.already_validated"
+
+	// Logger Error Keys
+	ERR_LOG_LAYER_NOT_FOUND ErrorKey = "logger.layer_not_found"
+)
+
+// ProblemTypeBaseURI prefixes error keys to build the problem+json type URI
+var ProblemTypeBaseURI = "urn:codepair-sinarmas:problem:"
+
+// ProblemType returns the problem+json type URI of key, "about:blank" when there is no key
+func ProblemType(key string) string {
+	if key == "" || key == "-" {
+		return "about:blank"
+	}
+
+	return ProblemTypeBaseURI + key
+}
+
+// ErrorDefinition is a catalog entry, every entry has a message for each supported language
+type ErrorDefinition struct {
+	Status   int
+	Messages map[Language]string
+}
+
+var errorCatalog = map[ErrorKey]ErrorDefinition{
+	ERR_INTERNAL: {http.StatusInternalServerError, map[Language]string{
+		LanguageEnglish:    "Internal server error",
+		LanguageIndonesian: "Terjadi kesalahan pada server",
+	}},
+	ERR_BAD_REQUEST: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "Request body is invalid",
+		LanguageIndonesian: "Isi permintaan tidak valid",
+	}},
+	ERR_VALIDATION: {http.StatusUnprocessableEntity, map[Language]string{
+		LanguageEnglish:    "Validation error",
+		LanguageIndonesian: "Validasi gagal",
+	}},
+	ERR_PANIC: {http.StatusInternalServerError, map[Language]string{
+		LanguageEnglish:    "Internal server error",
+		LanguageIndonesian: "Terjadi kesalahan pada server",
+	}},
+
+	ERR_TOKEN_MISSING: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token not found",
+		LanguageIndonesian: "Token tidak ditemukan",
+	}},
+	ERR_TOKEN_MALFORMED: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token is malformed",
+		LanguageIndonesian: "Format token tidak valid",
+	}},
+	ERR_TOKEN_INVALID: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token is invalid",
+		LanguageIndonesian: "Token tidak valid",
+	}},
+	ERR_TOKEN_UNKNOWN_KEY: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token was signed with an unknown key",
+		LanguageIndonesian: "Token ditandatangani dengan kunci yang tidak dikenal",
+	}},
+	ERR_TOKEN_INVALID_SIGNATURE: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token signature is invalid",
+		LanguageIndonesian: "Tanda tangan token tidak valid",
+	}},
+	ERR_TOKEN_EXPIRED: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token has expired, please login again",
+		LanguageIndonesian: "Token sudah kedaluwarsa, silakan login kembali",
+	}},
+	ERR_TOKEN_NOT_YET_VALID: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token is not valid yet",
+		LanguageIndonesian: "Token belum berlaku",
+	}},
+	ERR_TOKEN_INVALID_ISSUER: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token issuer is invalid",
+		LanguageIndonesian: "Penerbit token tidak valid",
+	}},
+	ERR_TOKEN_INVALID_AUDIENCE: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token audience is invalid",
+		LanguageIndonesian: "Audiens token tidak valid",
+	}},
+	ERR_TOKEN_INVALID_CLAIMS: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Token claims are invalid",
+		LanguageIndonesian: "Klaim token tidak valid",
+	}},
+
+	ERR_API_KEY_INVALID: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "API key is invalid",
+		LanguageIndonesian: "API key tidak valid",
+	}},
+	ERR_API_KEY_EXPIRED: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "API key has expired",
+		LanguageIndonesian: "API key sudah kedaluwarsa",
+	}},
+	ERR_API_KEY_REVOKED: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "API key has been revoked",
+		LanguageIndonesian: "API key sudah dicabut",
+	}},
+	ERR_API_KEY_NOT_FOUND: {http.StatusNotFound, map[Language]string{
+		LanguageEnglish:    "API key not found",
+		LanguageIndonesian: "API key tidak ditemukan",
+	}},
+	ERR_API_KEY_ALREADY_REVOKED: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "API key has already been revoked",
+		LanguageIndonesian: "API key sudah dicabut sebelumnya",
+	}},
+	ERR_API_KEY_EXPIRY_IN_PAST: {http.StatusUnprocessableEntity, map[Language]string{
+		LanguageEnglish:    "API key expiry must be in the future",
+		LanguageIndonesian: "Masa berlaku API key harus di masa mendatang",
+	}},
+	ERR_API_KEY_OWNER_NOT_EXISTS: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "API key owner no longer exists",
+		LanguageIndonesian: "Pemilik API key sudah tidak ada",
+	}},
+
+	ERR_PRINCIPAL_MISSING: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Authentication required",
+		LanguageIndonesian: "Autentikasi diperlukan",
+	}},
+	ERR_PRINCIPAL_INVALID: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Authenticated principal is invalid",
+		LanguageIndonesian: "Identitas yang terautentikasi tidak valid",
+	}},
+	ERR_PRINCIPAL_FORBIDDEN: {http.StatusForbidden, map[Language]string{
+		LanguageEnglish:    "Your role is not allowed to use this API",
+		LanguageIndonesian: "Peran Anda tidak diizinkan menggunakan API ini",
+	}},
+
+	ERR_USER_NOT_FOUND: {http.StatusNotFound, map[Language]string{
+		LanguageEnglish:    "User not found",
+		LanguageIndonesian: "Pengguna tidak ditemukan",
+	}},
+	ERR_USER_EMAIL_TAKEN: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "Email already registered",
+		LanguageIndonesian: "Email sudah terdaftar",
+	}},
+	ERR_USER_PASSWORD_MISMATCH: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "Password does not match",
+		LanguageIndonesian: "Kata sandi tidak cocok",
+	}},
+	ERR_USER_SUSPENDED: {http.StatusForbidden, map[Language]string{
+		LanguageEnglish:    "User has been suspended",
+		LanguageIndonesian: "Pengguna telah dinonaktifkan",
+	}},
+	ERR_USER_PASSWORD_RESET_REQUIRED: {http.StatusForbidden, map[Language]string{
+		LanguageEnglish:    "Password reset is required, please reset your password using OTP",
+		LanguageIndonesian: "Kata sandi wajib diatur ulang, silakan atur ulang kata sandi menggunakan OTP",
+	}},
+	ERR_USER_SUSPEND_SELF: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "You cannot suspend your own account",
+		LanguageIndonesian: "Anda tidak dapat menonaktifkan akun Anda sendiri",
+	}},
+	ERR_USER_ALREADY_SUSPENDED: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "User has already been suspended",
+		LanguageIndonesian: "Pengguna sudah dinonaktifkan",
+	}},
+	ERR_USER_NOT_SUSPENDED: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "User is not suspended",
+		LanguageIndonesian: "Pengguna tidak sedang dinonaktifkan",
+	}},
+	ERR_USER_ROLE_CHANGE_SELF: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "You cannot change your own role",
+		LanguageIndonesian: "Anda tidak dapat mengubah peran Anda sendiri",
+	}},
+	ERR_USER_ROLE_UNCHANGED: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "User already has this role",
+		LanguageIndonesian: "Pengguna sudah memiliki peran ini",
+	}},
+	ERR_SESSION_REVOKED: {http.StatusUnauthorized, map[Language]string{
+		LanguageEnglish:    "Session has been revoked, please login again",
+		LanguageIndonesian: "Sesi telah dicabut, silakan login kembali",
+	}},
+
+	ERR_OTP_INVALID: {http.StatusNotFound, map[Language]string{
+		LanguageEnglish:    "Invalid OTP code",
+		LanguageIndonesian: "Kode OTP tidak valid",
+	}},
+	ERR_OTP_EXPIRED: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "OTP has expired",
+		LanguageIndonesian: "OTP sudah kedaluwarsa",
+	}},
+	ERR_OTP_ALREADY_VALIDATED: {http.StatusBadRequest, map[Language]string{
+		LanguageEnglish:    "OTP has been validated",
+		LanguageIndonesian: "OTP sudah divalidasi",
+	}},
+
+	ERR_LOG_LAYER_NOT_FOUND: {http.StatusNotFound, map[Language]string{
+		LanguageEnglish:    "Log level override not found",
+		LanguageIndonesian: "Pengaturan level log tidak ditemukan",
+	}},
+}
+
+// ErrorKeys returns every catalog key, sorted
+func ErrorKeys() []ErrorKey {
+	keys := make([]ErrorKey, 0, len(errorCatalog))
+	for k := range errorCatalog {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// LookupError returns the catalog entry of key
+func LookupError(key string) (ErrorDefinition, bool) {
+	def, ok := errorCatalog[ErrorKey(key)]
+	return def, ok
+}
+
+// Status returns the key HTTP status, 500 for keys missing from the catalog
+func (k ErrorKey) Status() int {
+	if def, ok := errorCatalog[k]; ok {
+		return def.Status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Message returns the key message in lang, falling back to English then to the key itself
+func (k ErrorKey) Message(lang Language) string {
+	def, ok := errorCatalog[k]
+	if !ok {
+		return string(k)
+	}
+
+	if msg, ok := def.Messages[lang]; ok {
+		return msg
+	}
+
+	return def.Messages[DefaultLanguage]
+}
+
+// New creates an SError with the key status and English message
+func (k ErrorKey) New() serror.SError {
+	return serror.Newsik(1, k.Status(), string(k), k.Message(DefaultLanguage))
+}
+
+// Newc creates an SError with the key status and English message, and note as its first comment
+func (k ErrorKey) Newc(note string) serror.SError {
+	return serror.Newsikc(1, k.Status(), string(k), k.Message(DefaultLanguage), note)
+}
+
+// Is reports whether errx carries the key
+func (k ErrorKey) Is(errx serror.SError) bool {
+	return errx != nil && errx.Key() == string(k)
+}
+
+// ErrorBody returns the code, key and message rendered for errx in lang,
+// errors outside of the catalog keep their own message except internal ones which are never exposed
+func ErrorBody(errx serror.SError, lang Language) (code int, key string, message string) {
+	code, key, message = errx.Code(), errx.Key(), errx.Error()
+	if key == "-" {
+		key = ""
+	}
+
+	def, known := errorCatalog[ErrorKey(key)]
+	if known {
+		message = ErrorKey(key).Message(lang)
+		if code == 0 {
+			code = def.Status
+		}
+	}
+
+	switch {
+	case code == 0 || code == http.StatusInternalServerError:
+		code = http.StatusInternalServerError
+		if !known {
+			if key == "" {
+				key = string(ERR_INTERNAL)
+			}
+			message = ERR_INTERNAL.Message(lang)
+		}
+
+	case key == "" && code == http.StatusBadRequest:
+		key = string(ERR_BAD_REQUEST)
+	}
+
+	return code, key, message
+}
+
+// ParseAcceptLanguage returns the supported language with the highest quality in an Accept-Language header
+func ParseAcceptLanguage(header string) Language {
+	var (
+		best    = DefaultLanguage
+		quality = -1.0
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		lang := Language(base)
+		if lang != LanguageEnglish && lang != LanguageIndonesian {
+			continue
+		}
+
+		if q > quality {
+			best, quality = lang, q
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,132 @@
+package helper
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCatalog(t *testing.T) {
+	for _, key := range ErrorKeys() {
+		t.Run(string(key), func(t *testing.T) {
+			def, ok := LookupError(string(key))
+			assert.True(t, ok)
+			assert.NotEmpty(t, http.StatusText(def.Status))
+
+			for _, lang := range []Language{LanguageEnglish, LanguageIndonesian} {
+				assert.NotEmpty(t, def.Messages[lang], "missing %s message", lang)
+			}
+
+			errx := key.New()
+			assert.Equal(t, def.Status, errx.Code())
+			assert.Equal(t, string(key), errx.Key())
+			assert.Equal(t, def.Messages[LanguageEnglish], errx.Error())
+		})
+	}
+}
+
+func TestErrorKeyNewc(t *testing.T) {
+	errx := ERR_TOKEN_EXPIRED.Newc("token has invalid claims: token is expired")
+
+	assert.Equal(t, http.StatusUnauthorized, errx.Code())
+	assert.Equal(t, string(ERR_TOKEN_EXPIRED), errx.Key())
+	assert.Contains(t, errx.Comments(), "Token has invalid claims: token is expired")
+	assert.True(t, ERR_TOKEN_EXPIRED.Is(errx))
+	assert.False(t, ERR_TOKEN_INVALID.Is(errx))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		expected Language
+	}{
+		{name: "empty", header: "", expected: LanguageEnglish},
+		{name: "indonesian", header: "id", expected: LanguageIndonesian},
+		{name: "region", header: "id-ID", expected: LanguageIndonesian},
+		{name: "unsupported", header: "fr-FR, de", expected: LanguageEnglish},
+		{name: "first supported", header: "fr, id;q=0.8, en;q=0.5", expected: LanguageIndonesian},
+		{name: "quality", header: "id;q=0.4, en-US;q=0.9", expected: LanguageEnglish},
+		{name: "malformed quality", header: "id;q=abc, en;q=0.1", expected: LanguageEnglish},
+		{name: "case insensitive", header: "ID-id", expected: LanguageIndonesian},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseAcceptLanguage(tc.header))
+		})
+	}
+}
+
+func TestErrorBody(t *testing.T) {
+	testCases := []struct {
+		name            string
+		errx            serror.SError
+		lang            Language
+		expectedCode    int
+		expectedKey     string
+		expectedMessage string
+	}{
+		{
+			name:            "catalog english",
+			errx:            ERR_USER_NOT_FOUND.New(),
+			lang:            LanguageEnglish,
+			expectedCode:    http.StatusNotFound,
+			expectedKey:     string(ERR_USER_NOT_FOUND),
+			expectedMessage: "User not found",
+		},
+		{
+			name:            "catalog indonesian",
+			errx:            ERR_USER_NOT_FOUND.New(),
+			lang:            LanguageIndonesian,
+			expectedCode:    http.StatusNotFound,
+			expectedKey:     string(ERR_USER_NOT_FOUND),
+			expectedMessage: "Pengguna tidak ditemukan",
+		},
+		{
+			name:            "bad request keeps its message",
+			errx:            serror.NewFromErrori(http.StatusBadRequest, errors.New("EOF")),
+			lang:            LanguageIndonesian,
+			expectedCode:    http.StatusBadRequest,
+			expectedKey:     string(ERR_BAD_REQUEST),
+			expectedMessage: "EOF",
+		},
+		{
+			name:            "internal hides its message",
+			errx:            serror.NewFromError(errors.New("dial tcp: connection refused")),
+			lang:            LanguageEnglish,
+			expectedCode:    http.StatusInternalServerError,
+			expectedKey:     string(ERR_INTERNAL),
+			expectedMessage: "Internal server error",
+		},
+		{
+			name:            "panic",
+			errx:            ERR_PANIC.New(),
+			lang:            LanguageIndonesian,
+			expectedCode:    http.StatusInternalServerError,
+			expectedKey:     string(ERR_PANIC),
+			expectedMessage: "Terjadi kesalahan pada server",
+		},
+		{
+			name:            "uncatalogued key",
+			errx:            serror.Newik(http.StatusConflict, "custom.conflict", "Already exists"),
+			lang:            LanguageIndonesian,
+			expectedCode:    http.StatusConflict,
+			expectedKey:     "custom.conflict",
+			expectedMessage: "Already exists",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, key, message := ErrorBody(tc.errx, tc.lang)
+			assert.Equal(t, tc.expectedCode, code)
+			assert.Equal(t, tc.expectedKey, key)
+			assert.Equal(t, tc.expectedMessage, message)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"codepair-sinarmas/pkg/serror"
+)
+
+const ContextKeyErrorContext contextKey = "error_context"
+
+type (
+	// requestErrorContext holds the request part of the signature, it is shared by every layer of one request
+	requestErrorContext struct {
+		mu  sync.RWMutex
+		sig serror.Signature
+	}
+
+	// errorContextObj is the serror.HCtx of one layer, it signs errors with the request signature and its layer
+	errorContextObj struct {
+		request *requestErrorContext
+		layer   string
+	}
+)
+
+// WithErrorContext stores a request error context stamped with requestID, route and method,
+// SetPrincipal adds the user once it is known
+func WithErrorContext(ctx context.Context, requestID, route, method string) context.Context {
+	return context.WithValue(ctx, ContextKeyErrorContext, &requestErrorContext{
+		sig: serror.Signature{
+			RequestID: requestID,
+			Route:     route,
+			Method:    method,
+		},
+	})
+}
+
+// ErrorContextFromContext returns the request error context under layer, outside of a request
+// the errors are only signed with the request ID and user found in ctx
+func ErrorContextFromContext(ctx context.Context, layer string) serror.HCtx {
+	request, ok := requestErrorCtx(ctx)
+	if !ok {
+		request = &requestErrorContext{
+			sig: serror.Signature{
+				RequestID: RequestIDFromContext(ctx),
+			},
+		}
+
+		if principal, errx := PrincipalFromContext(ctx); errx == nil {
+			request.sig.UserID = principalUserTag(principal)
+		}
+	}
+
+	return &errorContextObj{
+		request: request,
+		layer:   layer,
+	}
+}
+
+// CreateError creates an error signed with the context signature, notes are added as comments
+func (ox *errorContextObj) CreateError(msg string, notes ...string) (errx serror.SError) {
+	errx = serror.NewFromErrors(1, errors.New(msg))
+	errx.AddCommentsx(1, notes...)
+
+	return ox.SignError(errx)
+}
+
+// CreateErrorEx wraps err into an error signed with the context signature, notes are added as comments
+func (ox *errorContextObj) CreateErrorEx(err error, notes ...string) (errx serror.SError) {
+	errx = serror.NewFromErrors(1, err)
+	errx.AddCommentsx(1, notes...)
+
+	return ox.SignError(errx)
+}
+
+// SignError stamps errx with the context signature, errors already signed keep their original signature
+func (ox *errorContextObj) SignError(errx serror.SError) serror.SError {
+	if errx == nil || !errx.Signature().IsZero() {
+		return errx
+	}
+
+	sig := ox.request.signature()
+	sig.Layer = ox.layer
+	errx.SetSignature(sig)
+
+	return errx
+}
+
+func (ox *requestErrorContext) signature() serror.Signature {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	return ox.sig
+}
+
+func (ox *requestErrorContext) setUserID(userID string) {
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.sig.UserID = userID
+}
+
+func requestErrorCtx(ctx context.Context) (*requestErrorContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	request, ok := ctx.Value(ContextKeyErrorContext).(*requestErrorContext)
+	return request, ok
+}
@@ -0,0 +1,57 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorContextFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+
+	reqCtx := WithRequestMeta(ginCtx.Request.Context(), "req-1", "127.0.0.1")
+	reqCtx = WithErrorContext(reqCtx, "req-1", "/v1/users/:id", http.MethodGet)
+	ginCtx.Request = ginCtx.Request.WithContext(reqCtx)
+
+	anonymous := ErrorContextFromContext(ginCtx.Request.Context(), LogLayerHTTP).CreateError("token not found")
+	assert.Equal(t, serror.Signature{RequestID: "req-1", Route: "/v1/users/:id", Method: http.MethodGet, Layer: LogLayerHTTP}, anonymous.Signature())
+
+	SetPrincipal(ginCtx, &Principal{Type: PrincipalTypeUser, UserID: 7, AuthMethod: AuthMethodJWT})
+
+	hctx := ErrorContextFromContext(ginCtx.Request.Context(), LogLayerUsecase)
+	errx := hctx.CreateErrorEx(errors.New("connection refused"), "[usecase][GetUser] Failed to get user")
+	assert.Equal(t, serror.Signature{RequestID: "req-1", UserID: "7", Route: "/v1/users/:id", Method: http.MethodGet, Layer: LogLayerUsecase}, errx.Signature())
+	assert.Equal(t, "connection refused", errx.Error())
+	assert.Equal(t, "TestErrorContextFromContext", errx.StackFrames()[0].Name)
+	assert.Contains(t, errx.CommentStack()[1], "Failed to get user on [")
+	assert.Contains(t, errx.CommentStack()[1], "error_context_test.go:")
+
+	// the originating layer is kept once the error reaches the handler
+	signed := ErrorContextFromContext(ginCtx.Request.Context(), LogLayerHandler).SignError(errx)
+	assert.Equal(t, LogLayerUsecase, signed.Signature().Layer)
+
+	catalog := hctx.SignError(ERR_USER_NOT_FOUND.New())
+	assert.Equal(t, "7", catalog.Signature().UserID)
+	assert.Equal(t, string(ERR_USER_NOT_FOUND), catalog.Key())
+
+	assert.Nil(t, hctx.SignError(nil))
+}
+
+func TestErrorContextFromContext_OutsideRequest(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &Principal{Type: PrincipalTypeServiceAccount, Name: "billing", AuthMethod: AuthMethodAPIKey})
+
+	errx := ErrorContextFromContext(ctx, LogLayerUsecase).CreateError("quota exceeded")
+	assert.Equal(t, serror.Signature{UserID: "service_account:billing", Layer: LogLayerUsecase}, errx.Signature())
+
+	errx = ErrorContextFromContext(nil, LogLayerUsecase).CreateError("quota exceeded")
+	assert.Equal(t, serror.Signature{Layer: LogLayerUsecase}, errx.Signature())
+}
@@ -2,51 +2,21 @@ package helper
 
 import (
 	"bytes"
-	"errors"
+	"crypto/rand"
 	"fmt"
-	"math/rand"
+	"math/big"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(pass string) string {
-	password := []byte(pass)
-
-	hash, _ := bcrypt.GenerateFromPassword(password, SALT)
-
-	return string(hash)
-}
-
-func ComparePassword(hashed, password []byte) bool {
-	h, p := []byte(hashed), []byte(password)
-
-	err := bcrypt.CompareHashAndPassword(h, p)
-
-	return err == nil
-}
-
-func CheckPassword(password string) error {
-	lengthPassword := len(password)
-
-	if lengthPassword < 8 {
-		return errors.New("password must be at least 8 characters")
-	}
-
-	if lengthPassword > 20 {
-		return errors.New("password must be maximum 20 characters")
-	}
-
-	return nil
-}
-
 func SplitCamelCase(input string) string {
 	var result bytes.Buffer
 
@@ -108,6 +78,28 @@ func ParseDateRange(start, end string) (time.Time, time.Time, error) {
 	return startDate, endDate, nil
 }
 
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+
+	isValidPhoneNumber = regexp.MustCompile(`^\+?[0-9]{8,15}$`).MatchString
+)
+
+// NewValidator returns the shared validator with every custom tag registered
+func NewValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+
+		_ = validate.RegisterValidation("phone_number", func(fl validator.FieldLevel) bool {
+			phoneNumber := fl.Field().String()
+			return phoneNumber == "" || isValidPhoneNumber(phoneNumber)
+		})
+		registerPasswordPolicy(validate)
+	})
+
+	return validate
+}
+
 func BuildAndGetValidationMessage(err error) map[string]string {
 	validationErrors := make(map[string]string)
 
@@ -115,7 +107,7 @@ func BuildAndGetValidationMessage(err error) map[string]string {
 		fieldName := strings.ToLower(err.Field()) // Convert field name to lowercase
 		var errorMessage string
 
-		switch err.Tag() {
+		switch err.ActualTag() {
 		case "required":
 			errorMessage = fmt.Sprintf("%s is required", fieldName)
 		case "min":
@@ -124,6 +116,10 @@ func BuildAndGetValidationMessage(err error) map[string]string {
 			errorMessage = fmt.Sprintf("%s must not exceed %s characters", fieldName, err.Param())
 		case "email":
 			errorMessage = fmt.Sprintf("%s must be a valid email address", fieldName)
+		case "phone_number":
+			errorMessage = fmt.Sprintf("%s must be a valid phone number", fieldName)
+		case PasswordRuleLength, PasswordRuleUpper, PasswordRuleLower, PasswordRuleDigit, PasswordRuleSymbol, PasswordRuleCommon, PasswordRulePersonal:
+			errorMessage = GetPasswordPolicy().Message(fieldName, err.ActualTag())
 		default:
 			errorMessage = fmt.Sprintf("%s failed validation on rule '%s'", fieldName, err.Tag())
 		}
@@ -178,6 +174,11 @@ func GoLayout(format DateFormat) string {
 }
 
 func GenerateOtpCode() string {
-	rand.Seed(time.Now().UnixNano())
-	return fmt.Sprintf("%06d", rand.Intn(1000000))
+	// the code is the only secret of a password reset, it comes from crypto/rand
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf("%06d", n.Int64())
 }
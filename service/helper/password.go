@@ -0,0 +1,301 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"codepair-sinarmas/pkg/serror"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes and verifies passwords, every encoded hash carries the parameters it was produced with
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password
+	Hash(password string) (hash string, errx serror.SError)
+	// Verify reports whether password matches the encoded hash
+	Verify(hash, password string) (match bool, errx serror.SError)
+	// Identify reports whether hash was produced by this hasher
+	Identify(hash string) bool
+	// NeedsRehash reports whether hash was produced with outdated parameters
+	NeedsRehash(hash string) bool
+}
+
+var passwordHasher PasswordHasher = NewPasswordHasherChain(
+	NewBcryptHasher(DEFAULT_BCRYPT_COST),
+	NewArgon2idHasher(DefaultArgon2idParams()),
+)
+
+// SetPasswordHasher to replace the hasher used by HashPassword and VerifyPassword
+func SetPasswordHasher(hasher PasswordHasher) {
+	if hasher == nil {
+		panic("Null password hasher")
+	}
+
+	passwordHasher = hasher
+}
+
+func HashPassword(pass string) (string, serror.SError) {
+	return passwordHasher.Hash(pass)
+}
+
+// VerifyPassword reports whether password matches hash and whether hash should be upgraded
+func VerifyPassword(hash, password string) (match bool, needsRehash bool, errx serror.SError) {
+	match, errx = passwordHasher.Verify(hash, password)
+	if errx != nil || !match {
+		return
+	}
+
+	needsRehash = passwordHasher.NeedsRehash(hash)
+	return
+}
+
+func ComparePassword(hashed, password []byte) bool {
+	match, _, errx := VerifyPassword(string(hashed), string(password))
+	return errx == nil && match
+}
+
+// bcrypt
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DEFAULT_BCRYPT_COST
+	}
+
+	return &bcryptHasher{cost: cost}
+}
+
+func (ox *bcryptHasher) Hash(password string) (hash string, errx serror.SError) {
+	byt, err := bcrypt.GenerateFromPassword([]byte(password), ox.cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		errx = ERR_VALIDATION.Newc("[helper][bcrypt] Password exceeds 72 bytes")
+		return
+	}
+
+	if err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][bcrypt] Failed to hash password")
+		return
+	}
+
+	return string(byt), nil
+}
+
+func (ox *bcryptHasher) Verify(hash, password string) (match bool, errx serror.SError) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+
+		errx = serror.NewFromErrorc(err, "[helper][bcrypt] Failed to verify password")
+		return
+	}
+
+	return true, nil
+}
+
+func (ox *bcryptHasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (ox *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != ox.cost
+}
+
+// argon2id
+
+// Argon2idParams holds the argon2id tuning parameters
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams returns the OWASP recommended argon2id parameters
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	def := DefaultArgon2idParams()
+	if params.Memory == 0 {
+		params.Memory = def.Memory
+	}
+
+	if params.Iterations == 0 {
+		params.Iterations = def.Iterations
+	}
+
+	if params.Parallelism == 0 {
+		params.Parallelism = def.Parallelism
+	}
+
+	if params.SaltLength == 0 {
+		params.SaltLength = def.SaltLength
+	}
+
+	if params.KeyLength == 0 {
+		params.KeyLength = def.KeyLength
+	}
+
+	return &argon2idHasher{params: params}
+}
+
+func (ox *argon2idHasher) Hash(password string) (hash string, errx serror.SError) {
+	salt := make([]byte, ox.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][argon2id] Failed to generate salt")
+		return
+	}
+
+	key := argon2.IDKey([]byte(password), salt, ox.params.Iterations, ox.params.Memory, ox.params.Parallelism, ox.params.KeyLength)
+
+	hash = fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		ox.params.Memory,
+		ox.params.Iterations,
+		ox.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+	return
+}
+
+func (ox *argon2idHasher) Verify(hash, password string) (match bool, errx serror.SError) {
+	params, salt, key, errx := ox.decode(hash)
+	if errx != nil {
+		return
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (ox *argon2idHasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func (ox *argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, errx := ox.decode(hash)
+	if errx != nil {
+		return true
+	}
+
+	return params.Memory != ox.params.Memory ||
+		params.Iterations != ox.params.Iterations ||
+		params.Parallelism != ox.params.Parallelism ||
+		params.SaltLength != ox.params.SaltLength ||
+		params.KeyLength != ox.params.KeyLength
+}
+
+func (ox *argon2idHasher) decode(hash string) (params Argon2idParams, salt []byte, key []byte, errx serror.SError) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		errx = serror.New("[helper][argon2id] Invalid hash format")
+		return
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		errx = serror.Newf("[helper][argon2id] Unsupported version %s", parts[2])
+		return
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][argon2id] Invalid hash parameters")
+		return
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][argon2id] Invalid hash salt")
+		return
+	}
+
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		errx = serror.NewFromErrorc(err, "[helper][argon2id] Invalid hash key")
+		return
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return
+}
+
+// chain
+
+type passwordHasherChain struct {
+	preferred PasswordHasher
+	fallbacks []PasswordHasher
+}
+
+// NewPasswordHasherChain hashes with preferred and still verifies hashes produced by fallbacks
+func NewPasswordHasherChain(preferred PasswordHasher, fallbacks ...PasswordHasher) PasswordHasher {
+	return &passwordHasherChain{
+		preferred: preferred,
+		fallbacks: fallbacks,
+	}
+}
+
+func (ox *passwordHasherChain) Hash(password string) (hash string, errx serror.SError) {
+	return ox.preferred.Hash(password)
+}
+
+func (ox *passwordHasherChain) Verify(hash, password string) (match bool, errx serror.SError) {
+	hasher := ox.find(hash)
+	if hasher == nil {
+		errx = serror.New("[helper][password] Unknown password hash format")
+		return
+	}
+
+	return hasher.Verify(hash, password)
+}
+
+func (ox *passwordHasherChain) Identify(hash string) bool {
+	return ox.find(hash) != nil
+}
+
+func (ox *passwordHasherChain) NeedsRehash(hash string) bool {
+	if !ox.preferred.Identify(hash) {
+		return true
+	}
+
+	return ox.preferred.NeedsRehash(hash)
+}
+
+func (ox *passwordHasherChain) find(hash string) PasswordHasher {
+	if ox.preferred.Identify(hash) {
+		return ox.preferred
+	}
+
+	for _, v := range ox.fallbacks {
+		if v.Identify(hash) {
+			return v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package helper
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PasswordPolicy describes the rules a new password has to satisfy
+type PasswordPolicy struct {
+	MinLength            int
+	MaxLength            int
+	RequireUpper         bool
+	RequireLower         bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	BanCommon            bool
+	DisallowPersonalInfo bool
+}
+
+const (
+	// PasswordPolicyTag is the validator tag running every password policy rule
+	PasswordPolicyTag = "password_policy"
+
+	PasswordRuleLength   = "password_length"
+	PasswordRuleUpper    = "password_upper"
+	PasswordRuleLower    = "password_lower"
+	PasswordRuleDigit    = "password_digit"
+	PasswordRuleSymbol   = "password_symbol"
+	PasswordRuleCommon   = "password_common"
+	PasswordRulePersonal = "password_personal"
+)
+
+var (
+	//go:embed common_passwords.txt
+	commonPasswordsRaw string
+	commonPasswords    = parseCommonPasswords(commonPasswordsRaw)
+
+	passwordPolicy = DefaultPasswordPolicy()
+
+	passwordRules = []string{
+		PasswordRuleLength,
+		PasswordRuleUpper,
+		PasswordRuleLower,
+		PasswordRuleDigit,
+		PasswordRuleSymbol,
+		PasswordRuleCommon,
+		PasswordRulePersonal,
+	}
+)
+
+// DefaultPasswordPolicy returns the password policy used when nothing is configured
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:            8,
+		MaxLength:            72,
+		RequireUpper:         true,
+		RequireLower:         true,
+		RequireDigit:         true,
+		RequireSymbol:        false,
+		BanCommon:            true,
+		DisallowPersonalInfo: true,
+	}
+}
+
+// SetPasswordPolicy to replace the active password policy
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicy = policy
+}
+
+// GetPasswordPolicy returns the active password policy
+func GetPasswordPolicy() PasswordPolicy {
+	return passwordPolicy
+}
+
+// Violations returns the rules password breaks, personal holds values like email and name the password must not contain
+func (p PasswordPolicy) Violations(password string, personal ...string) (rules []string) {
+	for _, rule := range passwordRules {
+		if !p.check(rule, password, personal) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return
+}
+
+// Message returns the human readable message for a broken rule
+func (p PasswordPolicy) Message(field string, rule string) string {
+	switch rule {
+	case PasswordRuleLength:
+		return fmt.Sprintf("%s must be at least %d characters and at most %d bytes long", field, p.MinLength, p.MaxLength)
+	case PasswordRuleUpper:
+		return fmt.Sprintf("%s must contain at least one uppercase letter", field)
+	case PasswordRuleLower:
+		return fmt.Sprintf("%s must contain at least one lowercase letter", field)
+	case PasswordRuleDigit:
+		return fmt.Sprintf("%s must contain at least one digit", field)
+	case PasswordRuleSymbol:
+		return fmt.Sprintf("%s must contain at least one symbol", field)
+	case PasswordRuleCommon:
+		return fmt.Sprintf("%s is too common, please choose a less predictable one", field)
+	case PasswordRulePersonal:
+		return fmt.Sprintf("%s must not contain your email or name", field)
+	}
+
+	return fmt.Sprintf("%s failed validation on rule '%s'", field, rule)
+}
+
+func CheckPassword(password string, personal ...string) error {
+	policy := GetPasswordPolicy()
+
+	rules := policy.Violations(password, personal...)
+	if len(rules) > 0 {
+		return errors.New(policy.Message("password", rules[0]))
+	}
+
+	return nil
+}
+
+// private
+
+func (p PasswordPolicy) check(rule string, password string, personal []string) bool {
+	switch rule {
+	case PasswordRuleLength:
+		// the maximum counts bytes since bcrypt refuses passwords longer than 72 bytes
+		return (p.MinLength <= 0 || utf8.RuneCountInString(password) >= p.MinLength) && (p.MaxLength <= 0 || len(password) <= p.MaxLength)
+
+	case PasswordRuleUpper:
+		return !p.RequireUpper || strings.IndexFunc(password, unicode.IsUpper) >= 0
+
+	case PasswordRuleLower:
+		return !p.RequireLower || strings.IndexFunc(password, unicode.IsLower) >= 0
+
+	case PasswordRuleDigit:
+		return !p.RequireDigit || strings.IndexFunc(password, unicode.IsDigit) >= 0
+
+	case PasswordRuleSymbol:
+		return !p.RequireSymbol || strings.IndexFunc(password, isPasswordSymbol) >= 0
+
+	case PasswordRuleCommon:
+		_, found := commonPasswords[strings.ToLower(password)]
+		return !p.BanCommon || !found
+
+	case PasswordRulePersonal:
+		return !p.DisallowPersonalInfo || !containsPersonalInfo(password, personal)
+	}
+
+	return true
+}
+
+func isPasswordSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func containsPersonalInfo(password string, personal []string) bool {
+	password = strings.ToLower(password)
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		// only the local part of an email is personal, the domain is shared by many users
+		if i := strings.LastIndex(value, "@"); i >= 0 {
+			value = value[:i]
+		}
+
+		if value == "" {
+			continue
+		}
+
+		candidates := strings.FieldsFunc(value, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '.' || r == '_' || r == '-' || r == '+'
+		})
+		candidates = append(candidates, value)
+
+		for _, v := range candidates {
+			if utf8.RuneCountInString(v) >= 3 && strings.Contains(password, v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func parseCommonPasswords(raw string) map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, v := range strings.Split(raw, "\n") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v != "" {
+			res[v] = struct{}{}
+		}
+	}
+
+	return res
+}
+
+// registerPasswordPolicy to register every password rule and the password_policy alias
+func registerPasswordPolicy(validate *validator.Validate) {
+	for _, rule := range passwordRules {
+		rule := rule
+		_ = validate.RegisterValidation(rule, func(fl validator.FieldLevel) bool {
+			return GetPasswordPolicy().check(rule, fl.Field().String(), personalInfoFromParent(fl.Parent()))
+		})
+	}
+
+	validate.RegisterAlias(PasswordPolicyTag, strings.Join(passwordRules, ","))
+}
+
+// personalInfoFromParent collects the Email and Name fields of the validated struct when they exist
+func personalInfoFromParent(parent reflect.Value) (personal []string) {
+	for parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+
+	if parent.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, name := range []string{"Email", "Name"} {
+		field := parent.FieldByName(name)
+		if field.IsValid() && field.Kind() == reflect.String {
+			personal = append(personal, field.String())
+		}
+	}
+
+	return
+}
@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Violations(t *testing.T) {
+	type testCase struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		personal []string
+		expected []string
+	}
+
+	strict := DefaultPasswordPolicy()
+	strict.RequireSymbol = true
+
+	testCases := []testCase{
+		{
+			name:     "valid password",
+			policy:   DefaultPasswordPolicy(),
+			password: "Tr0ub4dor-horse",
+			personal: []string{"john@example.com", "John Doe"},
+			expected: nil,
+		},
+		{
+			name:     "too short",
+			policy:   DefaultPasswordPolicy(),
+			password: "Ab1",
+			expected: []string{PasswordRuleLength},
+		},
+		{
+			name:     "too many bytes",
+			policy:   DefaultPasswordPolicy(),
+			password: "Ab1" + strings.Repeat("é", 35),
+			expected: []string{PasswordRuleLength},
+		},
+		{
+			name:     "missing character classes",
+			policy:   strict,
+			password: "alllowercase",
+			expected: []string{PasswordRuleUpper, PasswordRuleDigit, PasswordRuleSymbol},
+		},
+		{
+			name:     "common password",
+			policy:   PasswordPolicy{BanCommon: true},
+			password: "Password123",
+			expected: []string{PasswordRuleCommon},
+		},
+		{
+			name:     "contains email local part",
+			policy:   PasswordPolicy{DisallowPersonalInfo: true},
+			password: "MyJohnny2024",
+			personal: []string{"johnny@example.com"},
+			expected: []string{PasswordRulePersonal},
+		},
+		{
+			name:     "email domain is not personal",
+			policy:   DefaultPasswordPolicy(),
+			password: "Welcome2024!",
+			personal: []string{"alice@gmail.com", "Alice Smith"},
+			expected: nil,
+		},
+		{
+			name:     "email provider is not personal",
+			policy:   PasswordPolicy{DisallowPersonalInfo: true},
+			password: "MyGmailYahoo.co.id",
+			personal: []string{"alice.smith@gmail.co.id"},
+			expected: nil,
+		},
+		{
+			name:     "contains name",
+			policy:   PasswordPolicy{DisallowPersonalInfo: true},
+			password: "DoeFamily99",
+			personal: []string{"John Doe"},
+			expected: []string{PasswordRulePersonal},
+		},
+		{
+			name:     "disabled rules always pass",
+			policy:   PasswordPolicy{},
+			password: "password",
+			personal: []string{"password@example.com"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.policy.Violations(tc.password, tc.personal...))
+		})
+	}
+}
+
+func TestPasswordPolicy_Validator(t *testing.T) {
+	type registerRequest struct {
+		Name     string `validate:"required"`
+		Email    string `validate:"required,email"`
+		Password string `validate:"required,password_policy"`
+	}
+
+	validate := NewValidator()
+
+	err := validate.Struct(registerRequest{Name: "John Doe", Email: "john@example.com", Password: "Tr0ub4dor-horse"})
+	assert.Nil(t, err)
+
+	err = validate.Struct(registerRequest{Name: "John Doe", Email: "john@example.com", Password: "lowercase1"})
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]string{
+		"password": "password must contain at least one uppercase letter",
+	}, BuildAndGetValidationMessage(err))
+
+	err = validate.Struct(registerRequest{Name: "John Doe", Email: "john@example.com", Password: "Johnny123"})
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]string{
+		"password": "password must not contain your email or name",
+	}, BuildAndGetValidationMessage(err))
+}
+
+func TestCheckPassword(t *testing.T) {
+	assert.Nil(t, CheckPassword("Tr0ub4dor-horse", "john@example.com"))
+	assert.EqualError(t, CheckPassword("Qwerty123"), "password is too common, please choose a less predictable one")
+}
@@ -0,0 +1,88 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	fastArgon2id := Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+	type testCase struct {
+		name   string
+		hasher PasswordHasher
+		prefix string
+	}
+
+	testCases := []testCase{
+		{
+			name:   "bcrypt",
+			hasher: NewBcryptHasher(bcrypt.MinCost),
+			prefix: "$2a$04$",
+		},
+		{
+			name:   "argon2id",
+			hasher: NewArgon2idHasher(fastArgon2id),
+			prefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hash, errx := tc.hasher.Hash("s3cret-password")
+			assert.Nil(t, errx)
+			assert.True(t, strings.HasPrefix(hash, tc.prefix), hash)
+			assert.True(t, tc.hasher.Identify(hash))
+			assert.False(t, tc.hasher.NeedsRehash(hash))
+
+			match, errx := tc.hasher.Verify(hash, "s3cret-password")
+			assert.Nil(t, errx)
+			assert.True(t, match)
+
+			match, errx = tc.hasher.Verify(hash, "wrong-password")
+			assert.Nil(t, errx)
+			assert.False(t, match)
+		})
+	}
+}
+
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	oldBcrypt, _ := NewBcryptHasher(bcrypt.MinCost).Hash("password")
+	assert.True(t, NewBcryptHasher(bcrypt.MinCost+1).NeedsRehash(oldBcrypt))
+
+	oldArgon2id, _ := NewArgon2idHasher(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash("password")
+	assert.True(t, NewArgon2idHasher(Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1}).NeedsRehash(oldArgon2id))
+	assert.False(t, NewArgon2idHasher(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}).NeedsRehash(oldArgon2id))
+}
+
+func TestPasswordHasherChain(t *testing.T) {
+	bcryptHasher := NewBcryptHasher(bcrypt.MinCost)
+	argon2idHasher := NewArgon2idHasher(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1})
+	chain := NewPasswordHasherChain(argon2idHasher, bcryptHasher)
+
+	legacyHash, _ := bcryptHasher.Hash("password")
+
+	match, errx := chain.Verify(legacyHash, "password")
+	assert.Nil(t, errx)
+	assert.True(t, match)
+	assert.True(t, chain.NeedsRehash(legacyHash))
+
+	hash, errx := chain.Hash("password")
+	assert.Nil(t, errx)
+	assert.True(t, argon2idHasher.Identify(hash))
+	assert.False(t, chain.NeedsRehash(hash))
+
+	_, errx = chain.Verify("plain-text", "plain-text")
+	assert.NotNil(t, errx)
+}
+
+func TestHashPassword_Error(t *testing.T) {
+	_, errx := NewBcryptHasher(bcrypt.MinCost).Hash(strings.Repeat("a", 73))
+	assert.True(t, ERR_VALIDATION.Is(errx))
+
+	_, errx = NewArgon2idHasher(Argon2idParams{}).Verify("$argon2id$v=19$m=x$salt$key", "password")
+	assert.NotNil(t, errx)
+}
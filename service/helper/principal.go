@@ -0,0 +1,153 @@
+package helper
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utarray"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// PrincipalTypeUser is a constant for requests made on behalf of a user
+	PrincipalTypeUser = "user"
+	// PrincipalTypeServiceAccount is a constant for requests made by a service account
+	PrincipalTypeServiceAccount = "service_account"
+
+	// AuthMethodJWT is a constant for requests authenticated with a bearer token
+	AuthMethodJWT = "jwt"
+	// AuthMethodAPIKey is a constant for requests authenticated with an API key
+	AuthMethodAPIKey = "api_key"
+
+	// GinKeyPrincipal is the gin context key holding the authenticated principal
+	GinKeyPrincipal = "principal"
+)
+
+// Principal is whoever authenticated the current request
+type Principal struct {
+	Type       string
+	UserID     int64
+	Name       string
+	Email      string
+	Roles      []string
+	Scopes     []string
+	AuthMethod string
+	// TokenID is the jti of a bearer token or the prefix of an API key
+	TokenID   string
+	APIKeyID  int64
+	ExpiresAt time.Time
+}
+
+// HasRole reports whether the principal was granted one of roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, v := range roles {
+		if utarray.IsExist(v, p.Roles) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasScope reports whether the principal was granted one of scopes
+func (p *Principal) HasScope(scopes ...string) bool {
+	for _, v := range scopes {
+		if utarray.IsExist(v, p.Scopes) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsServiceAccount reports whether the principal is a service account
+func (p *Principal) IsServiceAccount() bool {
+	return p.Type == PrincipalTypeServiceAccount
+}
+
+// Validate checks the principal is complete for its type and auth method
+func (p *Principal) Validate() serror.SError {
+	switch p.AuthMethod {
+	case AuthMethodJWT, AuthMethodAPIKey:
+	default:
+		return ERR_PRINCIPAL_INVALID.Newc(fmt.Sprintf("Unknown auth method %s", p.AuthMethod))
+	}
+
+	switch p.Type {
+	case PrincipalTypeUser:
+		if p.UserID <= 0 {
+			return ERR_PRINCIPAL_INVALID.Newc("User principal has no user ID")
+		}
+
+	case PrincipalTypeServiceAccount:
+		if p.AuthMethod != AuthMethodAPIKey {
+			return ERR_PRINCIPAL_INVALID.Newc("Service accounts can only authenticate with an API key")
+		}
+
+	default:
+		return ERR_PRINCIPAL_INVALID.Newc(fmt.Sprintf("Unknown principal type %s", p.Type))
+	}
+
+	return nil
+}
+
+// SetPrincipal stores principal in both the gin context and the request context,
+// and tags the request log squad and error context with it
+func SetPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(GinKeyPrincipal, principal)
+	ctx.Request = ctx.Request.WithContext(WithPrincipal(ctx.Request.Context(), principal))
+
+	userID := principalUserTag(principal)
+
+	if squad, ok := requestLogSquad(ctx.Request.Context()); ok {
+		squad.SetTag(LogTagUserID, userID)
+		if principal.Type == PrincipalTypeUser && principal.Name != "" {
+			squad.SetTag(LogTagUsername, principal.Name)
+		}
+	}
+
+	if request, ok := requestErrorCtx(ctx.Request.Context()); ok {
+		request.setUserID(userID)
+	}
+}
+
+// GetPrincipal returns the principal set by the auth middleware, failing with 401 when it is missing or invalid
+func GetPrincipal(ctx *gin.Context) (*Principal, serror.SError) {
+	value, exists := ctx.Get(GinKeyPrincipal)
+	if !exists {
+		return nil, ERR_PRINCIPAL_MISSING.New()
+	}
+
+	principal, ok := value.(*Principal)
+	if !ok {
+		return nil, ERR_PRINCIPAL_INVALID.New()
+	}
+
+	return checkPrincipal(principal)
+}
+
+// private
+
+// principalUserTag identifies principal in logs and error signatures, service accounts have no user ID
+func principalUserTag(principal *Principal) string {
+	if principal.UserID != 0 {
+		return strconv.FormatInt(principal.UserID, 10)
+	}
+
+	return principal.Type + ":" + principal.Name
+}
+
+func checkPrincipal(principal *Principal) (*Principal, serror.SError) {
+	if principal == nil {
+		return nil, ERR_PRINCIPAL_MISSING.New()
+	}
+
+	if errx := principal.Validate(); errx != nil {
+		return nil, errx
+	}
+
+	return principal, nil
+}
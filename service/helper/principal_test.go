@@ -0,0 +1,156 @@
+package helper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalFromContext(t *testing.T) {
+	type testCase struct {
+		name        string
+		ctx         context.Context
+		expectedKey ErrorKey
+	}
+
+	testCases := []testCase{
+		{
+			name: "user principal",
+			ctx: WithPrincipal(context.Background(), &Principal{
+				Type:       PrincipalTypeUser,
+				UserID:     1,
+				AuthMethod: AuthMethodJWT,
+			}),
+		},
+		{
+			name: "service account principal",
+			ctx: WithPrincipal(context.Background(), &Principal{
+				Type:       PrincipalTypeServiceAccount,
+				Name:       "batch-job",
+				AuthMethod: AuthMethodAPIKey,
+			}),
+		},
+		{
+			name:        "nil context",
+			ctx:         nil,
+			expectedKey: ERR_PRINCIPAL_MISSING,
+		},
+		{
+			name:        "missing principal",
+			ctx:         context.Background(),
+			expectedKey: ERR_PRINCIPAL_MISSING,
+		},
+		{
+			name:        "nil principal",
+			ctx:         WithPrincipal(context.Background(), nil),
+			expectedKey: ERR_PRINCIPAL_MISSING,
+		},
+		{
+			name: "user principal without user ID",
+			ctx: WithPrincipal(context.Background(), &Principal{
+				Type:       PrincipalTypeUser,
+				AuthMethod: AuthMethodJWT,
+			}),
+			expectedKey: ERR_PRINCIPAL_INVALID,
+		},
+		{
+			name: "service account with bearer token",
+			ctx: WithPrincipal(context.Background(), &Principal{
+				Type:       PrincipalTypeServiceAccount,
+				AuthMethod: AuthMethodJWT,
+			}),
+			expectedKey: ERR_PRINCIPAL_INVALID,
+		},
+		{
+			name: "unknown auth method",
+			ctx: WithPrincipal(context.Background(), &Principal{
+				Type:   PrincipalTypeUser,
+				UserID: 1,
+			}),
+			expectedKey: ERR_PRINCIPAL_INVALID,
+		},
+		{
+			name: "unknown principal type",
+			ctx: WithPrincipal(context.Background(), &Principal{
+				Type:       "robot",
+				AuthMethod: AuthMethodAPIKey,
+			}),
+			expectedKey: ERR_PRINCIPAL_INVALID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, errx := PrincipalFromContext(tc.ctx)
+
+			if tc.expectedKey != "" {
+				assert.Nil(t, principal)
+				if assert.NotNil(t, errx) {
+					assert.Equal(t, string(tc.expectedKey), errx.Key())
+					assert.Equal(t, http.StatusUnauthorized, errx.Code())
+				}
+				assert.Equal(t, int64(0), UserIDFromContext(tc.ctx))
+			} else {
+				assert.Nil(t, errx)
+				assert.NotNil(t, principal)
+			}
+		})
+	}
+}
+
+func TestGetPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func() *gin.Context {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		return ctx
+	}
+
+	t.Run("set principal", func(t *testing.T) {
+		ctx := newContext()
+		SetPrincipal(ctx, &Principal{
+			Type:       PrincipalTypeUser,
+			UserID:     7,
+			Roles:      []string{"admin"},
+			Scopes:     []string{"users:read"},
+			AuthMethod: AuthMethodAPIKey,
+		})
+
+		principal, errx := GetPrincipal(ctx)
+		assert.Nil(t, errx)
+		assert.Equal(t, int64(7), principal.UserID)
+		assert.True(t, principal.HasRole("user", "admin"))
+		assert.False(t, principal.HasRole("service"))
+		assert.True(t, principal.HasScope("users:read"))
+		assert.False(t, principal.HasScope("users:write"))
+
+		// usecases read the same principal from the request context
+		assert.Equal(t, int64(7), UserIDFromContext(ctx.Request.Context()))
+	})
+
+	t.Run("missing principal", func(t *testing.T) {
+		_, errx := GetPrincipal(newContext())
+		assert.Equal(t, string(ERR_PRINCIPAL_MISSING), errx.Key())
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		ctx := newContext()
+		ctx.Set(GinKeyPrincipal, "7")
+
+		_, errx := GetPrincipal(ctx)
+		assert.Equal(t, string(ERR_PRINCIPAL_INVALID), errx.Key())
+	})
+
+	t.Run("invalid principal", func(t *testing.T) {
+		ctx := newContext()
+		SetPrincipal(ctx, &Principal{Type: PrincipalTypeUser, AuthMethod: AuthMethodJWT})
+
+		_, errx := GetPrincipal(ctx)
+		assert.Equal(t, string(ERR_PRINCIPAL_INVALID), errx.Key())
+	})
+}
@@ -1,47 +1,218 @@
 package helper
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"os"
+	"strconv"
+	"sync"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"codepair-sinarmas/pkg/serror"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateToken(id int64, email, name string) string {
-	claims := jwt.MapClaims{
-		"id":       id,
-		"name":     name,
-		"email":    email,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
-		"exp_date": time.Now().Add(time.Hour * 24),
+// TokenClaims is the payload of an access token, the user ID is carried in the sub claim
+type TokenClaims struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Version int64  `json:"ver"`
+	jwt.RegisteredClaims
+}
+
+// UserID parses the sub claim
+func (c *TokenClaims) UserID() (int64, bool) {
+	userID, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, false
 	}
 
-	parseToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return userID, true
+}
+
+// TokenOptions holds the registered claims settings used to issue and verify tokens
+type TokenOptions struct {
+	Issuer    string
+	Audience  []string
+	TTL       time.Duration
+	ClockSkew time.Duration
+}
+
+var (
+	tokenOptions   = DefaultTokenOptions()
+	tokenOptionsMu sync.RWMutex
 
-	signedToken, _ := parseToken.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	errUnknownTokenKey = errors.New("unknown token key")
+)
 
-	return signedToken
+// DefaultTokenOptions returns the token options used when nothing is configured
+func DefaultTokenOptions() TokenOptions {
+	return TokenOptions{
+		Issuer:    "codepair-sinarmas",
+		Audience:  []string{"codepair-sinarmas"},
+		TTL:       24 * time.Hour,
+		ClockSkew: 30 * time.Second,
+	}
 }
 
-func VerifyToken(tokenString string) (interface{}, error) {
-	errResponse := errors.New("Token-Invalid")
+// SetTokenOptions to replace the active token options
+func SetTokenOptions(opt TokenOptions) {
+	tokenOptionsMu.Lock()
+	defer tokenOptionsMu.Unlock()
+
+	tokenOptions = opt
+}
+
+// GetTokenOptions returns the active token options
+func GetTokenOptions() TokenOptions {
+	tokenOptionsMu.RLock()
+	defer tokenOptionsMu.RUnlock()
+
+	return tokenOptions
+}
+
+func GenerateToken(id int64, email, name, role string, tokenVersion int64) (string, serror.SError) {
+	opt := GetTokenOptions()
+	now := time.Now()
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", serror.NewFromErrorc(err, "[helper][GenerateToken] Failed to generate token ID")
+	}
+
+	claims := TokenClaims{
+		Name:    name,
+		Email:   email,
+		Role:    role,
+		Version: tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			Subject:   strconv.FormatInt(id, 10),
+			Issuer:    opt.Issuer,
+			Audience:  opt.Audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(opt.TTL)),
+		},
+	}
+
+	key := GetTokenKeySet().SigningKey()
+	if key == nil {
+		return "", serror.New("[helper][GenerateToken] No token signing key available")
+	}
+
+	parseToken := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	parseToken.Header["kid"] = key.ID
+
+	signedToken, err := parseToken.SignedString(key.signer)
+	if err != nil {
+		return "", serror.NewFromErrorc(err, "[helper][GenerateToken] Failed to sign token")
+	}
+
+	return signedToken, nil
+}
 
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errResponse
+// VerifyToken checks the signature and registered claims, failures carry one of the ERR_TOKEN_* keys
+func VerifyToken(tokenString string) (*TokenClaims, serror.SError) {
+	if tokenString == "" {
+		return nil, ERR_TOKEN_MISSING.New()
+	}
+
+	opt := GetTokenOptions()
+	keySet := GetTokenKeySet()
+
+	// only the configured algorithm is accepted, HS256 never verifies a token while asymmetric keys are in use
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{keySet.Algorithm()}),
+		jwt.WithLeeway(opt.ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+
+	if opt.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(opt.Issuer))
+	}
+
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, ok := keySet.VerificationKey(kid)
+		if !ok {
+			return nil, errUnknownTokenKey
 		}
 
-		return []byte(os.Getenv("SECRET_KEY")), nil
-	})
+		if t.Method.Alg() != signingMethod(key.Algorithm).Alg() {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
 
+		return key.public, nil
+	}, parserOptions...)
 	if err != nil {
-		return nil, errResponse
+		return nil, tokenError(err)
+	}
+
+	// jwt.WithAudience keeps a single audience, the claim is matched against every configured one here
+	if len(opt.Audience) > 0 && !hasAudience(claims.Audience, opt.Audience) {
+		return nil, ERR_TOKEN_INVALID_AUDIENCE.Newc("Token audience is not accepted")
+	}
+
+	if _, ok := claims.UserID(); !ok {
+		return nil, ERR_TOKEN_INVALID_CLAIMS.Newc("Token subject is invalid")
 	}
 
-	if _, ok := token.Claims.(jwt.MapClaims); !ok && !token.Valid {
-		return nil, errResponse
+	return claims, nil
+}
+
+// private
+
+// hasAudience reports whether the aud claim holds at least one of the accepted audiences
+func hasAudience(claim jwt.ClaimStrings, accepted []string) bool {
+	for _, v := range claim {
+		for _, aud := range accepted {
+			if v == aud {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func tokenError(err error) serror.SError {
+	key := ERR_TOKEN_INVALID
+
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		key = ERR_TOKEN_MALFORMED
+	case errors.Is(err, errUnknownTokenKey):
+		key = ERR_TOKEN_UNKNOWN_KEY
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		key = ERR_TOKEN_INVALID_SIGNATURE
+	case errors.Is(err, jwt.ErrTokenExpired):
+		key = ERR_TOKEN_EXPIRED
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		key = ERR_TOKEN_NOT_YET_VALID
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		key = ERR_TOKEN_INVALID_ISSUER
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		key = ERR_TOKEN_INVALID_AUDIENCE
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing), errors.Is(err, jwt.ErrTokenInvalidClaims):
+		key = ERR_TOKEN_INVALID_CLAIMS
+	}
+
+	return key.Newc(err.Error())
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case TokenAlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case TokenAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
 	}
 
-	return token.Claims.(jwt.MapClaims), nil
+	return jwt.SigningMethodHS256
 }
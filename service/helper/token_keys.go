@@ -0,0 +1,458 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/pkg/utils/utpath"
+)
+
+const (
+	TokenAlgorithmHS256 = "HS256"
+	TokenAlgorithmRS256 = "RS256"
+	TokenAlgorithmEdDSA = "EdDSA"
+)
+
+// TokenKey is a single token signing key identified by its kid
+type TokenKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	// RetireAt is when the key stops verifying tokens, zero means it is still the signing key
+	RetireAt time.Time
+
+	signer crypto.PrivateKey
+	public crypto.PublicKey
+}
+
+// TokenKeySetOptions type
+type TokenKeySetOptions struct {
+	Algorithm        string
+	Secret           string
+	Dir              string
+	RotationInterval time.Duration
+	Overlap          time.Duration
+}
+
+// TokenKeySet holds the signing key and every key still allowed to verify tokens
+type TokenKeySet struct {
+	mu   sync.RWMutex
+	opt  TokenKeySetOptions
+	keys []*TokenKey
+}
+
+// JWK is a single JSON web key
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON web key set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	tokenKeySet     *TokenKeySet
+	tokenKeySetOnce sync.Once
+)
+
+// SetTokenKeySet to replace the key set used to sign and verify tokens
+func SetTokenKeySet(keySet *TokenKeySet) {
+	if keySet == nil {
+		panic("Null token key set")
+	}
+
+	tokenKeySetOnce.Do(func() {})
+	tokenKeySet = keySet
+}
+
+// GetTokenKeySet returns the active key set, an in-memory EdDSA key set is created when nothing is configured
+func GetTokenKeySet() *TokenKeySet {
+	tokenKeySetOnce.Do(func() {
+		keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA})
+		if errx != nil {
+			errx.Panic()
+		}
+
+		tokenKeySet = keySet
+	})
+
+	return tokenKeySet
+}
+
+// NewTokenKeySet loads the keys stored in opt.Dir and makes sure there is a signing key
+func NewTokenKeySet(opt TokenKeySetOptions) (keySet *TokenKeySet, errx serror.SError) {
+	switch opt.Algorithm {
+	case TokenAlgorithmRS256, TokenAlgorithmEdDSA:
+	case TokenAlgorithmHS256:
+		if opt.Secret == "" {
+			return nil, serror.New("HS256 token signing requires a secret")
+		}
+
+	default:
+		return nil, serror.Newf("Unsupported token algorithm %s", opt.Algorithm)
+	}
+
+	if opt.Overlap <= 0 {
+		opt.Overlap = 48 * time.Hour
+	}
+
+	keySet = &TokenKeySet{opt: opt}
+
+	if opt.Algorithm == TokenAlgorithmHS256 {
+		keySet.keys = []*TokenKey{{
+			ID:        "default",
+			Algorithm: TokenAlgorithmHS256,
+			CreatedAt: time.Now(),
+			signer:    []byte(opt.Secret),
+			public:    []byte(opt.Secret),
+		}}
+		return keySet, nil
+	}
+
+	errx = keySet.Reload()
+	if errx != nil {
+		return nil, errx
+	}
+
+	if keySet.SigningKey() == nil {
+		errx = keySet.Rotate()
+		if errx != nil {
+			return nil, errx
+		}
+	}
+
+	return keySet, nil
+}
+
+// Algorithm returns the signing algorithm of the key set
+func (ox *TokenKeySet) Algorithm() string {
+	return ox.opt.Algorithm
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ox *TokenKeySet) SigningKey() *TokenKey {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	for _, v := range ox.keys {
+		if v.RetireAt.IsZero() {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// VerificationKey returns the key identified by kid when it is still allowed to verify tokens
+func (ox *TokenKeySet) VerificationKey(kid string) (key *TokenKey, ok bool) {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	now := time.Now()
+	for _, v := range ox.keys {
+		if v.ID == kid && (v.RetireAt.IsZero() || now.Before(v.RetireAt)) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// Rotate generates a new signing key, the previous one keeps verifying tokens for the overlap duration
+func (ox *TokenKeySet) Rotate() (errx serror.SError) {
+	if ox.opt.Algorithm == TokenAlgorithmHS256 {
+		return serror.New("HS256 keys are derived from the secret and cannot be rotated")
+	}
+
+	key, errx := generateTokenKey(ox.opt.Algorithm)
+	if errx != nil {
+		return
+	}
+
+	if ox.opt.Dir != "" {
+		errx = writeTokenKey(ox.opt.Dir, key)
+		if errx != nil {
+			return
+		}
+	}
+
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	ox.retire(key.CreatedAt)
+	ox.keys = append([]*TokenKey{key}, ox.keys...)
+	ox.prune()
+
+	return nil
+}
+
+// Reload reads the keys stored in the key directory, the newest one becomes the signing key
+func (ox *TokenKeySet) Reload() (errx serror.SError) {
+	if ox.opt.Dir == "" {
+		return nil
+	}
+
+	if !utpath.IsExists(ox.opt.Dir) {
+		if err := os.MkdirAll(ox.opt.Dir, 0700); err != nil {
+			return serror.NewFromErrorc(err, "Failed to create token key directory")
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(ox.opt.Dir, "*.pem"))
+	if err != nil {
+		return serror.NewFromErrorc(err, "Failed to list token keys")
+	}
+
+	var keys []*TokenKey
+	for _, file := range files {
+		key, errx := readTokenKey(file, ox.opt.Algorithm)
+		if errx != nil {
+			return errx
+		}
+
+		if key != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	ox.mu.Lock()
+	defer ox.mu.Unlock()
+
+	for i, v := range keys {
+		if i > 0 {
+			v.RetireAt = keys[i-1].CreatedAt.Add(ox.opt.Overlap)
+		}
+	}
+
+	ox.keys = keys
+	ox.prune()
+
+	return nil
+}
+
+// RotateIfDue rotates the signing key once it is older than the rotation interval
+func (ox *TokenKeySet) RotateIfDue() (rotated bool, errx serror.SError) {
+	if ox.opt.Algorithm == TokenAlgorithmHS256 || ox.opt.RotationInterval <= 0 {
+		return
+	}
+
+	errx = ox.Reload()
+	if errx != nil {
+		return
+	}
+
+	key := ox.SigningKey()
+	if key != nil && time.Since(key.CreatedAt) < ox.opt.RotationInterval {
+		return
+	}
+
+	errx = ox.Rotate()
+	return errx == nil, errx
+}
+
+// StartRotation runs RotateIfDue every checkInterval until stop is closed
+func (ox *TokenKeySet) StartRotation(checkInterval time.Duration, stop <-chan struct{}, onError func(errx serror.SError)) {
+	if ox.opt.Algorithm == TokenAlgorithmHS256 || ox.opt.RotationInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+
+			case <-ticker.C:
+				if _, errx := ox.RotateIfDue(); errx != nil && onError != nil {
+					onError(errx)
+				}
+			}
+		}
+	}()
+}
+
+// JWKS returns the public keys that are still allowed to verify tokens
+func (ox *TokenKeySet) JWKS() JWKS {
+	ox.mu.RLock()
+	defer ox.mu.RUnlock()
+
+	res := JWKS{Keys: []JWK{}}
+	now := time.Now()
+	for _, v := range ox.keys {
+		if !v.RetireAt.IsZero() && !now.Before(v.RetireAt) {
+			continue
+		}
+
+		switch pub := v.public.(type) {
+		case *rsa.PublicKey:
+			res.Keys = append(res.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     v.ID,
+				Use:       "sig",
+				Algorithm: v.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+
+		case ed25519.PublicKey:
+			res.Keys = append(res.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     v.ID,
+				Use:       "sig",
+				Algorithm: v.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	return res
+}
+
+// private
+
+func (ox *TokenKeySet) retire(at time.Time) {
+	for _, v := range ox.keys {
+		if v.RetireAt.IsZero() {
+			v.RetireAt = at.Add(ox.opt.Overlap)
+		}
+	}
+}
+
+func (ox *TokenKeySet) prune() {
+	now := time.Now()
+	keys := ox.keys[:0]
+	for _, v := range ox.keys {
+		if v.RetireAt.IsZero() || now.Before(v.RetireAt) {
+			keys = append(keys, v)
+		}
+	}
+
+	ox.keys = keys
+}
+
+func generateTokenKey(algorithm string) (key *TokenKey, errx serror.SError) {
+	key = &TokenKey{
+		Algorithm: algorithm,
+		CreatedAt: time.Now(),
+	}
+
+	switch algorithm {
+	case TokenAlgorithmRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, serror.NewFromErrorc(err, "Failed to generate RSA token key")
+		}
+		key.signer, key.public = priv, &priv.PublicKey
+
+	case TokenAlgorithmEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, serror.NewFromErrorc(err, "Failed to generate Ed25519 token key")
+		}
+		key.signer, key.public = priv, pub
+
+	default:
+		return nil, serror.Newf("Unsupported token algorithm %s", algorithm)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, serror.NewFromErrorc(err, "Failed to generate token key ID")
+	}
+	key.ID = key.CreatedAt.UTC().Format("20060102150405") + "-" + base64.RawURLEncoding.EncodeToString(id)
+
+	return key, nil
+}
+
+func writeTokenKey(dir string, key *TokenKey) serror.SError {
+	der, err := x509.MarshalPKCS8PrivateKey(key.signer)
+	if err != nil {
+		return serror.NewFromErrorc(err, "Failed to encode token key")
+	}
+
+	byt := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	path := filepath.Join(dir, key.ID+".pem")
+	if err := os.WriteFile(path, byt, 0600); err != nil {
+		return serror.NewFromErrorc(err, "Failed to write token key")
+	}
+
+	return nil
+}
+
+func readTokenKey(path string, algorithm string) (key *TokenKey, errx serror.SError) {
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, serror.NewFromErrorc(err, "Failed to read token key")
+	}
+
+	block, _ := pem.Decode(byt)
+	if block == nil {
+		return nil, serror.Newf("Invalid token key %s", path)
+	}
+
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, serror.NewFromErrorc(err, "Failed to parse token key")
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, serror.NewFromErrorc(err, "Failed to stat token key")
+	}
+
+	key = &TokenKey{
+		ID:        strings.TrimSuffix(filepath.Base(path), ".pem"),
+		Algorithm: algorithm,
+		CreatedAt: stat.ModTime(),
+		signer:    priv,
+	}
+
+	switch v := priv.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != TokenAlgorithmRS256 {
+			return nil, nil
+		}
+		key.public = &v.PublicKey
+
+	case ed25519.PrivateKey:
+		if algorithm != TokenAlgorithmEdDSA {
+			return nil, nil
+		}
+		key.public = v.Public()
+
+	default:
+		return nil, serror.Newf("Unsupported token key type in %s", path)
+	}
+
+	return key, nil
+}
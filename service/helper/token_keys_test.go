@@ -0,0 +1,128 @@
+package helper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenKeySet_SignAndVerify(t *testing.T) {
+	type testCase struct {
+		name      string
+		algorithm string
+		keyType   string
+	}
+
+	testCases := []testCase{
+		{name: "RS256", algorithm: TokenAlgorithmRS256, keyType: "RSA"},
+		{name: "EdDSA", algorithm: TokenAlgorithmEdDSA, keyType: "OKP"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: tc.algorithm, Overlap: time.Hour})
+			assert.Nil(t, errx)
+			SetTokenKeySet(keySet)
+
+			oldToken, errx := GenerateToken(1, "john@example.com", "John Doe", "user", 0)
+			assert.Nil(t, errx)
+			oldKid := keySet.SigningKey().ID
+
+			assert.Nil(t, keySet.Rotate())
+			assert.NotEqual(t, oldKid, keySet.SigningKey().ID)
+
+			newToken, errx := GenerateToken(1, "john@example.com", "John Doe", "user", 0)
+			assert.Nil(t, errx)
+
+			// tokens signed before the rotation stay valid during the overlap
+			for _, token := range []string{oldToken, newToken} {
+				claims, errx := VerifyToken(token)
+				assert.Nil(t, errx)
+				assert.Equal(t, "john@example.com", claims.Email)
+			}
+
+			jwks := keySet.JWKS()
+			assert.Len(t, jwks.Keys, 2)
+			for _, v := range jwks.Keys {
+				assert.Equal(t, tc.keyType, v.KeyType)
+				assert.Equal(t, tc.algorithm, v.Algorithm)
+				assert.Equal(t, "sig", v.Use)
+			}
+		})
+	}
+}
+
+func TestTokenKeySet_RetiredKey(t *testing.T) {
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA, Overlap: time.Nanosecond})
+	assert.Nil(t, errx)
+	SetTokenKeySet(keySet)
+
+	token, errx := GenerateToken(1, "john@example.com", "John Doe", "user", 0)
+	assert.Nil(t, errx)
+
+	assert.Nil(t, keySet.Rotate())
+	time.Sleep(time.Millisecond)
+
+	_, errx = VerifyToken(token)
+	assert.Equal(t, string(ERR_TOKEN_UNKNOWN_KEY), errx.Key())
+	assert.Len(t, keySet.JWKS().Keys, 1)
+}
+
+func TestTokenKeySet_UnknownKid(t *testing.T) {
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA})
+	assert.Nil(t, errx)
+	SetTokenKeySet(keySet)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"id": 1})
+	token.Header["kid"] = "unknown"
+	signed, err := token.SignedString(keySet.SigningKey().signer)
+	assert.Nil(t, err)
+
+	_, errx = VerifyToken(signed)
+	assert.Equal(t, string(ERR_TOKEN_UNKNOWN_KEY), errx.Key())
+}
+
+func TestTokenKeySet_HS256(t *testing.T) {
+	rsaKeySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmRS256})
+	assert.Nil(t, errx)
+	SetTokenKeySet(rsaKeySet)
+
+	// a kid pointing at an asymmetric key must not be accepted with an HMAC signature
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"})
+	forged.Header["kid"] = rsaKeySet.SigningKey().ID
+	signed, err := forged.SignedString([]byte("secret"))
+	assert.Nil(t, err)
+
+	_, errx = VerifyToken(signed)
+	assert.Equal(t, string(ERR_TOKEN_INVALID_SIGNATURE), errx.Key())
+
+	// shared secrets are never published
+	hmacKeySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmHS256, Secret: "secret"})
+	assert.Nil(t, errx)
+	SetTokenKeySet(hmacKeySet)
+	assert.Empty(t, hmacKeySet.JWKS().Keys)
+
+	token, errx := GenerateToken(1, "john@example.com", "John Doe", "user", 0)
+	assert.Nil(t, errx)
+
+	_, errx = VerifyToken(token)
+	assert.Nil(t, errx)
+}
+
+func TestTokenKeySet_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmRS256, Dir: dir})
+	assert.Nil(t, errx)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 1)
+
+	reloaded, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmRS256, Dir: dir})
+	assert.Nil(t, errx)
+	assert.Equal(t, keySet.SigningKey().ID, reloaded.SigningKey().ID)
+	assert.Equal(t, keySet.JWKS(), reloaded.JWKS())
+}
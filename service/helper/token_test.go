@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateToken_Claims(t *testing.T) {
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA})
+	assert.Nil(t, errx)
+	SetTokenKeySet(keySet)
+	SetTokenOptions(DefaultTokenOptions())
+
+	first, errx := GenerateToken(42, "john@example.com", "John Doe", "admin", 3)
+	assert.Nil(t, errx)
+
+	second, errx := GenerateToken(42, "john@example.com", "John Doe", "admin", 3)
+	assert.Nil(t, errx)
+
+	claims, errx := VerifyToken(first)
+	assert.Nil(t, errx)
+
+	userID, ok := claims.UserID()
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), userID)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, "admin", claims.Role)
+	assert.Equal(t, int64(3), claims.Version)
+	assert.Equal(t, DefaultTokenOptions().Issuer, claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings(DefaultTokenOptions().Audience), claims.Audience)
+	assert.NotEmpty(t, claims.ID)
+	assert.NotNil(t, claims.IssuedAt)
+	assert.NotNil(t, claims.NotBefore)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), claims.ExpiresAt.Time, time.Minute)
+
+	otherClaims, errx := VerifyToken(second)
+	assert.Nil(t, errx)
+	assert.NotEqual(t, claims.ID, otherClaims.ID)
+}
+
+func TestVerifyToken_Errors(t *testing.T) {
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA})
+	assert.Nil(t, errx)
+	SetTokenKeySet(keySet)
+	SetTokenOptions(DefaultTokenOptions())
+
+	sign := func(claims jwt.Claims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = keySet.SigningKey().ID
+		signed, err := token.SignedString(keySet.SigningKey().signer)
+		assert.Nil(t, err)
+		return signed
+	}
+
+	valid := func(modify func(c *jwt.RegisteredClaims)) string {
+		now := time.Now()
+		claims := TokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			Issuer:    DefaultTokenOptions().Issuer,
+			Audience:  DefaultTokenOptions().Audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		}}
+		modify(&claims.RegisteredClaims)
+		return sign(claims)
+	}
+
+	// tokens issued before the migration carry no kid
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"id": 1}).SignedString(keySet.SigningKey().signer)
+	assert.Nil(t, err)
+
+	type testCase struct {
+		name     string
+		token    string
+		expected ErrorKey
+	}
+
+	testCases := []testCase{
+		{
+			name:     "empty token",
+			token:    "",
+			expected: ERR_TOKEN_MISSING,
+		},
+		{
+			name:     "malformed token",
+			token:    "not-a-token",
+			expected: ERR_TOKEN_MALFORMED,
+		},
+		{
+			name: "expired token",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			}),
+			expected: ERR_TOKEN_EXPIRED,
+		},
+		{
+			name: "not valid yet",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+			}),
+			expected: ERR_TOKEN_NOT_YET_VALID,
+		},
+		{
+			name: "missing expiry",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.ExpiresAt = nil
+			}),
+			expected: ERR_TOKEN_INVALID_CLAIMS,
+		},
+		{
+			name: "wrong issuer",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.Issuer = "someone-else"
+			}),
+			expected: ERR_TOKEN_INVALID_ISSUER,
+		},
+		{
+			name: "wrong audience",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.Audience = jwt.ClaimStrings{"another-service"}
+			}),
+			expected: ERR_TOKEN_INVALID_AUDIENCE,
+		},
+		{
+			name: "missing audience",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.Audience = nil
+			}),
+			expected: ERR_TOKEN_INVALID_AUDIENCE,
+		},
+		{
+			name: "invalid subject",
+			token: valid(func(c *jwt.RegisteredClaims) {
+				c.Subject = "abc"
+			}),
+			expected: ERR_TOKEN_INVALID_CLAIMS,
+		},
+		{
+			name:     "legacy token without kid",
+			token:    legacyToken,
+			expected: ERR_TOKEN_UNKNOWN_KEY,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, errx := VerifyToken(tc.token)
+			assert.Nil(t, claims)
+			if assert.NotNil(t, errx) {
+				assert.Equal(t, string(tc.expected), errx.Key())
+				assert.Equal(t, http.StatusUnauthorized, errx.Code())
+			}
+		})
+	}
+}
+
+func TestVerifyToken_ClockSkew(t *testing.T) {
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA})
+	assert.Nil(t, errx)
+	SetTokenKeySet(keySet)
+
+	opt := DefaultTokenOptions()
+	opt.TTL = -10 * time.Second
+	SetTokenOptions(opt)
+	defer SetTokenOptions(DefaultTokenOptions())
+
+	token, errx := GenerateToken(1, "john@example.com", "John Doe", "user", 0)
+	assert.Nil(t, errx)
+
+	_, errx = VerifyToken(token)
+	assert.Nil(t, errx)
+
+	opt.ClockSkew = 0
+	SetTokenOptions(opt)
+
+	_, errx = VerifyToken(token)
+	assert.Equal(t, string(ERR_TOKEN_EXPIRED), errx.Key())
+}
+
+func TestVerifyToken_Audiences(t *testing.T) {
+	keySet, errx := NewTokenKeySet(TokenKeySetOptions{Algorithm: TokenAlgorithmEdDSA})
+	assert.Nil(t, errx)
+	SetTokenKeySet(keySet)
+
+	opt := DefaultTokenOptions()
+	opt.Audience = []string{"codepair-web", "codepair-mobile"}
+	SetTokenOptions(opt)
+	defer SetTokenOptions(DefaultTokenOptions())
+
+	token, errx := GenerateToken(1, "john@example.com", "John Doe", "user", 0)
+	assert.Nil(t, errx)
+
+	// a token meant for any of the configured audiences is accepted
+	for _, aud := range opt.Audience {
+		SetTokenOptions(TokenOptions{Issuer: opt.Issuer, Audience: []string{aud, "another-service"}, TTL: opt.TTL, ClockSkew: opt.ClockSkew})
+
+		_, errx = VerifyToken(token)
+		assert.Nil(t, errx)
+	}
+
+	SetTokenOptions(TokenOptions{Issuer: opt.Issuer, Audience: []string{"another-service"}, TTL: opt.TTL, ClockSkew: opt.ClockSkew})
+
+	_, errx = VerifyToken(token)
+	assert.Equal(t, string(ERR_TOKEN_INVALID_AUDIENCE), errx.Key())
+}
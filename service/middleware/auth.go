@@ -1,108 +1,111 @@
 package middlewares
 
 import (
-	"net/http"
-	"strconv"
-	"time"
+	"strings"
 
 	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	api "codepair-sinarmas/service"
 	"codepair-sinarmas/service/helper"
 
-	"strings"
-
-	log "github.com/sirupsen/logrus"
-
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
-func Auth() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		token := ctx.Request.Header.Get("Authorization")
-
-		if token == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: "Token Not Found",
-				Error:   "Unauthorized",
-			})
+const HeaderAPIKey = "X-API-Key"
 
+// Auth accepts either a bearer token or an API key sent in the X-API-Key header
+func Auth(userUsecase api.UserUsecase, apiKeyUsecase api.APIKeyUsecase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if key := ctx.GetHeader(HeaderAPIKey); key != "" {
+			authAPIKey(ctx, apiKeyUsecase, key)
 			return
 		}
 
-		bearer := strings.HasPrefix(token, "Bearer")
-
-		if !bearer {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: "Bearer Not Found",
-				Error:   "Unauthorized",
-			})
-
+		tokenStr, errx := bearerToken(ctx.Request.Header.Get("Authorization"))
+		if errx != nil {
+			abortWithError(ctx, errx)
 			return
 		}
-		tokenStr := strings.Split(token, "Bearer ")[1]
-
-		if tokenStr == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: "Token STR Not Found",
-				Error:   "Unauthorized",
-			})
 
+		claims, errx := helper.VerifyToken(tokenStr)
+		if errx != nil {
+			abortWithError(ctx, errx)
 			return
 		}
 
-		claims, err := helper.VerifyToken(tokenStr)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: err.Error(),
-				Error:   "Unauthorized",
-			})
+		userID, _ := claims.UserID()
 
-			log.Errorln("ERROR:", err)
+		_, errx = userUsecase.ValidateSession(ctx.Request.Context(), userID, claims.Version)
+		if errx != nil {
+			abortWithError(ctx, errx)
 			return
 		}
 
-		var data = claims.(jwt.MapClaims)
-		userId := data["id"].(float64)
-		strUserId := strconv.FormatFloat(userId, 'f', -1, 64)
+		helper.SetPrincipal(ctx, &helper.Principal{
+			Type:       helper.PrincipalTypeUser,
+			UserID:     userID,
+			Name:       claims.Name,
+			Email:      claims.Email,
+			Roles:      []string{claims.Role},
+			AuthMethod: helper.AuthMethodJWT,
+			TokenID:    claims.ID,
+			ExpiresAt:  claims.ExpiresAt.Time,
+		})
 
-		ctx.Set("user_id", strUserId)
-		ctx.Set("name", data["name"])
-		ctx.Set("email", data["email"])
-		ctx.Set("exp", data["exp"])
-		ctx.Set("exp_date", data["exp_date"])
+		ctx.Next()
+	}
+}
 
-		if data["exp_date"] == nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: "Invalid token",
-				Error:   "Unauthorized",
-			})
+func authAPIKey(ctx *gin.Context, apiKeyUsecase api.APIKeyUsecase, key string) {
+	apiKey, owner, errx := apiKeyUsecase.ValidateAPIKey(ctx.Request.Context(), key)
+	if errx != nil {
+		abortWithError(ctx, errx)
+		return
+	}
 
-			return
-		}
+	principal := &helper.Principal{
+		Type:       helper.PrincipalTypeServiceAccount,
+		Name:       apiKey.ServiceAccount,
+		Roles:      []string{models.RoleService},
+		Scopes:     apiKey.Scopes,
+		AuthMethod: helper.AuthMethodAPIKey,
+		TokenID:    apiKey.Prefix,
+		APIKeyID:   apiKey.ID,
+		ExpiresAt:  apiKey.ExpiresAt,
+	}
 
-		timeNow := time.Now()
-		expiredTime := data["exp_date"].(string)
+	// the owner only gives the key an identity, the key is limited to the service role and its scopes
+	if owner != nil {
+		principal.Type = helper.PrincipalTypeUser
+		principal.UserID = owner.UserID
+		principal.Name = owner.Name
+		principal.Email = owner.Email
+	}
 
-		parsed, err := time.Parse(time.RFC3339, expiredTime)
-		if err != nil {
-			log.Errorln("ERROR:", err)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: err.Error(),
-				Error:   "Unauthorized",
-			})
+	helper.SetPrincipal(ctx, principal)
 
-			return
-		}
+	ctx.Next()
+}
 
-		if timeNow.After(parsed) {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, models.ResponseError{
-				Message: "Token has expired, please login again",
-				Error:   "Unauthorized",
-			})
+func bearerToken(header string) (string, serror.SError) {
+	if header == "" {
+		return "", helper.ERR_TOKEN_MISSING.New()
+	}
 
-			return
-		}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", helper.ERR_TOKEN_MALFORMED.Newc("Bearer not found")
+	}
 
-		ctx.Next()
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", helper.ERR_TOKEN_MISSING.New()
 	}
+
+	return token, nil
+}
+
+func abortWithError(ctx *gin.Context, errx serror.SError) {
+	errx = helper.ErrorContextFromContext(ctx.Request.Context(), helper.LogLayerHTTP).SignError(errx)
+	AbortWithError(ctx, errx, nil)
 }
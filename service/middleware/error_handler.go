@@ -1,16 +1,17 @@
 package middlewares
 
 import (
+	"codepair-sinarmas/service/helper"
+
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
 )
 
-func ErrorHandler(logger *log.Logger) gin.HandlerFunc {
+func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		for _, ginErr := range c.Errors {
-			log.Errorln(ginErr)
+			helper.LoggerFromContext(c.Request.Context(), helper.LogLayerHTTP).Err(ginErr.Err)
 		}
 	}
 }
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WantsProblemJSON reports whether the client negotiated problem+json, it is the default unless
+// the Accept header asks for plain application/json before it
+func WantsProblemJSON(ctx *gin.Context) bool {
+	return ctx.NegotiateFormat(models.MediaTypeProblemJSON, gin.MIMEJSON) == models.MediaTypeProblemJSON
+}
+
+// AbortWithError answers with errx as problem+json or as the legacy envelope depending on the Accept header,
+// validationErrors are rendered as the errors extension; internal errors never expose their message
+func AbortWithError(ctx *gin.Context, errx serror.SError, validationErrors interface{}) {
+	var (
+		reqCtx             = ctx.Request.Context()
+		code, key, message = helper.ErrorBody(errx, helper.ParseAcceptLanguage(ctx.GetHeader("Accept-Language")))
+		requestID          = helper.RequestIDFromContext(reqCtx)
+		internal           = code == http.StatusInternalServerError
+		legacyRequestID    string
+	)
+
+	ctx.Header("Vary", "Accept")
+
+	if WantsProblemJSON(ctx) {
+		problem := models.ProblemDetails{
+			Type:      helper.ProblemType(key),
+			Title:     http.StatusText(code),
+			Status:    code,
+			Detail:    message,
+			Instance:  ctx.Request.URL.Path,
+			Key:       key,
+			RequestID: requestID,
+			TraceID:   helper.TraceIDFromContext(reqCtx),
+			Errors:    validationErrors,
+		}
+
+		ctx.Header("Content-Type", models.MediaTypeProblemJSON)
+		ctx.AbortWithStatusJSON(code, problem)
+		return
+	}
+
+	if internal {
+		legacyRequestID = requestID
+	}
+
+	ctx.AbortWithStatusJSON(code, models.ResponseError{
+		Code:      code,
+		Key:       key,
+		Message:   message,
+		Error:     validationErrors,
+		RequestID: legacyRequestID,
+	})
+}
@@ -0,0 +1,159 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbortWithError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestContext())
+	r.GET("/users/:id", func(ctx *gin.Context) {
+		AbortWithError(ctx, helper.ERR_USER_NOT_FOUND.New(), nil)
+	})
+	r.GET("/internal", func(ctx *gin.Context) {
+		AbortWithError(ctx, serror.NewFromError(errors.New("pq: password authentication failed")), nil)
+	})
+	r.POST("/register", func(ctx *gin.Context) {
+		AbortWithError(ctx, helper.ERR_VALIDATION.New(), map[string]string{"email": "email is required"})
+	})
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		accept         string
+		language       string
+		expectedCode   int
+		expectedType   string
+		expectedKey    string
+		expectedDetail string
+		expectedErrors interface{}
+		expectProblem  bool
+	}{
+		{
+			name:           "problem by default",
+			method:         http.MethodGet,
+			path:           "/users/7",
+			expectedCode:   http.StatusNotFound,
+			expectedKey:    string(helper.ERR_USER_NOT_FOUND),
+			expectedDetail: "User not found",
+			expectProblem:  true,
+		},
+		{
+			name:           "problem requested in indonesian",
+			method:         http.MethodGet,
+			path:           "/users/7",
+			accept:         models.MediaTypeProblemJSON,
+			language:       "id-ID",
+			expectedCode:   http.StatusNotFound,
+			expectedKey:    string(helper.ERR_USER_NOT_FOUND),
+			expectedDetail: "Pengguna tidak ditemukan",
+			expectProblem:  true,
+		},
+		{
+			name:           "problem hides internal message",
+			method:         http.MethodGet,
+			path:           "/internal",
+			accept:         "*/*",
+			expectedCode:   http.StatusInternalServerError,
+			expectedKey:    string(helper.ERR_INTERNAL),
+			expectedDetail: "Internal server error",
+			expectProblem:  true,
+		},
+		{
+			name:           "problem validation errors extension",
+			method:         http.MethodPost,
+			path:           "/register",
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedKey:    string(helper.ERR_VALIDATION),
+			expectedDetail: "Validation error",
+			expectedErrors: map[string]interface{}{"email": "email is required"},
+			expectProblem:  true,
+		},
+		{
+			name:           "legacy envelope",
+			method:         http.MethodGet,
+			path:           "/users/7",
+			accept:         gin.MIMEJSON,
+			expectedCode:   http.StatusNotFound,
+			expectedKey:    string(helper.ERR_USER_NOT_FOUND),
+			expectedDetail: "User not found",
+		},
+		{
+			name:           "legacy hides internal message",
+			method:         http.MethodGet,
+			path:           "/internal",
+			accept:         gin.MIMEJSON,
+			expectedCode:   http.StatusInternalServerError,
+			expectedKey:    string(helper.ERR_INTERNAL),
+			expectedDetail: "Internal server error",
+		},
+		{
+			name:           "legacy validation errors",
+			method:         http.MethodPost,
+			path:           "/register",
+			accept:         gin.MIMEJSON,
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedKey:    string(helper.ERR_VALIDATION),
+			expectedDetail: "Validation error",
+			expectedErrors: map[string]interface{}{"email": "email is required"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set(HeaderRequestID, "req-1")
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			if tc.language != "" {
+				req.Header.Set("Accept-Language", tc.language)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			assert.NotContains(t, w.Body.String(), "pq: password authentication failed")
+
+			if tc.expectProblem {
+				assert.Equal(t, models.MediaTypeProblemJSON, w.Header().Get("Content-Type"))
+
+				var res models.ProblemDetails
+				assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, helper.ProblemType(tc.expectedKey), res.Type)
+				assert.Equal(t, http.StatusText(tc.expectedCode), res.Title)
+				assert.Equal(t, tc.expectedCode, res.Status)
+				assert.Equal(t, tc.expectedDetail, res.Detail)
+				assert.Equal(t, tc.path, res.Instance)
+				assert.Equal(t, tc.expectedKey, res.Key)
+				assert.Equal(t, "req-1", res.RequestID)
+				assert.Equal(t, tc.expectedErrors, res.Errors)
+				return
+			}
+
+			assert.Contains(t, w.Header().Get("Content-Type"), gin.MIMEJSON)
+
+			var res models.ResponseError
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, tc.expectedCode, res.Code)
+			assert.Equal(t, tc.expectedKey, res.Key)
+			assert.Equal(t, tc.expectedDetail, res.Message)
+			assert.Equal(t, tc.expectedErrors, res.Error)
+		})
+	}
+}
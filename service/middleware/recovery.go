@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery converts panics into a logged SError and answers with a 500 carrying the request ID
+func Recovery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			errx := serror.NewFromPanic(rec)
+			errx.SetKey(string(helper.ERR_PANIC))
+			errx.Sign(helper.ErrorContextFromContext(ctx.Request.Context(), helper.LogLayerHTTP))
+			errx.AddCommentf("[middleware][Recovery] Recovered from panic, [method: %s, route: %s]", ctx.Request.Method, ctx.FullPath())
+
+			log := helper.LoggerFromContext(ctx.Request.Context(), helper.LogLayerHTTP)
+
+			// the client is gone, there is nobody left to answer
+			if isBrokenPipe(rec) {
+				log.Warn(errx)
+				ctx.Abort()
+				return
+			}
+
+			log.Err(errx)
+			AbortWithError(ctx, errx, nil)
+		}()
+
+		ctx.Next()
+	}
+}
+
+func isBrokenPipe(rec interface{}) bool {
+	err, ok := rec.(error)
+	if !ok {
+		return false
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+
+	var syscallErr *os.SyscallError
+	if errors.As(opErr, &syscallErr) {
+		msg := strings.ToLower(syscallErr.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+
+	return false
+}
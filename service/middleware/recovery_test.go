@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/logger"
+	"codepair-sinarmas/pkg/serror"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type payloadRecorder struct {
+	payloads []interface{}
+}
+
+func (r *payloadRecorder) Translate(args logger.LogInterceptorTranslateArguments) string {
+	r.payloads = append(r.payloads, args.Payload)
+	return ""
+}
+
+func (r *payloadRecorder) Process(lvl logger.ErrorLevel, msg string) {}
+
+type panicTarget struct {
+	name string
+}
+
+func panicNilPointer(ctx *gin.Context) {
+	var target *panicTarget
+	ctx.String(http.StatusOK, target.name)
+}
+
+func TestRecovery(t *testing.T) {
+	record := &payloadRecorder{}
+	previous := logger.Default().Interceptor()
+	logger.SetInterceptor(record)
+	defer logger.SetInterceptor(previous)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Recovery(), RequestContext(), RequestLogger())
+	r.GET("/nil", panicNilPointer)
+	r.GET("/value", func(ctx *gin.Context) {
+		panic("unexpected state")
+	})
+
+	testCases := []struct {
+		name     string
+		path     string
+		function string
+		message  string
+	}{
+		{name: "nil pointer", path: "/nil", function: "panicNilPointer", message: "invalid memory address or nil pointer dereference"},
+		{name: "plain value", path: "/value", function: "TestRecovery.func1", message: "unexpected state"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			record.payloads = nil
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(HeaderRequestID, "req-"+tc.name)
+			req.Header.Set("Accept", gin.MIMEJSON)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+			var res models.ResponseError
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, "req-"+tc.name, res.RequestID)
+			assert.Equal(t, string(helper.ERR_PANIC), res.Key)
+			assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+			assert.Len(t, record.payloads, 1)
+			errx, ok := record.payloads[0].(serror.SError)
+			assert.True(t, ok)
+			assert.Equal(t, string(helper.ERR_PANIC), errx.Key())
+			assert.Equal(t, "req-"+tc.name, errx.Signature().RequestID)
+			assert.Equal(t, helper.LogLayerHTTP, errx.Signature().Layer)
+			assert.Equal(t, http.StatusInternalServerError, errx.Code())
+			assert.Contains(t, errx.Error(), tc.message)
+			assert.Equal(t, tc.function, errx.StackFrames()[0].Name)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"codepair-sinarmas/pkg/logger"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+func RequestContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(HeaderRequestID)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = generateRequestID()
+		}
+
+		ctx.Set("request_id", requestID)
+		ctx.Header(HeaderRequestID, requestID)
+		ctx.Request = ctx.Request.WithContext(helper.WithRequestMeta(ctx.Request.Context(), requestID, ctx.ClientIP()))
+
+		ctx.Next()
+	}
+}
+
+func generateRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger creates the request log squad and error context tagged with the request ID, route and method,
+// Auth adds the user once the principal is known
+func RequestLogger() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			requestID = helper.RequestIDFromContext(ctx.Request.Context())
+			route     = ctx.FullPath()
+		)
+
+		squad := logger.CreateSquad(ctx.Request.Context(), helper.LogLayerHTTP)
+		squad.SetTag(helper.LogTagRequestID, requestID)
+		squad.SetTag(helper.LogTagMethod, ctx.Request.Method)
+		if route != "" {
+			squad.SetTag(helper.LogTagRoute, route)
+		}
+
+		reqCtx := helper.WithLogSquad(ctx.Request.Context(), squad)
+		reqCtx = helper.WithErrorContext(reqCtx, requestID, route, ctx.Request.Method)
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RoleGuard(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, errx := helper.GetPrincipal(ctx)
+		if errx != nil {
+			abortWithError(ctx, errx)
+			return
+		}
+
+		if !principal.HasRole(roles...) {
+			abortWithError(ctx, helper.ERR_PRINCIPAL_FORBIDDEN.New())
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// ScopeGuard requires the principals authenticated with an API key to hold one of scopes,
+// bearer token principals are authorized by their roles alone
+func ScopeGuard(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, errx := helper.GetPrincipal(ctx)
+		if errx != nil {
+			abortWithError(ctx, errx)
+			return
+		}
+
+		if principal.AuthMethod == helper.AuthMethodAPIKey && !principal.HasScope(scopes...) {
+			abortWithError(ctx, helper.ERR_PRINCIPAL_FORBIDDEN.New())
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/service/helper"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAndScopeGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		admin = &helper.Principal{
+			Type:       helper.PrincipalTypeUser,
+			UserID:     1,
+			Roles:      []string{models.RoleAdmin},
+			AuthMethod: helper.AuthMethodJWT,
+		}
+		ownedKey = &helper.Principal{
+			Type:       helper.PrincipalTypeUser,
+			UserID:     1,
+			Roles:      []string{models.RoleService},
+			Scopes:     []string{models.ScopeUsersRead},
+			AuthMethod: helper.AuthMethodAPIKey,
+		}
+		serviceKey = &helper.Principal{
+			Type:       helper.PrincipalTypeServiceAccount,
+			Name:       "reporting",
+			Roles:      []string{models.RoleService},
+			Scopes:     []string{models.ScopeAuditRead},
+			AuthMethod: helper.AuthMethodAPIKey,
+		}
+	)
+
+	testCases := []struct {
+		name      string
+		principal *helper.Principal
+		path      string
+		expected  int
+	}{
+		{name: "admin without scopes", principal: admin, path: "/users", expected: http.StatusOK},
+		{name: "admin on admin only route", principal: admin, path: "/api-keys", expected: http.StatusOK},
+		{name: "key with scope", principal: ownedKey, path: "/users", expected: http.StatusOK},
+		{name: "key without scope", principal: ownedKey, path: "/audit-events", expected: http.StatusForbidden},
+		{name: "owned key on admin only route", principal: ownedKey, path: "/api-keys", expected: http.StatusForbidden},
+		{name: "service account with scope", principal: serviceKey, path: "/audit-events", expected: http.StatusOK},
+		{name: "service account without scope", principal: serviceKey, path: "/users", expected: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok := func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				helper.SetPrincipal(ctx, tc.principal)
+			}, RoleGuard(models.RoleAdmin, models.RoleService))
+			r.GET("/users", ScopeGuard(models.ScopeUsersRead), ok)
+			r.GET("/audit-events", ScopeGuard(models.ScopeAuditRead), ok)
+			r.GET("/api-keys", RoleGuard(models.RoleAdmin), ok)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"codepair-sinarmas/models"
+)
+
+// OTPNotifier delivers an OTP to its owner out of band, the API never returns the code
+type OTPNotifier interface {
+	SendOTP(ctx context.Context, user *models.User, otp *models.OTPLog) error
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/logger"
+	api "codepair-sinarmas/service"
+)
+
+// EmailOptions holds the SMTP settings used to mail the OTPs
+type EmailOptions struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type emailOTPNotifier struct {
+	opt  EmailOptions
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailOTPNotifier returns a notifier mailing the OTPs through the SMTP server of opt
+func NewEmailOTPNotifier(opt EmailOptions) api.OTPNotifier {
+	return &emailOTPNotifier{
+		opt:  opt,
+		send: smtp.SendMail,
+	}
+}
+
+func (n *emailOTPNotifier) SendOTP(ctx context.Context, user *models.User, otp *models.OTPLog) error {
+	var auth smtp.Auth
+	if n.opt.Username != "" {
+		auth = smtp.PlainAuth("", n.opt.Username, n.opt.Password, n.opt.Host)
+	}
+
+	return n.send(net.JoinHostPort(n.opt.Host, n.opt.Port), auth, n.opt.From, []string{user.Email}, n.message(user, otp))
+}
+
+func (n *emailOTPNotifier) message(user *models.User, otp *models.OTPLog) []byte {
+	subject := "Your verification code"
+	if otp.Purpose == models.OTPPurposePasswordReset {
+		subject = "Your password reset code"
+	}
+
+	lines := []string{
+		"From: " + n.opt.From,
+		"To: " + user.Email,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		fmt.Sprintf("Hi %s,", user.Name),
+		"",
+		fmt.Sprintf("Your code is %s, it expires at %s.", otp.OTPCode, otp.ExpiredAt.Format(time.RFC1123)),
+		"If you did not ask for it, you can ignore this email.",
+	}
+
+	return []byte(strings.Join(lines, "\r\n"))
+}
+
+type logOTPNotifier struct{}
+
+// NewLogOTPNotifier returns a notifier logging the OTPs, it is meant for local development only
+func NewLogOTPNotifier() api.OTPNotifier {
+	return logOTPNotifier{}
+}
+
+func (logOTPNotifier) SendOTP(ctx context.Context, user *models.User, otp *models.OTPLog) error {
+	logger.CreateSquad(ctx, "notifier").Infof("OTP %s for %s, [userID: %d, purpose: %s]", otp.OTPCode, user.Email, user.UserID, otp.Purpose)
+	return nil
+}
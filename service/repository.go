@@ -8,11 +8,27 @@ type UserRepository interface {
 	Register(user *models.User) (*models.User, error)
 	GetUserByID(userId int64) (user *models.User, err error)
 	GetUserByEmail(email string) (user *models.User, err error)
+	GetUsers(filter *models.UserFilter) (users []models.User, total int64, err error)
+	UpdateUser(userID int64, values map[string]interface{}) error
+	UpdateUserWithAudit(userID int64, values map[string]interface{}, event *models.AuditEvent) error
 }
 
 type OtpRepository interface {
 	SaveOTP(otpLog *models.OTPLog) (*models.OTPLog, error)
-	GetOtpByUserID(userID int64) (otp *models.OTPLog, err error)
-	GetOtpByUserIDAndCode(userID int64, otpCode string) (otp *models.OTPLog, err error)
-	UpdateStatusOtpByUserIDAndCode(userID int64, status string) error
+	GetOtpByUserID(userID int64, purpose string) (otp *models.OTPLog, err error)
+	ConsumeOtp(id int64) (consumed bool, err error)
+	FailOtp(id int64, maxAttempts int) error
+}
+
+type AuditRepository interface {
+	CreateAuditEvent(event *models.AuditEvent) error
+	GetAuditEvents(filter *models.AuditEventFilter) (events []models.AuditEvent, total int64, err error)
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(apiKey *models.APIKey) error
+	GetAPIKeyByID(id int64) (apiKey *models.APIKey, err error)
+	GetAPIKeyByPrefix(prefix string) (apiKey *models.APIKey, err error)
+	GetAPIKeys(filter *models.APIKeyFilter) (apiKeys []models.APIKey, total int64, err error)
+	UpdateAPIKey(id int64, values map[string]interface{}) error
 }
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"codepair-sinarmas/models"
+	api "codepair-sinarmas/service"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) api.APIKeyRepository {
+	return &apiKeyRepo{
+		db: db,
+	}
+}
+
+func (u *apiKeyRepo) CreateAPIKey(apiKey *models.APIKey) error {
+	return u.db.Create(apiKey).Error
+}
+
+func (u *apiKeyRepo) GetAPIKeyByID(id int64) (apiKey *models.APIKey, err error) {
+	return apiKey, primary(u.db).Where("id = ?", id).First(&apiKey).Error
+}
+
+func (u *apiKeyRepo) GetAPIKeyByPrefix(prefix string) (apiKey *models.APIKey, err error) {
+	return apiKey, primary(u.db).Where("prefix = ?", prefix).First(&apiKey).Error
+}
+
+func (u *apiKeyRepo) GetAPIKeys(filter *models.APIKeyFilter) (apiKeys []models.APIKey, total int64, err error) {
+	query := reader(u.db).Model(&models.APIKey{})
+
+	if filter.OwnerUserID != 0 {
+		query = query.Where("owner_user_id = ?", filter.OwnerUserID)
+	}
+
+	if !filter.IncludeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.Size).
+		Limit(filter.Size).
+		Find(&apiKeys).Error
+	return
+}
+
+func (u *apiKeyRepo) UpdateAPIKey(id int64, values map[string]interface{}) error {
+	return u.db.Model(&models.APIKey{}).Where("id = ?", id).Updates(values).Error
+}
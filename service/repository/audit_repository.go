@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"codepair-sinarmas/models"
+	api "codepair-sinarmas/service"
+
+	"gorm.io/gorm"
+)
+
+type auditRepo struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) api.AuditRepository {
+	return &auditRepo{
+		db: db,
+	}
+}
+
+func (u *auditRepo) CreateAuditEvent(event *models.AuditEvent) error {
+	return u.db.Create(event).Error
+}
+
+func (u *auditRepo) GetAuditEvents(filter *models.AuditEventFilter) (events []models.AuditEvent, total int64, err error) {
+	query := reader(u.db).Model(&models.AuditEvent{}).
+		Where("created_at >= ? AND created_at < ?", filter.StartDate, filter.EndDate)
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.Size).
+		Limit(filter.Size).
+		Find(&events).Error
+	return
+}
@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: codepair-sinarmas/service (interfaces: AuditLogger)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "codepair-sinarmas/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAuditLogger is a mock of AuditLogger interface.
+type MockAuditLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLoggerMockRecorder
+}
+
+// MockAuditLoggerMockRecorder is the mock recorder for MockAuditLogger.
+type MockAuditLoggerMockRecorder struct {
+	mock *MockAuditLogger
+}
+
+// NewMockAuditLogger creates a new mock instance.
+func NewMockAuditLogger(ctrl *gomock.Controller) *MockAuditLogger {
+	mock := &MockAuditLogger{ctrl: ctrl}
+	mock.recorder = &MockAuditLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogger) EXPECT() *MockAuditLoggerMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditLogger) Record(arg0 context.Context, arg1 *models.AuditEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", arg0, arg1)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditLoggerMockRecorder) Record(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditLogger)(nil).Record), arg0, arg1)
+}
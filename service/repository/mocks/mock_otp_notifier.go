@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: codepair-sinarmas/service (interfaces: OTPNotifier)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "codepair-sinarmas/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOTPNotifier is a mock of OTPNotifier interface.
+type MockOTPNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPNotifierMockRecorder
+}
+
+// MockOTPNotifierMockRecorder is the mock recorder for MockOTPNotifier.
+type MockOTPNotifierMockRecorder struct {
+	mock *MockOTPNotifier
+}
+
+// NewMockOTPNotifier creates a new mock instance.
+func NewMockOTPNotifier(ctrl *gomock.Controller) *MockOTPNotifier {
+	mock := &MockOTPNotifier{ctrl: ctrl}
+	mock.recorder = &MockOTPNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPNotifier) EXPECT() *MockOTPNotifierMockRecorder {
+	return m.recorder
+}
+
+// SendOTP mocks base method.
+func (m *MockOTPNotifier) SendOTP(arg0 context.Context, arg1 *models.User, arg2 *models.OTPLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendOTP", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendOTP indicates an expected call of SendOTP.
+func (mr *MockOTPNotifierMockRecorder) SendOTP(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOTP", reflect.TypeOf((*MockOTPNotifier)(nil).SendOTP), arg0, arg1, arg2)
+}
@@ -34,34 +34,48 @@ func (m *MockOtpRepository) EXPECT() *MockOtpRepositoryMockRecorder {
 	return m.recorder
 }
 
-// GetOtpByUserID mocks base method.
-func (m *MockOtpRepository) GetOtpByUserID(arg0 int64) (*models.OTPLog, error) {
+// ConsumeOtp mocks base method.
+func (m *MockOtpRepository) ConsumeOtp(arg0 int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOtpByUserID", arg0)
-	ret0, _ := ret[0].(*models.OTPLog)
+	ret := m.ctrl.Call(m, "ConsumeOtp", arg0)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOtpByUserID indicates an expected call of GetOtpByUserID.
-func (mr *MockOtpRepositoryMockRecorder) GetOtpByUserID(arg0 interface{}) *gomock.Call {
+// ConsumeOtp indicates an expected call of ConsumeOtp.
+func (mr *MockOtpRepositoryMockRecorder) ConsumeOtp(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOtpByUserID", reflect.TypeOf((*MockOtpRepository)(nil).GetOtpByUserID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOtp", reflect.TypeOf((*MockOtpRepository)(nil).ConsumeOtp), arg0)
 }
 
-// GetOtpByUserIDAndCode mocks base method.
-func (m *MockOtpRepository) GetOtpByUserIDAndCode(arg0 int64, arg1 string) (*models.OTPLog, error) {
+// FailOtp mocks base method.
+func (m *MockOtpRepository) FailOtp(arg0 int64, arg1 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOtpByUserIDAndCode", arg0, arg1)
+	ret := m.ctrl.Call(m, "FailOtp", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailOtp indicates an expected call of FailOtp.
+func (mr *MockOtpRepositoryMockRecorder) FailOtp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailOtp", reflect.TypeOf((*MockOtpRepository)(nil).FailOtp), arg0, arg1)
+}
+
+// GetOtpByUserID mocks base method.
+func (m *MockOtpRepository) GetOtpByUserID(arg0 int64, arg1 string) (*models.OTPLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOtpByUserID", arg0, arg1)
 	ret0, _ := ret[0].(*models.OTPLog)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOtpByUserIDAndCode indicates an expected call of GetOtpByUserIDAndCode.
-func (mr *MockOtpRepositoryMockRecorder) GetOtpByUserIDAndCode(arg0, arg1 interface{}) *gomock.Call {
+// GetOtpByUserID indicates an expected call of GetOtpByUserID.
+func (mr *MockOtpRepositoryMockRecorder) GetOtpByUserID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOtpByUserIDAndCode", reflect.TypeOf((*MockOtpRepository)(nil).GetOtpByUserIDAndCode), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOtpByUserID", reflect.TypeOf((*MockOtpRepository)(nil).GetOtpByUserID), arg0, arg1)
 }
 
 // SaveOTP mocks base method.
@@ -78,17 +92,3 @@ func (mr *MockOtpRepositoryMockRecorder) SaveOTP(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOTP", reflect.TypeOf((*MockOtpRepository)(nil).SaveOTP), arg0)
 }
-
-// UpdateStatusOtpByUserIDAndCode mocks base method.
-func (m *MockOtpRepository) UpdateStatusOtpByUserIDAndCode(arg0 int64, arg1 string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatusOtpByUserIDAndCode", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// UpdateStatusOtpByUserIDAndCode indicates an expected call of UpdateStatusOtpByUserIDAndCode.
-func (mr *MockOtpRepositoryMockRecorder) UpdateStatusOtpByUserIDAndCode(arg0, arg1 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusOtpByUserIDAndCode", reflect.TypeOf((*MockOtpRepository)(nil).UpdateStatusOtpByUserIDAndCode), arg0, arg1)
-}
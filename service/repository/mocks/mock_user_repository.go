@@ -64,6 +64,22 @@ func (mr *MockUserRepositoryMockRecorder) GetUserByID(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), arg0)
 }
 
+// GetUsers mocks base method.
+func (m *MockUserRepository) GetUsers(arg0 *models.UserFilter) ([]models.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", arg0)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepositoryMockRecorder) GetUsers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepository)(nil).GetUsers), arg0)
+}
+
 // Register mocks base method.
 func (m *MockUserRepository) Register(arg0 *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +94,31 @@ func (mr *MockUserRepositoryMockRecorder) Register(arg0 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserRepository)(nil).Register), arg0)
 }
+
+// UpdateUser mocks base method.
+func (m *MockUserRepository) UpdateUser(arg0 int64, arg1 map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserRepositoryMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRepository)(nil).UpdateUser), arg0, arg1)
+}
+
+// UpdateUserWithAudit mocks base method.
+func (m *MockUserRepository) UpdateUserWithAudit(arg0 int64, arg1 map[string]interface{}, arg2 *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserWithAudit", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserWithAudit indicates an expected call of UpdateUserWithAudit.
+func (mr *MockUserRepositoryMockRecorder) UpdateUserWithAudit(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserWithAudit", reflect.TypeOf((*MockUserRepository)(nil).UpdateUserWithAudit), arg0, arg1, arg2)
+}
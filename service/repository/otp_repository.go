@@ -3,6 +3,7 @@ package repository
 import (
 	"codepair-sinarmas/models"
 	api "codepair-sinarmas/service"
+	"errors"
 
 	"gorm.io/gorm"
 )
@@ -21,19 +22,27 @@ func (u *otpRepo) SaveOTP(otpLog *models.OTPLog) (*models.OTPLog, error) {
 	return otpLog, u.db.Create(&otpLog).Error
 }
 
-func (u *otpRepo) GetOtpByUserID(userID int64) (otp *models.OTPLog, err error) {
-	err = u.db.Where("user_id = ?", userID).First(&otp).Error
-	if err == gorm.ErrRecordNotFound {
+// GetOtpByUserID returns the latest OTP of purpose issued to the user, an empty one when there is none
+func (u *otpRepo) GetOtpByUserID(userID int64, purpose string) (otp *models.OTPLog, err error) {
+	err = primary(u.db).Where("user_id = ? AND purpose = ?", userID, purpose).Order("id DESC").First(&otp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return &models.OTPLog{}, nil
 	}
 
 	return otp, err
 }
 
-func (u *otpRepo) GetOtpByUserIDAndCode(userID int64, otpCode string) (otp *models.OTPLog, err error) {
-	return otp, u.db.Where("user_id = ? AND  otp_code = ?", userID, otpCode).First(&otp).Error
+// ConsumeOtp marks the OTP validated, consumed is false when it was already used so a code is never accepted twice
+func (u *otpRepo) ConsumeOtp(id int64) (consumed bool, err error) {
+	res := u.db.Model(&models.OTPLog{}).Where("id = ? AND status = ?", id, "created").Update("status", "validated")
+	return res.RowsAffected > 0, res.Error
 }
 
-func (u *otpRepo) UpdateStatusOtpByUserIDAndCode(userID int64, status string) error {
-	return u.db.Model(&models.OTPLog{}).Where("user_id = ?", userID).Update("status", status).Error
+// FailOtp counts a wrong code against the OTP in a single statement on the primary, the OTP is invalidated
+// by the attempt reaching maxAttempts so concurrent guesses cannot go past it
+func (u *otpRepo) FailOtp(id int64, maxAttempts int) error {
+	return u.db.Model(&models.OTPLog{}).Where("id = ? AND status = ?", id, "created").Updates(map[string]interface{}{
+		"failed_attempts": gorm.Expr("failed_attempts + 1"),
+		"status":          gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 'invalidated' ELSE status END", maxAttempts),
+	}).Error
 }
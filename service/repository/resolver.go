@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ResolverReplicas is the name the read replicas are registered under, the statements not asking
+// for it keep running on the primary
+const ResolverReplicas = "replicas"
+
+// reader routes the queries of db to the read replicas, they run on the primary when none is registered.
+// Only the list and report queries use it, a lagging replica must never answer an authentication check
+func reader(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Use(ResolverReplicas))
+}
+
+// primary pins the queries of db to the primary, it is used by the lookups deciding authentication,
+// revocation and OTP validity which have to see the latest writes
+func primary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write)
+}
@@ -22,9 +22,46 @@ func (u *userRepo) Register(user *models.User) (*models.User, error) {
 }
 
 func (u *userRepo) GetUserByID(userId int64) (user *models.User, err error) {
-	return user, u.db.Where("user_id = ?", userId).First(&user).Error
+	return user, primary(u.db).Where("user_id = ?", userId).First(&user).Error
 }
 
 func (u *userRepo) GetUserByEmail(email string) (user *models.User, err error) {
-	return user, u.db.Where("email = ?", email).First(&user).Error
+	return user, primary(u.db).Where("email = ?", email).First(&user).Error
+}
+
+func (u *userRepo) GetUsers(filter *models.UserFilter) (users []models.User, total int64, err error) {
+	query := reader(u.db).Model(&models.User{})
+
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", search, search)
+	}
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Order("user_id ASC").
+		Offset((filter.Page - 1) * filter.Size).
+		Limit(filter.Size).
+		Find(&users).Error
+	return
+}
+
+func (u *userRepo) UpdateUser(userID int64, values map[string]interface{}) error {
+	return u.db.Model(&models.User{}).Where("user_id = ?", userID).Updates(values).Error
+}
+
+// UpdateUserWithAudit updates the user and stores the audit event in the same transaction,
+// the update is rolled back when the event cannot be stored
+func (u *userRepo) UpdateUserWithAudit(userID int64, values map[string]interface{}, event *models.AuditEvent) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("user_id = ?", userID).Updates(values).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(event).Error
+	})
 }
@@ -1,16 +1,45 @@
 package service
 
 import (
+	"context"
+
 	"codepair-sinarmas/models"
 	"codepair-sinarmas/pkg/serror"
 )
 
 type UserUsecase interface {
-	Register(request *models.RegisterUser) (user *models.User, errx serror.SError)
-	Login(request *models.LoginUser) (res models.LoginResponse, errx serror.SError)
+	Register(ctx context.Context, request *models.RegisterUser) (user *models.User, errx serror.SError)
+	Login(ctx context.Context, request *models.LoginUser) (res models.LoginResponse, errx serror.SError)
+	ResetPassword(ctx context.Context, request *models.ResetPasswordRequest) (errx serror.SError)
+	ValidateSession(ctx context.Context, userID int64, tokenVersion int64) (user *models.User, errx serror.SError)
+}
+
+type AdminUsecase interface {
+	GetUsers(ctx context.Context, filter *models.UserFilter) (users []models.User, pagination models.PaginationResponse, errx serror.SError)
+	SuspendUser(ctx context.Context, userID int64) (errx serror.SError)
+	UnsuspendUser(ctx context.Context, userID int64) (errx serror.SError)
+	ForcePasswordReset(ctx context.Context, userID int64) (errx serror.SError)
+	RevokeSessions(ctx context.Context, userID int64) (errx serror.SError)
+	ChangeRole(ctx context.Context, userID int64, request *models.ChangeRoleRequest) (errx serror.SError)
+}
+
+type APIKeyUsecase interface {
+	CreateAPIKey(ctx context.Context, request *models.CreateAPIKeyRequest) (res models.CreateAPIKeyResponse, errx serror.SError)
+	GetAPIKeys(ctx context.Context, filter *models.APIKeyFilter) (apiKeys []models.APIKey, pagination models.PaginationResponse, errx serror.SError)
+	RevokeAPIKey(ctx context.Context, id int64) (errx serror.SError)
+	ValidateAPIKey(ctx context.Context, key string) (apiKey *models.APIKey, owner *models.User, errx serror.SError)
 }
 
 type OTPUsecase interface {
-	RequestOtp(request *models.OTPRequest) (res *models.OTPResponse, errx serror.SError)
-	ValidateOtp(request *models.OTPValidateRequest) (valid bool, errx serror.SError)
+	RequestOtp(ctx context.Context, request *models.OTPRequest) (errx serror.SError)
+	ValidateOtp(ctx context.Context, request *models.OTPValidateRequest) (valid bool, errx serror.SError)
+}
+
+type AuditLogger interface {
+	Record(ctx context.Context, event *models.AuditEvent)
+}
+
+type AuditUsecase interface {
+	AuditLogger
+	GetAuditEvents(ctx context.Context, filter *models.AuditEventFilter) (events []models.AuditEvent, pagination models.PaginationResponse, errx serror.SError)
 }
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	api "codepair-sinarmas/service"
+	"codepair-sinarmas/service/helper"
+
+	"gorm.io/gorm"
+)
+
+// AdminUsecase stores the audit event of every action in the same transaction as the action,
+// an action is never applied without its audit record
+type AdminUsecase struct {
+	userRepo api.UserRepository
+}
+
+func NewAdminUsecase(
+	userRepo api.UserRepository,
+) api.AdminUsecase {
+	return &AdminUsecase{
+		userRepo: userRepo,
+	}
+}
+
+func (u *AdminUsecase) GetUsers(ctx context.Context, filter *models.UserFilter) (users []models.User, pagination models.PaginationResponse, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	if filter.Size < 1 || filter.Size > 100 {
+		filter.Size = 10
+	}
+
+	users, total, err := u.userRepo.GetUsers(filter)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][GetUsers] Failed to get users, [search: %s]", filter.Search)
+		return
+	}
+
+	pagination = models.NewPaginationResponse(filter.Page, filter.Size, total, len(users))
+	return
+}
+
+func (u *AdminUsecase) SuspendUser(ctx context.Context, userID int64) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	actorID := helper.UserIDFromContext(ctx)
+	if actorID == userID {
+		errx = hctx.SignError(helper.ERR_USER_SUSPEND_SELF.New())
+		return
+	}
+
+	user, errx := u.getUser(hctx, userID, "SuspendUser")
+	if errx != nil {
+		return
+	}
+
+	if user.SuspendedAt != nil {
+		errx = hctx.SignError(helper.ERR_USER_ALREADY_SUSPENDED.New())
+		return
+	}
+
+	suspendedAt := time.Now()
+	err := u.userRepo.UpdateUserWithAudit(userID, map[string]interface{}{
+		"suspended_at": suspendedAt,
+	}, auditEvent(ctx, userID, models.AuditActionSuspend, map[string]models.AuditChange{
+		"suspended_at": {From: nil, To: suspendedAt},
+	}))
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][SuspendUser] Failed to suspend user, [userID: %d]", userID)
+		return
+	}
+
+	return
+}
+
+func (u *AdminUsecase) UnsuspendUser(ctx context.Context, userID int64) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	user, errx := u.getUser(hctx, userID, "UnsuspendUser")
+	if errx != nil {
+		return
+	}
+
+	if user.SuspendedAt == nil {
+		errx = hctx.SignError(helper.ERR_USER_NOT_SUSPENDED.New())
+		return
+	}
+
+	err := u.userRepo.UpdateUserWithAudit(userID, map[string]interface{}{
+		"suspended_at": nil,
+	}, auditEvent(ctx, userID, models.AuditActionUnsuspend, map[string]models.AuditChange{
+		"suspended_at": {From: *user.SuspendedAt, To: nil},
+	}))
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][UnsuspendUser] Failed to unsuspend user, [userID: %d]", userID)
+		return
+	}
+
+	return
+}
+
+func (u *AdminUsecase) ForcePasswordReset(ctx context.Context, userID int64) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	user, errx := u.getUser(hctx, userID, "ForcePasswordReset")
+	if errx != nil {
+		return
+	}
+
+	err := u.userRepo.UpdateUserWithAudit(userID, map[string]interface{}{
+		"password_reset_required": true,
+		"token_version":           gorm.Expr("token_version + 1"),
+	}, auditEvent(ctx, userID, models.AuditActionForcePasswordReset, map[string]models.AuditChange{
+		"password_reset_required": {From: user.PasswordResetRequired, To: true},
+		"token_version":           {From: user.TokenVersion, To: user.TokenVersion + 1},
+	}))
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ForcePasswordReset] Failed to force password reset, [userID: %d]", userID)
+		return
+	}
+
+	return
+}
+
+func (u *AdminUsecase) RevokeSessions(ctx context.Context, userID int64) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	user, errx := u.getUser(hctx, userID, "RevokeSessions")
+	if errx != nil {
+		return
+	}
+
+	err := u.userRepo.UpdateUserWithAudit(userID, map[string]interface{}{
+		"token_version": gorm.Expr("token_version + 1"),
+	}, auditEvent(ctx, userID, models.AuditActionRevokeSessions, map[string]models.AuditChange{
+		"token_version": {From: user.TokenVersion, To: user.TokenVersion + 1},
+	}))
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][RevokeSessions] Failed to revoke sessions, [userID: %d]", userID)
+		return
+	}
+
+	return
+}
+
+func (u *AdminUsecase) ChangeRole(ctx context.Context, userID int64, request *models.ChangeRoleRequest) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	actorID := helper.UserIDFromContext(ctx)
+	if actorID == userID {
+		errx = hctx.SignError(helper.ERR_USER_ROLE_CHANGE_SELF.New())
+		return
+	}
+
+	user, errx := u.getUser(hctx, userID, "ChangeRole")
+	if errx != nil {
+		return
+	}
+
+	if user.Role == request.Role {
+		errx = hctx.SignError(helper.ERR_USER_ROLE_UNCHANGED.New())
+		return
+	}
+
+	err := u.userRepo.UpdateUserWithAudit(userID, map[string]interface{}{
+		"role":          request.Role,
+		"token_version": gorm.Expr("token_version + 1"),
+	}, auditEvent(ctx, userID, models.AuditActionRoleChange, map[string]models.AuditChange{
+		"role":          {From: user.Role, To: request.Role},
+		"token_version": {From: user.TokenVersion, To: user.TokenVersion + 1},
+	}))
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ChangeRole] Failed to change user role, [userID: %d]", userID)
+		return
+	}
+
+	return
+}
+
+// private
+
+// auditEvent builds the event of an action of the current principal on the target user
+func auditEvent(ctx context.Context, targetID int64, action string, changes map[string]models.AuditChange) *models.AuditEvent {
+	return &models.AuditEvent{
+		ActorID:   helper.UserIDFromContext(ctx),
+		TargetID:  targetID,
+		Action:    action,
+		IP:        helper.ClientIPFromContext(ctx),
+		RequestID: helper.RequestIDFromContext(ctx),
+		Changes:   changes,
+	}
+}
+
+func (u *AdminUsecase) getUser(hctx serror.HCtx, userID int64, fn string) (user *models.User, errx serror.SError) {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_USER_NOT_FOUND.New())
+			return
+		}
+
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][%s] Failed to get user by ID, [userID: %d]", fn, userID)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,488 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/service/helper"
+	"codepair-sinarmas/service/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var adminPrincipal = &helper.Principal{
+	Type:       helper.PrincipalTypeUser,
+	UserID:     1,
+	Roles:      []string{models.RoleAdmin},
+	AuthMethod: helper.AuthMethodJWT,
+}
+
+func Test_AdminUsecase_GetUsers(t *testing.T) {
+	type testCase struct {
+		name               string
+		wantError          bool
+		filter             *models.UserFilter
+		expectedUsers      []models.User
+		expectedPagination models.PaginationResponse
+		onGetUsers         func(mock *mocks.MockUserRepository)
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		filter: &models.UserFilter{
+			Search: "john",
+			Page:   1,
+			Size:   2,
+		},
+		onGetUsers: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUsers(&models.UserFilter{Search: "john", Page: 1, Size: 2}).Return([]models.User{
+				{UserID: 1, Name: "John Doe"},
+				{UserID: 2, Name: "Johnny"},
+			}, int64(3), nil)
+		},
+		expectedUsers: []models.User{
+			{UserID: 1, Name: "John Doe"},
+			{UserID: 2, Name: "Johnny"},
+		},
+		expectedPagination: models.PaginationResponse{
+			CurrentPage:  1,
+			PageSize:     2,
+			TotalCount:   3,
+			TotalPages:   2,
+			FirstPage:    1,
+			NextPage:     2,
+			LastPage:     2,
+			CurrentCount: 2,
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to get users",
+		wantError: true,
+		filter:    &models.UserFilter{},
+		onGetUsers: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUsers(&models.UserFilter{Page: 1, Size: 10}).Return(nil, int64(0), assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUsers != nil {
+				tc.onGetUsers(userRepo)
+			}
+
+			usecase := &AdminUsecase{userRepo: userRepo}
+
+			users, pagination, err := usecase.GetUsers(context.Background(), tc.filter)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedUsers, users)
+				assert.Equal(t, tc.expectedPagination, pagination)
+			}
+		})
+	}
+}
+
+func Test_AdminUsecase_SuspendUser(t *testing.T) {
+	type testCase struct {
+		name                  string
+		wantError             bool
+		userID                int64
+		onGetUserByID         func(mock *mocks.MockUserRepository)
+		onUpdateUserWithAudit func(mock *mocks.MockUserRepository)
+	}
+
+	suspendedAt := time.Now()
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		userID:    2,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(2)).Return(&models.User{UserID: 2}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(2), gomock.Any(), gomock.Any()).DoAndReturn(func(_ int64, _ map[string]interface{}, event *models.AuditEvent) error {
+				assert.Equal(t, models.AuditActionSuspend, event.Action)
+				assert.Equal(t, int64(1), event.ActorID)
+				assert.Equal(t, int64(2), event.TargetID)
+				return nil
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "suspend own account",
+		wantError: true,
+		userID:    1,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "user not found",
+		wantError: true,
+		userID:    3,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "user already suspended",
+		wantError: true,
+		userID:    4,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(4)).Return(&models.User{UserID: 4, SuspendedAt: &suspendedAt}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to update user",
+		wantError: true,
+		userID:    5,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(5)).Return(&models.User{UserID: 5}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(5), gomock.Any(), gomock.Any()).Return(assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onUpdateUserWithAudit != nil {
+				tc.onUpdateUserWithAudit(userRepo)
+			}
+
+			usecase := &AdminUsecase{userRepo: userRepo}
+
+			err := usecase.SuspendUser(helper.WithPrincipal(context.Background(), adminPrincipal), tc.userID)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_AdminUsecase_UnsuspendUser(t *testing.T) {
+	type testCase struct {
+		name                  string
+		wantError             bool
+		userID                int64
+		onGetUserByID         func(mock *mocks.MockUserRepository)
+		onUpdateUserWithAudit func(mock *mocks.MockUserRepository)
+	}
+
+	suspendedAt := time.Now()
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		userID:    2,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(2)).Return(&models.User{UserID: 2, SuspendedAt: &suspendedAt}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(2), map[string]interface{}{"suspended_at": nil}, gomock.Any()).DoAndReturn(func(_ int64, _ map[string]interface{}, event *models.AuditEvent) error {
+				assert.Equal(t, models.AuditActionUnsuspend, event.Action)
+				assert.Equal(t, int64(2), event.TargetID)
+				return nil
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "user is not suspended",
+		wantError: true,
+		userID:    3,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(&models.User{UserID: 3}, nil)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onUpdateUserWithAudit != nil {
+				tc.onUpdateUserWithAudit(userRepo)
+			}
+
+			usecase := &AdminUsecase{userRepo: userRepo}
+
+			err := usecase.UnsuspendUser(helper.WithPrincipal(context.Background(), adminPrincipal), tc.userID)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_AdminUsecase_ForcePasswordReset(t *testing.T) {
+	type testCase struct {
+		name                  string
+		wantError             bool
+		userID                int64
+		onGetUserByID         func(mock *mocks.MockUserRepository)
+		onUpdateUserWithAudit func(mock *mocks.MockUserRepository)
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		userID:    2,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(2)).Return(&models.User{UserID: 2, TokenVersion: 4}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(2), map[string]interface{}{
+				"password_reset_required": true,
+				"token_version":           gorm.Expr("token_version + 1"),
+			}, gomock.Any()).DoAndReturn(func(_ int64, _ map[string]interface{}, event *models.AuditEvent) error {
+				assert.Equal(t, models.AuditActionForcePasswordReset, event.Action)
+				assert.Equal(t, int64(2), event.TargetID)
+				return nil
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to get user",
+		wantError: true,
+		userID:    3,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(nil, assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onUpdateUserWithAudit != nil {
+				tc.onUpdateUserWithAudit(userRepo)
+			}
+
+			usecase := &AdminUsecase{userRepo: userRepo}
+
+			err := usecase.ForcePasswordReset(helper.WithPrincipal(context.Background(), adminPrincipal), tc.userID)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_AdminUsecase_RevokeSessions(t *testing.T) {
+	type testCase struct {
+		name                  string
+		wantError             bool
+		userID                int64
+		onGetUserByID         func(mock *mocks.MockUserRepository)
+		onUpdateUserWithAudit func(mock *mocks.MockUserRepository)
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		userID:    2,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(2)).Return(&models.User{UserID: 2}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(2), map[string]interface{}{"token_version": gorm.Expr("token_version + 1")}, gomock.Any()).DoAndReturn(func(_ int64, _ map[string]interface{}, event *models.AuditEvent) error {
+				assert.Equal(t, models.AuditActionRevokeSessions, event.Action)
+				assert.Equal(t, int64(2), event.TargetID)
+				return nil
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to update user",
+		wantError: true,
+		userID:    3,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(&models.User{UserID: 3}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(3), gomock.Any(), gomock.Any()).Return(assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onUpdateUserWithAudit != nil {
+				tc.onUpdateUserWithAudit(userRepo)
+			}
+
+			usecase := &AdminUsecase{userRepo: userRepo}
+
+			err := usecase.RevokeSessions(helper.WithPrincipal(context.Background(), adminPrincipal), tc.userID)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_AdminUsecase_ChangeRole(t *testing.T) {
+	type testCase struct {
+		name                  string
+		wantError             bool
+		userID                int64
+		role                  string
+		onGetUserByID         func(mock *mocks.MockUserRepository)
+		onUpdateUserWithAudit func(mock *mocks.MockUserRepository)
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		userID:    2,
+		role:      models.RoleAdmin,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(2)).Return(&models.User{UserID: 2, Role: models.RoleUser, TokenVersion: 4}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(2), gomock.Any(), gomock.Any()).DoAndReturn(func(_ int64, values map[string]interface{}, event *models.AuditEvent) error {
+				assert.Equal(t, models.RoleAdmin, values["role"])
+				assert.Equal(t, gorm.Expr("token_version + 1"), values["token_version"])
+				assert.Equal(t, models.AuditActionRoleChange, event.Action)
+				assert.Equal(t, int64(1), event.ActorID)
+				assert.Equal(t, int64(2), event.TargetID)
+				assert.Equal(t, models.AuditChange{From: models.RoleUser, To: models.RoleAdmin}, event.Changes["role"])
+				return nil
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "cannot change own role",
+		wantError: true,
+		userID:    1,
+		role:      models.RoleUser,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "user not found",
+		wantError: true,
+		userID:    3,
+		role:      models.RoleAdmin,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "role unchanged",
+		wantError: true,
+		userID:    4,
+		role:      models.RoleUser,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(4)).Return(&models.User{UserID: 4, Role: models.RoleUser}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to update user role",
+		wantError: true,
+		userID:    5,
+		role:      models.RoleAdmin,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(5)).Return(&models.User{UserID: 5, Role: models.RoleUser}, nil)
+		},
+		onUpdateUserWithAudit: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUserWithAudit(int64(5), gomock.Any(), gomock.Any()).Return(assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onUpdateUserWithAudit != nil {
+				tc.onUpdateUserWithAudit(userRepo)
+			}
+
+			usecase := &AdminUsecase{userRepo: userRepo}
+
+			err := usecase.ChangeRole(helper.WithPrincipal(context.Background(), adminPrincipal), tc.userID, &models.ChangeRoleRequest{Role: tc.role})
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
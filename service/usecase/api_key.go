@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	api "codepair-sinarmas/service"
+	"codepair-sinarmas/service/helper"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyUsecase struct {
+	apiKeyRepo  api.APIKeyRepository
+	userRepo    api.UserRepository
+	auditLogger api.AuditLogger
+}
+
+func NewAPIKeyUsecase(
+	apiKeyRepo api.APIKeyRepository,
+	userRepo api.UserRepository,
+	auditLogger api.AuditLogger,
+) api.APIKeyUsecase {
+	return &APIKeyUsecase{
+		apiKeyRepo:  apiKeyRepo,
+		userRepo:    userRepo,
+		auditLogger: auditLogger,
+	}
+}
+
+func (u *APIKeyUsecase) CreateAPIKey(ctx context.Context, request *models.CreateAPIKeyRequest) (res models.CreateAPIKeyResponse, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	if !request.ExpiresAt.After(time.Now()) {
+		errx = hctx.SignError(helper.ERR_API_KEY_EXPIRY_IN_PAST.New())
+		return
+	}
+
+	apiKey := models.APIKey{
+		Name:      request.Name,
+		OwnerType: request.OwnerType,
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+		CreatedBy: helper.UserIDFromContext(ctx),
+	}
+
+	if request.OwnerType == helper.PrincipalTypeUser {
+		_, err := u.userRepo.GetUserByID(request.OwnerUserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				errx = hctx.SignError(helper.ERR_USER_NOT_FOUND.New())
+				return
+			}
+
+			errx = hctx.CreateErrorEx(err)
+			errx.AddCommentf("[usecase][CreateAPIKey] Failed to get user by ID, [userID: %d]", request.OwnerUserID)
+			return
+		}
+
+		apiKey.OwnerUserID = &request.OwnerUserID
+	} else {
+		apiKey.ServiceAccount = request.ServiceAccount
+	}
+
+	key, prefix, hash, errx := helper.GenerateAPIKey()
+	if errx != nil {
+		errx.AddComments("[usecase][CreateAPIKey] Failed to generate API key")
+		return
+	}
+
+	apiKey.Prefix = prefix
+	apiKey.KeyHash = hash
+
+	err := u.apiKeyRepo.CreateAPIKey(&apiKey)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][CreateAPIKey] Failed to create API key, [name: %s]", request.Name)
+		return
+	}
+
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  apiKey.CreatedBy,
+		TargetID: apiKey.ID,
+		Action:   models.AuditActionAPIKeyCreate,
+		Changes: map[string]models.AuditChange{
+			"prefix":          {To: apiKey.Prefix},
+			"owner_type":      {To: apiKey.OwnerType},
+			"owner_user_id":   {To: apiKey.OwnerUserID},
+			"service_account": {To: apiKey.ServiceAccount},
+			"scopes":          {To: apiKey.Scopes},
+			"expires_at":      {To: apiKey.ExpiresAt},
+		},
+	})
+
+	res = models.CreateAPIKeyResponse{
+		Key:    key,
+		APIKey: apiKey,
+	}
+
+	return
+}
+
+func (u *APIKeyUsecase) GetAPIKeys(ctx context.Context, filter *models.APIKeyFilter) (apiKeys []models.APIKey, pagination models.PaginationResponse, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	if filter.Size < 1 || filter.Size > 100 {
+		filter.Size = 10
+	}
+
+	apiKeys, total, err := u.apiKeyRepo.GetAPIKeys(filter)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][GetAPIKeys] Failed to get API keys, [ownerUserID: %d]", filter.OwnerUserID)
+		return
+	}
+
+	pagination = models.NewPaginationResponse(filter.Page, filter.Size, total, len(apiKeys))
+	return
+}
+
+func (u *APIKeyUsecase) RevokeAPIKey(ctx context.Context, id int64) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	apiKey, err := u.apiKeyRepo.GetAPIKeyByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_API_KEY_NOT_FOUND.New())
+			return
+		}
+
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][RevokeAPIKey] Failed to get API key by ID, [id: %d]", id)
+		return
+	}
+
+	if apiKey.RevokedAt != nil {
+		errx = hctx.SignError(helper.ERR_API_KEY_ALREADY_REVOKED.New())
+		return
+	}
+
+	revokedAt := time.Now()
+	err = u.apiKeyRepo.UpdateAPIKey(id, map[string]interface{}{
+		"revoked_at": revokedAt,
+	})
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][RevokeAPIKey] Failed to revoke API key, [id: %d]", id)
+		return
+	}
+
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  helper.UserIDFromContext(ctx),
+		TargetID: id,
+		Action:   models.AuditActionAPIKeyRevoke,
+		Changes: map[string]models.AuditChange{
+			"revoked_at": {From: nil, To: revokedAt},
+		},
+	})
+
+	return
+}
+
+// ValidateAPIKey resolves key to its stored record, owner is only set for keys owned by a user
+func (u *APIKeyUsecase) ValidateAPIKey(ctx context.Context, key string) (apiKey *models.APIKey, owner *models.User, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	prefix, ok := helper.ParseAPIKeyPrefix(key)
+	if !ok {
+		errx = hctx.SignError(helper.ERR_API_KEY_INVALID.New())
+		return
+	}
+
+	apiKey, err := u.apiKeyRepo.GetAPIKeyByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_API_KEY_INVALID.New())
+			return
+		}
+
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ValidateAPIKey] Failed to get API key by prefix, [prefix: %s]", prefix)
+		return
+	}
+
+	if !helper.CompareAPIKey(apiKey.KeyHash, key) {
+		errx = hctx.SignError(helper.ERR_API_KEY_INVALID.New())
+		return nil, nil, errx
+	}
+
+	if apiKey.RevokedAt != nil {
+		errx = hctx.SignError(helper.ERR_API_KEY_REVOKED.New())
+		return nil, nil, errx
+	}
+
+	now := time.Now()
+	if !now.Before(apiKey.ExpiresAt) {
+		errx = hctx.SignError(helper.ERR_API_KEY_EXPIRED.New())
+		return nil, nil, errx
+	}
+
+	if apiKey.OwnerUserID != nil {
+		owner, err = u.userRepo.GetUserByID(*apiKey.OwnerUserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				errx = hctx.SignError(helper.ERR_API_KEY_OWNER_NOT_EXISTS.New())
+				return nil, nil, errx
+			}
+
+			errx = hctx.CreateErrorEx(err)
+			errx.AddCommentf("[usecase][ValidateAPIKey] Failed to get API key owner, [userID: %d]", *apiKey.OwnerUserID)
+			return nil, nil, errx
+		}
+
+		if owner.SuspendedAt != nil {
+			errx = hctx.SignError(helper.ERR_USER_SUSPENDED.New())
+			return nil, nil, errx
+		}
+	}
+
+	err = u.apiKeyRepo.UpdateAPIKey(apiKey.ID, map[string]interface{}{
+		"last_used_at": now,
+	})
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ValidateAPIKey] Failed to update last used time, [id: %d]", apiKey.ID)
+		helper.LoggerFromContext(ctx, helper.LogLayerUsecase).Warn(errx)
+		errx = nil
+	}
+
+	return
+}
@@ -0,0 +1,390 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/service/helper"
+	"codepair-sinarmas/service/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_APIKeyUsecase_CreateAPIKey(t *testing.T) {
+	type testCase struct {
+		name           string
+		wantError      bool
+		request        *models.CreateAPIKeyRequest
+		onGetUserByID  func(mock *mocks.MockUserRepository)
+		onCreateAPIKey func(mock *mocks.MockAPIKeyRepository)
+		onRecord       func(mock *mocks.MockAuditLogger)
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	ownerUserID := int64(2)
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success for user",
+		wantError: false,
+		request: &models.CreateAPIKeyRequest{
+			Name:        "reporting",
+			OwnerType:   helper.PrincipalTypeUser,
+			OwnerUserID: ownerUserID,
+			Scopes:      []string{models.ScopeUsersRead},
+			ExpiresAt:   expiresAt,
+		},
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(ownerUserID).Return(&models.User{UserID: ownerUserID}, nil)
+		},
+		onCreateAPIKey: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().CreateAPIKey(gomock.Any()).DoAndReturn(func(apiKey *models.APIKey) error {
+				assert.Equal(t, &ownerUserID, apiKey.OwnerUserID)
+				assert.Equal(t, int64(1), apiKey.CreatedBy)
+				apiKey.ID = 10
+				return nil
+			})
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionAPIKeyCreate, event.Action)
+				assert.Equal(t, int64(10), event.TargetID)
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success for service account",
+		wantError: false,
+		request: &models.CreateAPIKeyRequest{
+			Name:           "nightly batch",
+			OwnerType:      helper.PrincipalTypeServiceAccount,
+			ServiceAccount: "batch-job",
+			Scopes:         []string{models.ScopeAuditRead},
+			ExpiresAt:      expiresAt,
+		},
+		onCreateAPIKey: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().CreateAPIKey(gomock.Any()).DoAndReturn(func(apiKey *models.APIKey) error {
+				assert.Nil(t, apiKey.OwnerUserID)
+				assert.Equal(t, "batch-job", apiKey.ServiceAccount)
+				return nil
+			})
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any())
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "expiry in the past",
+		wantError: true,
+		request: &models.CreateAPIKeyRequest{
+			Name:           "expired",
+			OwnerType:      helper.PrincipalTypeServiceAccount,
+			ServiceAccount: "batch-job",
+			Scopes:         []string{models.ScopeAuditRead},
+			ExpiresAt:      time.Now().Add(-time.Hour),
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "owner not found",
+		wantError: true,
+		request: &models.CreateAPIKeyRequest{
+			Name:        "reporting",
+			OwnerType:   helper.PrincipalTypeUser,
+			OwnerUserID: 3,
+			Scopes:      []string{models.ScopeUsersRead},
+			ExpiresAt:   expiresAt,
+		},
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to create API key",
+		wantError: true,
+		request: &models.CreateAPIKeyRequest{
+			Name:           "nightly batch",
+			OwnerType:      helper.PrincipalTypeServiceAccount,
+			ServiceAccount: "batch-job",
+			Scopes:         []string{models.ScopeAuditRead},
+			ExpiresAt:      expiresAt,
+		},
+		onCreateAPIKey: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().CreateAPIKey(gomock.Any()).Return(assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			apiKeyRepo := mocks.NewMockAPIKeyRepository(mockCtrl)
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onCreateAPIKey != nil {
+				tc.onCreateAPIKey(apiKeyRepo)
+			}
+
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
+			}
+
+			usecase := &APIKeyUsecase{apiKeyRepo: apiKeyRepo, userRepo: userRepo, auditLogger: auditLogger}
+
+			res, err := usecase.CreateAPIKey(helper.WithPrincipal(context.Background(), adminPrincipal), tc.request)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+
+				prefix, ok := helper.ParseAPIKeyPrefix(res.Key)
+				assert.True(t, ok)
+				assert.Equal(t, prefix, res.APIKey.Prefix)
+				assert.True(t, helper.CompareAPIKey(res.APIKey.KeyHash, res.Key))
+			}
+		})
+	}
+}
+
+func Test_APIKeyUsecase_RevokeAPIKey(t *testing.T) {
+	type testCase struct {
+		name            string
+		wantError       bool
+		id              int64
+		onGetAPIKeyByID func(mock *mocks.MockAPIKeyRepository)
+		onUpdateAPIKey  func(mock *mocks.MockAPIKeyRepository)
+		onRecord        func(mock *mocks.MockAuditLogger)
+	}
+
+	revokedAt := time.Now()
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		id:        10,
+		onGetAPIKeyByID: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByID(int64(10)).Return(&models.APIKey{ID: 10}, nil)
+		},
+		onUpdateAPIKey: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().UpdateAPIKey(int64(10), gomock.Any()).Return(nil)
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionAPIKeyRevoke, event.Action)
+				assert.Equal(t, int64(10), event.TargetID)
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "API key not found",
+		wantError: true,
+		id:        11,
+		onGetAPIKeyByID: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByID(int64(11)).Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "already revoked",
+		wantError: true,
+		id:        12,
+		onGetAPIKeyByID: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByID(int64(12)).Return(&models.APIKey{ID: 12, RevokedAt: &revokedAt}, nil)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			apiKeyRepo := mocks.NewMockAPIKeyRepository(mockCtrl)
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+
+			if tc.onGetAPIKeyByID != nil {
+				tc.onGetAPIKeyByID(apiKeyRepo)
+			}
+
+			if tc.onUpdateAPIKey != nil {
+				tc.onUpdateAPIKey(apiKeyRepo)
+			}
+
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
+			}
+
+			usecase := &APIKeyUsecase{apiKeyRepo: apiKeyRepo, auditLogger: auditLogger}
+
+			err := usecase.RevokeAPIKey(helper.WithPrincipal(context.Background(), adminPrincipal), tc.id)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_APIKeyUsecase_ValidateAPIKey(t *testing.T) {
+	key, prefix, hash, errx := helper.GenerateAPIKey()
+	assert.Nil(t, errx)
+
+	ownerUserID := int64(2)
+	revokedAt := time.Now()
+	suspendedAt := time.Now()
+	expiresAt := time.Now().Add(time.Hour)
+
+	type testCase struct {
+		name                string
+		key                 string
+		expectedCode        int
+		expectedKey         helper.ErrorKey
+		expectedOwner       bool
+		onGetAPIKeyByPrefix func(mock *mocks.MockAPIKeyRepository)
+		onGetUserByID       func(mock *mocks.MockUserRepository)
+		onUpdateAPIKey      func(mock *mocks.MockAPIKeyRepository)
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name: "service account key",
+		key:  key,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(&models.APIKey{ID: 1, KeyHash: hash, ExpiresAt: expiresAt}, nil)
+		},
+		onUpdateAPIKey: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().UpdateAPIKey(int64(1), gomock.Any()).Return(nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:          "user key",
+		key:           key,
+		expectedOwner: true,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(&models.APIKey{ID: 1, KeyHash: hash, ExpiresAt: expiresAt, OwnerUserID: &ownerUserID}, nil)
+		},
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(ownerUserID).Return(&models.User{UserID: ownerUserID}, nil)
+		},
+		onUpdateAPIKey: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().UpdateAPIKey(int64(1), gomock.Any()).Return(assert.AnError)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "malformed key",
+		key:          "not-an-api-key",
+		expectedCode: http.StatusUnauthorized,
+		expectedKey:  helper.ERR_API_KEY_INVALID,
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "unknown prefix",
+		key:          key,
+		expectedCode: http.StatusUnauthorized,
+		expectedKey:  helper.ERR_API_KEY_INVALID,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "wrong secret",
+		key:          prefix + "_wrong",
+		expectedCode: http.StatusUnauthorized,
+		expectedKey:  helper.ERR_API_KEY_INVALID,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(&models.APIKey{ID: 1, KeyHash: hash, ExpiresAt: expiresAt}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "revoked key",
+		key:          key,
+		expectedCode: http.StatusUnauthorized,
+		expectedKey:  helper.ERR_API_KEY_REVOKED,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(&models.APIKey{ID: 1, KeyHash: hash, ExpiresAt: expiresAt, RevokedAt: &revokedAt}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "expired key",
+		key:          key,
+		expectedCode: http.StatusUnauthorized,
+		expectedKey:  helper.ERR_API_KEY_EXPIRED,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(&models.APIKey{ID: 1, KeyHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "suspended owner",
+		key:          key,
+		expectedCode: http.StatusForbidden,
+		expectedKey:  helper.ERR_USER_SUSPENDED,
+		onGetAPIKeyByPrefix: func(mock *mocks.MockAPIKeyRepository) {
+			mock.EXPECT().GetAPIKeyByPrefix(prefix).Return(&models.APIKey{ID: 1, KeyHash: hash, ExpiresAt: expiresAt, OwnerUserID: &ownerUserID}, nil)
+		},
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(ownerUserID).Return(&models.User{UserID: ownerUserID, SuspendedAt: &suspendedAt}, nil)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			apiKeyRepo := mocks.NewMockAPIKeyRepository(mockCtrl)
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetAPIKeyByPrefix != nil {
+				tc.onGetAPIKeyByPrefix(apiKeyRepo)
+			}
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
+			if tc.onUpdateAPIKey != nil {
+				tc.onUpdateAPIKey(apiKeyRepo)
+			}
+
+			usecase := &APIKeyUsecase{apiKeyRepo: apiKeyRepo, userRepo: userRepo}
+
+			apiKey, owner, err := usecase.ValidateAPIKey(context.Background(), tc.key)
+
+			if tc.expectedCode != 0 {
+				assert.Nil(t, apiKey)
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tc.expectedCode, err.Code())
+					assert.Equal(t, string(tc.expectedKey), err.Key())
+				}
+			} else {
+				assert.Nil(t, err)
+				assert.NotNil(t, apiKey)
+				assert.Equal(t, tc.expectedOwner, owner != nil)
+			}
+		})
+	}
+}
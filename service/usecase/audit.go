@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/pkg/serror"
+	api "codepair-sinarmas/service"
+	"codepair-sinarmas/service/helper"
+)
+
+type AuditUsecase struct {
+	auditRepo api.AuditRepository
+}
+
+func NewAuditUsecase(
+	auditRepo api.AuditRepository,
+) api.AuditUsecase {
+	return &AuditUsecase{
+		auditRepo: auditRepo,
+	}
+}
+
+func (u *AuditUsecase) Record(ctx context.Context, event *models.AuditEvent) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	if event.IP == "" {
+		event.IP = helper.ClientIPFromContext(ctx)
+	}
+
+	if event.RequestID == "" {
+		event.RequestID = helper.RequestIDFromContext(ctx)
+	}
+
+	err := u.auditRepo.CreateAuditEvent(event)
+	if err != nil {
+		errx := hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][Record] Failed to record audit event, [action: %s, requestID: %s]", event.Action, event.RequestID)
+		helper.LoggerFromContext(ctx, helper.LogLayerUsecase).Err(errx)
+	}
+}
+
+func (u *AuditUsecase) GetAuditEvents(ctx context.Context, filter *models.AuditEventFilter) (events []models.AuditEvent, pagination models.PaginationResponse, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	if filter.Size < 1 || filter.Size > 100 {
+		filter.Size = 10
+	}
+
+	filter.StartDate, filter.EndDate = helper.PrepareDateFilters(filter.StartDate, filter.EndDate)
+
+	events, total, err := u.auditRepo.GetAuditEvents(filter)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][GetAuditEvents] Failed to get audit events, [action: %s]", filter.Action)
+		return
+	}
+
+	pagination = models.NewPaginationResponse(filter.Page, filter.Size, total, len(events))
+	return
+}
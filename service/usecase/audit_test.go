@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"codepair-sinarmas/models"
+	"codepair-sinarmas/service/helper"
+	"codepair-sinarmas/service/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditUsecase_Record(t *testing.T) {
+	type testCase struct {
+		name              string
+		ctx               context.Context
+		event             *models.AuditEvent
+		expectedIP        string
+		expectedRequestID string
+		onCreate          func(mock *mocks.MockAuditRepository)
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name: "fill request meta from context",
+		ctx:  helper.WithRequestMeta(context.Background(), "req-1", "10.0.0.1"),
+		event: &models.AuditEvent{
+			ActorID:  1,
+			TargetID: 1,
+			Action:   models.AuditActionLogin,
+		},
+		expectedIP:        "10.0.0.1",
+		expectedRequestID: "req-1",
+		onCreate: func(mock *mocks.MockAuditRepository) {
+			mock.EXPECT().CreateAuditEvent(gomock.Any()).Return(nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "keep explicit request meta",
+		ctx:  helper.WithRequestMeta(context.Background(), "req-2", "10.0.0.2"),
+		event: &models.AuditEvent{
+			Action:    models.AuditActionRegister,
+			IP:        "192.168.0.1",
+			RequestID: "req-explicit",
+		},
+		expectedIP:        "192.168.0.1",
+		expectedRequestID: "req-explicit",
+		onCreate: func(mock *mocks.MockAuditRepository) {
+			mock.EXPECT().CreateAuditEvent(gomock.Any()).Return(nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name: "failed to create audit event",
+		ctx:  context.Background(),
+		event: &models.AuditEvent{
+			Action: models.AuditActionOTPIssue,
+		},
+		onCreate: func(mock *mocks.MockAuditRepository) {
+			mock.EXPECT().CreateAuditEvent(gomock.Any()).Return(assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			auditRepo := mocks.NewMockAuditRepository(mockCtrl)
+
+			if tc.onCreate != nil {
+				tc.onCreate(auditRepo)
+			}
+
+			usecase := &AuditUsecase{auditRepo: auditRepo}
+			usecase.Record(tc.ctx, tc.event)
+
+			assert.Equal(t, tc.expectedIP, tc.event.IP)
+			assert.Equal(t, tc.expectedRequestID, tc.event.RequestID)
+		})
+	}
+}
+
+func Test_AuditUsecase_GetAuditEvents(t *testing.T) {
+	type testCase struct {
+		name               string
+		wantError          bool
+		filter             *models.AuditEventFilter
+		expectedEvents     []models.AuditEvent
+		expectedPagination models.PaginationResponse
+		onGetAuditEvents   func(mock *mocks.MockAuditRepository)
+	}
+
+	startDate := time.Date(2024, 1, 1, 10, 30, 0, 0, helper.JakartaLoc)
+	endDate := time.Date(2024, 1, 31, 18, 0, 0, 0, helper.JakartaLoc)
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		filter: &models.AuditEventFilter{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Action:    models.AuditActionLogin,
+			Page:      2,
+			Size:      1,
+		},
+		onGetAuditEvents: func(mock *mocks.MockAuditRepository) {
+			mock.EXPECT().GetAuditEvents(gomock.Any()).DoAndReturn(func(filter *models.AuditEventFilter) ([]models.AuditEvent, int64, error) {
+				assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, helper.JakartaLoc), filter.StartDate)
+				assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, helper.JakartaLoc), filter.EndDate)
+
+				return []models.AuditEvent{{ID: 2, Action: models.AuditActionLogin}}, 3, nil
+			})
+		},
+		expectedEvents: []models.AuditEvent{{ID: 2, Action: models.AuditActionLogin}},
+		expectedPagination: models.PaginationResponse{
+			CurrentPage:  2,
+			PageSize:     1,
+			TotalCount:   3,
+			TotalPages:   3,
+			FirstPage:    1,
+			NextPage:     3,
+			LastPage:     3,
+			CurrentCount: 1,
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "normalize invalid pagination",
+		wantError: false,
+		filter: &models.AuditEventFilter{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Page:      0,
+			Size:      1000,
+		},
+		onGetAuditEvents: func(mock *mocks.MockAuditRepository) {
+			mock.EXPECT().GetAuditEvents(gomock.Any()).DoAndReturn(func(filter *models.AuditEventFilter) ([]models.AuditEvent, int64, error) {
+				assert.Equal(t, 1, filter.Page)
+				assert.Equal(t, 10, filter.Size)
+
+				return nil, 0, nil
+			})
+		},
+		expectedPagination: models.PaginationResponse{
+			CurrentPage: 1,
+			PageSize:    10,
+			FirstPage:   1,
+			NextPage:    1,
+			LastPage:    1,
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to get audit events",
+		wantError: true,
+		filter: &models.AuditEventFilter{
+			StartDate: startDate,
+			EndDate:   endDate,
+		},
+		onGetAuditEvents: func(mock *mocks.MockAuditRepository) {
+			mock.EXPECT().GetAuditEvents(gomock.Any()).Return(nil, int64(0), assert.AnError)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			auditRepo := mocks.NewMockAuditRepository(mockCtrl)
+
+			if tc.onGetAuditEvents != nil {
+				tc.onGetAuditEvents(auditRepo)
+			}
+
+			usecase := &AuditUsecase{auditRepo: auditRepo}
+
+			events, pagination, err := usecase.GetAuditEvents(context.Background(), tc.filter)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedEvents, events)
+				assert.Equal(t, tc.expectedPagination, pagination)
+			}
+		})
+	}
+}
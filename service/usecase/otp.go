@@ -1,7 +1,9 @@
 package usecase
 
 import (
-	"net/http"
+	"context"
+	"crypto/subtle"
+	"errors"
 	"time"
 
 	"codepair-sinarmas/models"
@@ -14,114 +16,170 @@ import (
 )
 
 type OtpUsecase struct {
-	otpRepo  api.OtpRepository
-	userRepo api.UserRepository
+	otpRepo     api.OtpRepository
+	userRepo    api.UserRepository
+	otpNotifier api.OTPNotifier
+	auditLogger api.AuditLogger
 }
 
 func NewOtpUsecase(
 	otpRepo api.OtpRepository,
 	userRepo api.UserRepository,
+	otpNotifier api.OTPNotifier,
+	auditLogger api.AuditLogger,
 ) service.OTPUsecase {
 	return &OtpUsecase{
-		otpRepo:  otpRepo,
-		userRepo: userRepo,
+		otpRepo:     otpRepo,
+		userRepo:    userRepo,
+		otpNotifier: otpNotifier,
+		auditLogger: auditLogger,
 	}
 }
 
-func (u *OtpUsecase) RequestOtp(request *models.OTPRequest) (res *models.OTPResponse, errx serror.SError) {
-	user, err := u.userRepo.GetUserByID(request.UserID)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			errx = serror.Newi(http.StatusNotFound, "User not found")
-			return
-		}
-
-		errx = serror.NewFromError(err)
-		errx.AddCommentf("[usecase][RequestOtp] Failed to get user by email, [userID: %s]", request.UserID)
-		return
-	}
+// RequestOtp sends an OTP of the requested purpose to the user email, unknown emails get the same
+// answer so the endpoint does not reveal which accounts exist
+func (u *OtpUsecase) RequestOtp(ctx context.Context, request *models.OTPRequest) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
 
-	otp, err := u.otpRepo.GetOtpByUserID(user.UserID)
+	user, err := u.userRepo.GetUserByEmail(request.Email)
 	if err != nil {
-		errx = serror.NewFromError(err)
-		errx.AddCommentf("[usecase][RequestOtp] Failed to get otp by userID, [userID: %s]", request.UserID)
-		return
-	}
-
-	if otp.Status == "created" {
-		res = &models.OTPResponse{
-			UserID: user.UserID,
-			OTP:    otp.OTPCode,
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return
 		}
 
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][RequestOtp] Failed to get user by email, [purpose: %s]", request.Purpose)
 		return
 	}
 
-	otpArgs := &models.OTPLog{
+	// a new OTP is issued on every request, only the latest one of a purpose is accepted
+	otp, err := u.otpRepo.SaveOTP(&models.OTPLog{
 		UserID:           user.UserID,
 		NotificationType: models.NotificationTypeEmail,
+		Purpose:          request.Purpose,
 		OTPCode:          helper.GenerateOtpCode(),
 		Status:           "created",
 		CreatedAt:        time.Now(),
 		ExpiredAt:        time.Now().Add(time.Minute * 2),
-	}
-
-	otp, err = u.otpRepo.SaveOTP(otpArgs)
+	})
 	if err != nil {
-		errx = serror.NewFromError(err)
-		errx.AddCommentf("[usecase][RequestOtp] Failed to save otp, [userID: %s]", request.UserID)
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][RequestOtp] Failed to save otp, [userID: %d]", user.UserID)
 		return
 	}
 
-	res = &models.OTPResponse{
-		UserID: user.UserID,
-		OTP:    otp.OTPCode,
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  user.UserID,
+		TargetID: user.UserID,
+		Action:   models.AuditActionOTPIssue,
+		Changes: map[string]models.AuditChange{
+			"notification_type": {To: otp.NotificationType},
+			"purpose":           {To: otp.Purpose},
+			"status":            {To: otp.Status},
+		},
+	})
+
+	err = u.otpNotifier.SendOTP(ctx, user, otp)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][RequestOtp] Failed to send otp, [userID: %d]", user.UserID)
+		return
 	}
 
 	return
 }
 
-func (u *OtpUsecase) ValidateOtp(request *models.OTPValidateRequest) (valid bool, errx serror.SError) {
-	_, err := u.userRepo.GetUserByID(request.UserID)
+// ValidateOtp consumes a verification OTP, the password reset ones are only accepted by ResetPassword
+func (u *OtpUsecase) ValidateOtp(ctx context.Context, request *models.OTPValidateRequest) (valid bool, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	user, err := u.userRepo.GetUserByEmail(request.Email)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			errx = serror.Newi(http.StatusNotFound, "User not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_OTP_INVALID.New())
 			return
 		}
 
-		errx = serror.NewFromError(err)
-		errx.AddCommentf("[usecase][ValidateOtp] Failed to get otp by userID, [userID: %s]", request.UserID)
+		errx = hctx.CreateErrorEx(err)
+		errx.AddComments("[usecase][ValidateOtp] Failed to get user by email")
 		return
 	}
 
-	otpDB, err := u.otpRepo.GetOtpByUserIDAndCode(request.UserID, request.OTP)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			errx = serror.Newi(http.StatusNotFound, "Invalid OTP code")
-			return
+	otpDB, errx := verifyOtp(hctx, u.otpRepo, user.UserID, models.OTPPurposeVerification, request.OTP, "ValidateOtp")
+	if errx != nil {
+		if helper.ERR_OTP_INVALID.Is(errx) {
+			u.auditLogger.Record(ctx, &models.AuditEvent{
+				TargetID: user.UserID,
+				Action:   models.AuditActionOTPValidateFailed,
+			})
 		}
-		errx = serror.NewFromError(err)
-		errx.AddCommentf("[usecase][ValidateOtp] Failed to get otp by userID, [userID: %s]", request.UserID)
+
 		return
 	}
 
-	if time.Now().After(otpDB.ExpiredAt) {
-		errx = serror.Newi(http.StatusBadRequest, "OTP has expired")
+	consumed, err := u.otpRepo.ConsumeOtp(otpDB.ID)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ValidateOtp] Failed to update otp status, [userID: %d]", user.UserID)
 		return
 	}
 
-	if otpDB.Status == "validated" {
-		errx = serror.Newi(http.StatusBadRequest, "OTP has been validated")
+	if !consumed {
+		errx = hctx.SignError(helper.ERR_OTP_ALREADY_VALIDATED.New())
 		return
 	}
 
-	err = u.otpRepo.UpdateStatusOtpByUserIDAndCode(otpDB.ID, "validated")
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  user.UserID,
+		TargetID: user.UserID,
+		Action:   models.AuditActionOTPValidate,
+		Changes: map[string]models.AuditChange{
+			"status": {From: otpDB.Status, To: "validated"},
+		},
+	})
+
+	valid = true
+	return
+}
+
+// verifyOtp checks code against the latest OTP of purpose issued to the user, a wrong code counts as a failed
+// attempt and the OTP is invalidated once models.OTPMaxFailedAttempts is reached
+func verifyOtp(hctx serror.HCtx, otpRepo api.OtpRepository, userID int64, purpose, code, fn string) (otp *models.OTPLog, errx serror.SError) {
+	otp, err := otpRepo.GetOtpByUserID(userID, purpose)
 	if err != nil {
-		errx = serror.NewFromError(err)
-		errx.AddCommentf("[usecase][ValidateOtp] Failed to update otp status, [userID: %s]", request.UserID)
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][%s] Failed to get otp by userID, [userID: %d]", fn, userID)
+		return
+	}
+
+	if otp.ID == 0 || otp.Status == "invalidated" {
+		errx = hctx.SignError(helper.ERR_OTP_INVALID.New())
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(otp.OTPCode), []byte(code)) != 1 {
+		if otp.Status == "created" {
+			err = otpRepo.FailOtp(otp.ID, models.OTPMaxFailedAttempts)
+			if err != nil {
+				errx = hctx.CreateErrorEx(err)
+				errx.AddCommentf("[usecase][%s] Failed to count otp attempt, [userID: %d]", fn, userID)
+				return
+			}
+		}
+
+		errx = hctx.SignError(helper.ERR_OTP_INVALID.New())
+		return
+	}
+
+	if otp.Status == "validated" {
+		errx = hctx.SignError(helper.ERR_OTP_ALREADY_VALIDATED.New())
+		return
+	}
+
+	if time.Now().After(otp.ExpiredAt) {
+		errx = hctx.SignError(helper.ERR_OTP_EXPIRED.New())
 		return
 	}
 
-	valid = true
 	return
 }
@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"codepair-sinarmas/models"
+	"codepair-sinarmas/service/helper"
 	"codepair-sinarmas/service/repository/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -16,135 +18,111 @@ func Test_OTPUsecase_RequestOtp(t *testing.T) {
 	type testCase struct {
 		name             string
 		wantError        bool
-		expectedResponse *models.OTPResponse
 		request          *models.OTPRequest
-		onGetUserByID    func(mock *mocks.MockUserRepository)
-		onGetOtpByUserID func(mock *mocks.MockOtpRepository)
+		onGetUserByEmail func(mock *mocks.MockUserRepository)
 		onSaveOTP        func(mock *mocks.MockOtpRepository)
+		onSendOTP        func(mock *mocks.MockOTPNotifier)
+		onRecord         func(mock *mocks.MockAuditLogger)
 	}
 
 	var testTable []testCase
 	testTable = append(testTable, testCase{
-		name:      "success with no otp log",
+		name:      "success",
 		wantError: false,
 		request: &models.OTPRequest{
-			UserID: 1,
+			Email:   "john@example.com",
+			Purpose: models.OTPPurposePasswordReset,
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(1)).Return(&models.User{
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("john@example.com").Return(&models.User{
 				UserID: 1,
 				Name:   "John Doe",
+				Email:  "john@example.com",
 			}, nil)
 		},
-		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserID(int64(1)).Return(&models.OTPLog{}, nil)
-		},
 		onSaveOTP: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().SaveOTP(gomock.Any()).Return(&models.OTPLog{
-				UserID:           1,
-				OTPCode:          "123456",
-				NotificationType: models.NotificationTypeEmail,
-				Status:           "created",
-			}, nil)
-		},
-		expectedResponse: &models.OTPResponse{
-			UserID: 1,
-			OTP:    "123456",
+			mock.EXPECT().SaveOTP(gomock.Any()).DoAndReturn(func(otp *models.OTPLog) (*models.OTPLog, error) {
+				assert.Equal(t, int64(1), otp.UserID)
+				assert.Equal(t, models.OTPPurposePasswordReset, otp.Purpose)
+				assert.Len(t, otp.OTPCode, 6)
+				return otp, nil
+			})
+		},
+		onSendOTP: func(mock *mocks.MockOTPNotifier) {
+			mock.EXPECT().SendOTP(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user *models.User, otp *models.OTPLog) error {
+				assert.Equal(t, "john@example.com", user.Email)
+				assert.Equal(t, models.OTPPurposePasswordReset, otp.Purpose)
+				return nil
+			})
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionOTPIssue, event.Action)
+				assert.Equal(t, int64(1), event.TargetID)
+			})
 		},
 	})
 
 	testTable = append(testTable, testCase{
-		name:      "user not found",
-		wantError: true,
+		name:      "unknown email is not revealed",
+		wantError: false,
 		request: &models.OTPRequest{
-			UserID: 2,
+			Email:   "nobody@example.com",
+			Purpose: models.OTPPurposePasswordReset,
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(2)).Return(nil, gorm.ErrRecordNotFound)
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
 		},
-		onGetOtpByUserID: nil,
-		onSaveOTP:        nil,
-		expectedResponse: nil,
 	})
 
 	testTable = append(testTable, testCase{
-		name:      "failed to get user by ID",
+		name:      "failed to get user by email",
 		wantError: true,
 		request: &models.OTPRequest{
-			UserID: 3,
+			Email:   "john@example.com",
+			Purpose: models.OTPPurposeVerification,
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(3)).Return(nil, assert.AnError)
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("john@example.com").Return(nil, assert.AnError)
 		},
-		onGetOtpByUserID: nil,
-		onSaveOTP:        nil,
-		expectedResponse: nil,
 	})
 
 	testTable = append(testTable, testCase{
-		name:      "otp already created",
-		wantError: false,
-		request: &models.OTPRequest{
-			UserID: 4,
-		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(4)).Return(&models.User{
-				UserID: 4,
-				Name:   "Jane Doe",
-			}, nil)
-		},
-		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserID(int64(4)).Return(&models.OTPLog{
-				UserID:  4,
-				OTPCode: "654321",
-				Status:  "created",
-			}, nil)
-		},
-		onSaveOTP: nil,
-		expectedResponse: &models.OTPResponse{
-			UserID: 4,
-			OTP:    "654321",
-		},
-	})
-
-	testTable = append(testTable, testCase{
-		name:      "failed to get otp by user ID",
+		name:      "failed to save otp",
 		wantError: true,
 		request: &models.OTPRequest{
-			UserID: 5,
+			Email:   "bob@example.com",
+			Purpose: models.OTPPurposeVerification,
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(5)).Return(&models.User{
-				UserID: 5,
-				Name:   "Alice",
-			}, nil)
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("bob@example.com").Return(&models.User{UserID: 6, Name: "Bob"}, nil)
 		},
-		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserID(int64(5)).Return(nil, assert.AnError)
+		onSaveOTP: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().SaveOTP(gomock.Any()).Return(nil, assert.AnError)
 		},
-		onSaveOTP:        nil,
-		expectedResponse: nil,
 	})
 
 	testTable = append(testTable, testCase{
-		name:      "failed to save otp",
+		name:      "failed to send otp",
 		wantError: true,
 		request: &models.OTPRequest{
-			UserID: 6,
-		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(6)).Return(&models.User{
-				UserID: 6,
-				Name:   "Bob",
-			}, nil)
+			Email:   "bob@example.com",
+			Purpose: models.OTPPurposeVerification,
 		},
-		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserID(int64(6)).Return(&models.OTPLog{}, nil)
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("bob@example.com").Return(&models.User{UserID: 6, Name: "Bob"}, nil)
 		},
 		onSaveOTP: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().SaveOTP(gomock.Any()).Return(nil, assert.AnError)
+			mock.EXPECT().SaveOTP(gomock.Any()).DoAndReturn(func(otp *models.OTPLog) (*models.OTPLog, error) {
+				return otp, nil
+			})
+		},
+		onSendOTP: func(mock *mocks.MockOTPNotifier) {
+			mock.EXPECT().SendOTP(gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any())
 		},
-		expectedResponse: nil,
 	})
 
 	for _, tc := range testTable {
@@ -154,45 +132,54 @@ func Test_OTPUsecase_RequestOtp(t *testing.T) {
 
 			otpRepo := mocks.NewMockOtpRepository(mockCtrl)
 			userRepo := mocks.NewMockUserRepository(mockCtrl)
+			otpNotifier := mocks.NewMockOTPNotifier(mockCtrl)
 
-			if tc.onGetUserByID != nil {
-				tc.onGetUserByID(userRepo)
-			}
-
-			if tc.onGetOtpByUserID != nil {
-				tc.onGetOtpByUserID(otpRepo)
+			if tc.onGetUserByEmail != nil {
+				tc.onGetUserByEmail(userRepo)
 			}
 
 			if tc.onSaveOTP != nil {
 				tc.onSaveOTP(otpRepo)
 			}
 
+			if tc.onSendOTP != nil {
+				tc.onSendOTP(otpNotifier)
+			}
+
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
+			}
+
 			usecase := &OtpUsecase{
-				otpRepo:  otpRepo,
-				userRepo: userRepo,
+				otpRepo:     otpRepo,
+				userRepo:    userRepo,
+				otpNotifier: otpNotifier,
+				auditLogger: auditLogger,
 			}
 
-			resp, err := usecase.RequestOtp(tc.request)
+			err := usecase.RequestOtp(context.Background(), tc.request)
 
 			if tc.wantError {
 				assert.NotNil(t, err)
-				assert.Nil(t, resp)
 			} else {
 				assert.Nil(t, err)
-				assert.Equal(t, tc.expectedResponse, resp)
 			}
 		})
 	}
 }
+
 func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 	type testCase struct {
-		name                    string
-		wantValid               bool
-		wantError               bool
-		request                 *models.OTPValidateRequest
-		onGetUserByID           func(mock *mocks.MockUserRepository)
-		onGetOtpByUserIDAndCode func(mock *mocks.MockOtpRepository)
-		onUpdateStatusOtpByID   func(mock *mocks.MockOtpRepository)
+		name             string
+		wantValid        bool
+		wantError        bool
+		request          *models.OTPValidateRequest
+		onGetUserByEmail func(mock *mocks.MockUserRepository)
+		onGetOtpByUserID func(mock *mocks.MockOtpRepository)
+		onFailOtp        func(mock *mocks.MockOtpRepository)
+		onConsumeOtp     func(mock *mocks.MockOtpRepository)
+		onRecord         func(mock *mocks.MockAuditLogger)
 	}
 
 	var testTable []testCase
@@ -201,26 +188,32 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 		wantValid: true,
 		wantError: false,
 		request: &models.OTPValidateRequest{
-			UserID: 1,
-			OTP:    "123456",
+			Email: "john@example.com",
+			OTP:   "123456",
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(1)).Return(&models.User{
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("john@example.com").Return(&models.User{
 				UserID: 1,
 				Name:   "John Doe",
 			}, nil)
 		},
-		onGetOtpByUserIDAndCode: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserIDAndCode(int64(1), "123456").Return(&models.OTPLog{
-				ID:        1,
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(1), models.OTPPurposeVerification).Return(&models.OTPLog{
+				ID:        10,
 				UserID:    1,
 				OTPCode:   "123456",
 				Status:    "created",
 				ExpiredAt: time.Now().Add(time.Minute * 5),
 			}, nil)
 		},
-		onUpdateStatusOtpByID: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().UpdateStatusOtpByUserIDAndCode(int64(1), "validated").Return(nil)
+		onConsumeOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().ConsumeOtp(int64(10)).Return(true, nil)
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionOTPValidate, event.Action)
+				assert.Equal(t, int64(1), event.TargetID)
+			})
 		},
 	})
 
@@ -229,14 +222,12 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 		wantValid: false,
 		wantError: true,
 		request: &models.OTPValidateRequest{
-			UserID: 2,
-			OTP:    "654321",
+			Email: "nobody@example.com",
+			OTP:   "654321",
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(2)).Return(nil, gorm.ErrRecordNotFound)
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
 		},
-		onGetOtpByUserIDAndCode: nil,
-		onUpdateStatusOtpByID:   nil,
 	})
 
 	testTable = append(testTable, testCase{
@@ -244,19 +235,121 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 		wantValid: false,
 		wantError: true,
 		request: &models.OTPValidateRequest{
-			UserID: 3,
-			OTP:    "111111",
+			Email: "alice@example.com",
+			OTP:   "111111",
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(3)).Return(&models.User{
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{
 				UserID: 3,
 				Name:   "Alice",
 			}, nil)
 		},
-		onGetOtpByUserIDAndCode: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserIDAndCode(int64(3), "111111").Return(nil, gorm.ErrRecordNotFound)
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposeVerification).Return(&models.OTPLog{}, nil)
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionOTPValidateFailed, event.Action)
+				assert.Equal(t, int64(3), event.TargetID)
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "wrong code counts a failed attempt",
+		wantValid: false,
+		wantError: true,
+		request: &models.OTPValidateRequest{
+			Email: "alice@example.com",
+			OTP:   "000000",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{UserID: 3}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposeVerification).Return(&models.OTPLog{
+				ID:        3,
+				UserID:    3,
+				OTPCode:   "111111",
+				Status:    "created",
+				ExpiredAt: time.Now().Add(time.Minute * 5),
+			}, nil)
+		},
+		onFailOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().FailOtp(int64(3), models.OTPMaxFailedAttempts).Return(nil)
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionOTPValidateFailed, event.Action)
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to count the failed attempt",
+		wantValid: false,
+		wantError: true,
+		request: &models.OTPValidateRequest{
+			Email: "alice@example.com",
+			OTP:   "000000",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{UserID: 3}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposeVerification).Return(&models.OTPLog{
+				ID:        3,
+				UserID:    3,
+				OTPCode:   "111111",
+				Status:    "created",
+				ExpiredAt: time.Now().Add(time.Minute * 5),
+			}, nil)
+		},
+		onFailOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().FailOtp(int64(3), models.OTPMaxFailedAttempts).Return(assert.AnError)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "invalidated otp rejects the right code",
+		wantValid: false,
+		wantError: true,
+		request: &models.OTPValidateRequest{
+			Email: "alice@example.com",
+			OTP:   "111111",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{UserID: 3}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposeVerification).Return(&models.OTPLog{
+				ID:             3,
+				UserID:         3,
+				OTPCode:        "111111",
+				Status:         "invalidated",
+				FailedAttempts: models.OTPMaxFailedAttempts,
+				ExpiredAt:      time.Now().Add(time.Minute * 5),
+			}, nil)
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any())
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "failed to get otp by user ID",
+		wantValid: false,
+		wantError: true,
+		request: &models.OTPValidateRequest{
+			Email: "alice@example.com",
+			OTP:   "111111",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{UserID: 3}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposeVerification).Return(nil, assert.AnError)
 		},
-		onUpdateStatusOtpByID: nil,
 	})
 
 	testTable = append(testTable, testCase{
@@ -264,17 +357,17 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 		wantValid: false,
 		wantError: true,
 		request: &models.OTPValidateRequest{
-			UserID: 4,
-			OTP:    "222222",
+			Email: "bob@example.com",
+			OTP:   "222222",
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(4)).Return(&models.User{
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("bob@example.com").Return(&models.User{
 				UserID: 4,
 				Name:   "Bob",
 			}, nil)
 		},
-		onGetOtpByUserIDAndCode: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserIDAndCode(int64(4), "222222").Return(&models.OTPLog{
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(4), models.OTPPurposeVerification).Return(&models.OTPLog{
 				ID:        4,
 				UserID:    4,
 				OTPCode:   "222222",
@@ -282,7 +375,6 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 				ExpiredAt: time.Now().Add(-time.Minute * 1),
 			}, nil)
 		},
-		onUpdateStatusOtpByID: nil,
 	})
 
 	testTable = append(testTable, testCase{
@@ -290,17 +382,17 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 		wantValid: false,
 		wantError: true,
 		request: &models.OTPValidateRequest{
-			UserID: 5,
-			OTP:    "333333",
+			Email: "charlie@example.com",
+			OTP:   "333333",
 		},
-		onGetUserByID: func(mock *mocks.MockUserRepository) {
-			mock.EXPECT().GetUserByID(int64(5)).Return(&models.User{
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("charlie@example.com").Return(&models.User{
 				UserID: 5,
 				Name:   "Charlie",
 			}, nil)
 		},
-		onGetOtpByUserIDAndCode: func(mock *mocks.MockOtpRepository) {
-			mock.EXPECT().GetOtpByUserIDAndCode(int64(5), "333333").Return(&models.OTPLog{
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(5), models.OTPPurposeVerification).Return(&models.OTPLog{
 				ID:        5,
 				UserID:    5,
 				OTPCode:   "333333",
@@ -308,7 +400,31 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 				ExpiredAt: time.Now().Add(time.Minute * 5),
 			}, nil)
 		},
-		onUpdateStatusOtpByID: nil,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "otp consumed concurrently",
+		wantValid: false,
+		wantError: true,
+		request: &models.OTPValidateRequest{
+			Email: "charlie@example.com",
+			OTP:   "444444",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("charlie@example.com").Return(&models.User{UserID: 5}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(5), models.OTPPurposeVerification).Return(&models.OTPLog{
+				ID:        6,
+				UserID:    5,
+				OTPCode:   "444444",
+				Status:    "created",
+				ExpiredAt: time.Now().Add(time.Minute * 5),
+			}, nil)
+		},
+		onConsumeOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().ConsumeOtp(int64(6)).Return(false, nil)
+		},
 	})
 
 	for _, tc := range testTable {
@@ -319,24 +435,34 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 			otpRepo := mocks.NewMockOtpRepository(mockCtrl)
 			userRepo := mocks.NewMockUserRepository(mockCtrl)
 
-			if tc.onGetUserByID != nil {
-				tc.onGetUserByID(userRepo)
+			if tc.onGetUserByEmail != nil {
+				tc.onGetUserByEmail(userRepo)
 			}
 
-			if tc.onGetOtpByUserIDAndCode != nil {
-				tc.onGetOtpByUserIDAndCode(otpRepo)
+			if tc.onGetOtpByUserID != nil {
+				tc.onGetOtpByUserID(otpRepo)
+			}
+
+			if tc.onFailOtp != nil {
+				tc.onFailOtp(otpRepo)
+			}
+
+			if tc.onConsumeOtp != nil {
+				tc.onConsumeOtp(otpRepo)
 			}
 
-			if tc.onUpdateStatusOtpByID != nil {
-				tc.onUpdateStatusOtpByID(otpRepo)
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
 			}
 
 			usecase := &OtpUsecase{
-				otpRepo:  otpRepo,
-				userRepo: userRepo,
+				otpRepo:     otpRepo,
+				userRepo:    userRepo,
+				auditLogger: auditLogger,
 			}
 
-			valid, err := usecase.ValidateOtp(tc.request)
+			valid, err := usecase.ValidateOtp(context.Background(), tc.request)
 
 			if tc.wantError {
 				assert.NotNil(t, err)
@@ -348,3 +474,55 @@ func Test_OTPUsecase_ValidateOtp(t *testing.T) {
 		})
 	}
 }
+
+func Test_OTPUsecase_ValidateOtp_Lockout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	otpRepo := mocks.NewMockOtpRepository(mockCtrl)
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+
+	// the repository keeps a single OTP and applies FailOtp the way the database does
+	otp := &models.OTPLog{
+		ID:        7,
+		UserID:    7,
+		OTPCode:   "123456",
+		Purpose:   models.OTPPurposeVerification,
+		Status:    "created",
+		ExpiredAt: time.Now().Add(time.Minute * 5),
+	}
+
+	userRepo.EXPECT().GetUserByEmail("dave@example.com").Return(&models.User{UserID: 7}, nil).AnyTimes()
+	otpRepo.EXPECT().GetOtpByUserID(int64(7), models.OTPPurposeVerification).DoAndReturn(func(int64, string) (*models.OTPLog, error) {
+		copied := *otp
+		return &copied, nil
+	}).AnyTimes()
+	otpRepo.EXPECT().FailOtp(int64(7), models.OTPMaxFailedAttempts).DoAndReturn(func(_ int64, maxAttempts int) error {
+		otp.FailedAttempts++
+		if otp.FailedAttempts >= maxAttempts {
+			otp.Status = "invalidated"
+		}
+		return nil
+	}).Times(models.OTPMaxFailedAttempts)
+	otpRepo.EXPECT().ConsumeOtp(gomock.Any()).Times(0)
+	auditLogger.EXPECT().Record(gomock.Any(), gomock.Any()).Times(models.OTPMaxFailedAttempts + 1)
+
+	usecase := &OtpUsecase{
+		otpRepo:     otpRepo,
+		userRepo:    userRepo,
+		auditLogger: auditLogger,
+	}
+
+	for i := 0; i < models.OTPMaxFailedAttempts; i++ {
+		valid, err := usecase.ValidateOtp(context.Background(), &models.OTPValidateRequest{Email: "dave@example.com", OTP: "000000"})
+		assert.False(t, valid)
+		assert.True(t, helper.ERR_OTP_INVALID.Is(err))
+	}
+
+	assert.Equal(t, "invalidated", otp.Status)
+
+	valid, err := usecase.ValidateOtp(context.Background(), &models.OTPValidateRequest{Email: "dave@example.com", OTP: "123456"})
+	assert.False(t, valid)
+	assert.True(t, helper.ERR_OTP_INVALID.Is(err))
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"codepair-sinarmas/models"
@@ -12,18 +14,26 @@ import (
 )
 
 type UserUsecase struct {
-	userRepo api.UserRepository
+	userRepo    api.UserRepository
+	otpRepo     api.OtpRepository
+	auditLogger api.AuditLogger
 }
 
 func NewUserUsecase(
 	userRepo api.UserRepository,
+	otpRepo api.OtpRepository,
+	auditLogger api.AuditLogger,
 ) api.UserUsecase {
 	return &UserUsecase{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		otpRepo:     otpRepo,
+		auditLogger: auditLogger,
 	}
 }
 
-func (u *UserUsecase) Register(request *models.RegisterUser) (user *models.User, errx serror.SError) {
+func (u *UserUsecase) Register(ctx context.Context, request *models.RegisterUser) (user *models.User, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
 	userArgs := &models.User{
 		Name:     request.Name,
 		Email:    request.Email,
@@ -31,47 +41,94 @@ func (u *UserUsecase) Register(request *models.RegisterUser) (user *models.User,
 	}
 
 	userCheck, err := u.userRepo.GetUserByEmail(request.Email)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		errx = serror.NewFromError(err)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		errx = hctx.CreateErrorEx(err)
 		errx.AddCommentf("[usecase][Register] Failed to get user by email, [email: %s]", request.Email)
 		return
 	}
 
 	if userCheck.UserID != 0 {
-		errx = serror.Newi(http.StatusBadRequest, "Email already registered")
+		errx = hctx.SignError(helper.ERR_USER_EMAIL_TAKEN.New())
 		return
 	}
 
 	user, err = u.userRepo.Register(userArgs)
 	if err != nil {
-		errx = serror.NewFromError(err)
+		errx = hctx.CreateErrorEx(err)
 		errx.AddCommentf("[usecase][Register] Failed to register user, [email: %s]", request.Email)
 		return
 	}
 
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  user.UserID,
+		TargetID: user.UserID,
+		Action:   models.AuditActionRegister,
+		Changes: map[string]models.AuditChange{
+			"name":  {To: user.Name},
+			"email": {To: user.Email},
+			"role":  {To: user.Role},
+		},
+	})
+
 	return
 }
 
-func (u *UserUsecase) Login(request *models.LoginUser) (res models.LoginResponse, errx serror.SError) {
+func (u *UserUsecase) Login(ctx context.Context, request *models.LoginUser) (res models.LoginResponse, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
 	userDB, err := u.userRepo.GetUserByEmail(request.Email)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			errx = serror.Newi(http.StatusNotFound, "User not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_USER_NOT_FOUND.New())
 			return
 		}
 
-		errx = serror.NewFromError(err)
+		errx = hctx.CreateErrorEx(err)
 		errx.AddCommentf("[usecase][Login] Failed to get user by email, [email: %s]", request.Email)
 		return
 	}
 
-	accountMatch := helper.ComparePassword([]byte(userDB.Password), []byte(request.Password))
+	accountMatch, needsRehash, errx := helper.VerifyPassword(userDB.Password, request.Password)
+	if errx != nil {
+		errx.AddCommentf("[usecase][Login] Failed to verify password, [userID: %d]", userDB.UserID)
+		return
+	}
+
 	if !accountMatch {
-		errx = serror.Newi(http.StatusBadRequest, "Password does not match")
+		u.auditLogger.Record(ctx, &models.AuditEvent{
+			TargetID: userDB.UserID,
+			Action:   models.AuditActionLoginFailed,
+		})
+
+		errx = hctx.SignError(helper.ERR_USER_PASSWORD_MISMATCH.New())
+		return
+	}
+
+	if userDB.SuspendedAt != nil {
+		errx = hctx.SignError(helper.ERR_USER_SUSPENDED.New())
 		return
 	}
 
-	token := helper.GenerateToken(userDB.UserID, userDB.Email, userDB.Name)
+	if userDB.PasswordResetRequired {
+		errx = hctx.SignError(helper.ERR_USER_PASSWORD_RESET_REQUIRED.New())
+		return
+	}
+
+	if needsRehash {
+		u.rehashPassword(ctx, userDB, request.Password)
+	}
+
+	token, errx := helper.GenerateToken(userDB.UserID, userDB.Email, userDB.Name, userDB.Role, userDB.TokenVersion)
+	if errx != nil {
+		errx.AddCommentf("[usecase][Login] Failed to generate token, [userID: %d]", userDB.UserID)
+		return
+	}
+
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  userDB.UserID,
+		TargetID: userDB.UserID,
+		Action:   models.AuditActionLogin,
+	})
 
 	res = models.LoginResponse{
 		Token: token,
@@ -80,3 +137,125 @@ func (u *UserUsecase) Login(request *models.LoginUser) (res models.LoginResponse
 
 	return
 }
+
+// ResetPassword sets the password of the owner of a password reset OTP, the email only narrows the OTP lookup
+func (u *UserUsecase) ResetPassword(ctx context.Context, request *models.ResetPasswordRequest) (errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	user, err := u.userRepo.GetUserByEmail(request.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_OTP_INVALID.New())
+			return
+		}
+
+		errx = hctx.CreateErrorEx(err)
+		errx.AddComments("[usecase][ResetPassword] Failed to get user by email")
+		return
+	}
+
+	otpDB, errx := verifyOtp(hctx, u.otpRepo, user.UserID, models.OTPPurposePasswordReset, request.OTP, "ResetPassword")
+	if errx != nil {
+		return
+	}
+
+	if err := helper.CheckPassword(request.Password, user.Email, user.Name); err != nil {
+		errx = hctx.SignError(serror.NewFromErrori(http.StatusUnprocessableEntity, err))
+		return
+	}
+
+	hash, errx := helper.HashPassword(request.Password)
+	if errx != nil {
+		errx.AddCommentf("[usecase][ResetPassword] Failed to hash password, [userID: %d]", otpDB.UserID)
+		return
+	}
+
+	// the OTP is consumed before the update so a code racing with itself changes the password once
+	consumed, err := u.otpRepo.ConsumeOtp(otpDB.ID)
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ResetPassword] Failed to update otp status, [userID: %d]", otpDB.UserID)
+		return
+	}
+
+	if !consumed {
+		errx = hctx.SignError(helper.ERR_OTP_ALREADY_VALIDATED.New())
+		return
+	}
+
+	err = u.userRepo.UpdateUser(otpDB.UserID, map[string]interface{}{
+		"password":                hash,
+		"password_reset_required": false,
+		"token_version":           gorm.Expr("token_version + 1"),
+	})
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ResetPassword] Failed to update password, [userID: %d]", otpDB.UserID)
+		return
+	}
+
+	u.auditLogger.Record(ctx, &models.AuditEvent{
+		ActorID:  otpDB.UserID,
+		TargetID: otpDB.UserID,
+		Action:   models.AuditActionPasswordChange,
+		Changes: map[string]models.AuditChange{
+			"password_reset_required": {From: user.PasswordResetRequired, To: false},
+		},
+	})
+
+	return
+}
+
+func (u *UserUsecase) ValidateSession(ctx context.Context, userID int64, tokenVersion int64) (user *models.User, errx serror.SError) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errx = hctx.SignError(helper.ERR_TOKEN_INVALID.New())
+			return
+		}
+
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][ValidateSession] Failed to get user by ID, [userID: %d]", userID)
+		return
+	}
+
+	if user.SuspendedAt != nil {
+		errx = hctx.SignError(helper.ERR_USER_SUSPENDED.New())
+		return
+	}
+
+	if user.TokenVersion != tokenVersion {
+		errx = hctx.SignError(helper.ERR_SESSION_REVOKED.New())
+		return
+	}
+
+	return
+}
+
+// private
+
+// rehashPassword upgrades a stored hash produced with outdated parameters, failures only get logged since the login itself succeeded
+func (u *UserUsecase) rehashPassword(ctx context.Context, user *models.User, password string) {
+	hctx := helper.ErrorContextFromContext(ctx, helper.LogLayerUsecase)
+
+	hash, errx := helper.HashPassword(password)
+	if errx != nil {
+		errx.AddCommentf("[usecase][rehashPassword] Failed to rehash password, [userID: %d]", user.UserID)
+		helper.LoggerFromContext(ctx, helper.LogLayerUsecase).Warn(errx)
+		return
+	}
+
+	err := u.userRepo.UpdateUser(user.UserID, map[string]interface{}{
+		"password": hash,
+	})
+	if err != nil {
+		errx = hctx.CreateErrorEx(err)
+		errx.AddCommentf("[usecase][rehashPassword] Failed to update password hash, [userID: %d]", user.UserID)
+		helper.LoggerFromContext(ctx, helper.LogLayerUsecase).Warn(errx)
+		return
+	}
+
+	user.Password = hash
+}
@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"codepair-sinarmas/models"
 	"codepair-sinarmas/service/helper"
@@ -10,9 +12,19 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+func mustHashPassword(password string) string {
+	hash, errx := helper.HashPassword(password)
+	if errx != nil {
+		panic(errx)
+	}
+
+	return hash
+}
+
 func Test_UserUsecase_Register(t *testing.T) {
 	type testCase struct {
 		name             string
@@ -21,6 +33,7 @@ func Test_UserUsecase_Register(t *testing.T) {
 		request          *models.RegisterUser
 		onRegister       func(mock *mocks.MockUserRepository)
 		onGetUserByEmail func(mock *mocks.MockUserRepository)
+		onRecord         func(mock *mocks.MockAuditLogger)
 	}
 
 	var testTable []testCase
@@ -46,6 +59,12 @@ func Test_UserUsecase_Register(t *testing.T) {
 				Email:  "john@example.com",
 			}, nil)
 		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionRegister, event.Action)
+				assert.Equal(t, int64(1), event.TargetID)
+			})
+		},
 		expectedResponse: &models.User{
 			UserID: 1,
 			Name:   "John Doe",
@@ -96,9 +115,14 @@ func Test_UserUsecase_Register(t *testing.T) {
 				tc.onRegister(userRepo)
 			}
 
-			usecase := &UserUsecase{userRepo: userRepo}
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
+			}
 
-			resp, err := usecase.Register(tc.request)
+			usecase := &UserUsecase{userRepo: userRepo, auditLogger: auditLogger}
+
+			resp, err := usecase.Register(context.Background(), tc.request)
 
 			if tc.wantError {
 				assert.NotNil(t, err)
@@ -118,6 +142,7 @@ func Test_UserUsecase_Login(t *testing.T) {
 		expectedResponse *models.LoginResponse
 		request          *models.LoginUser
 		onGetUserByEmail func(mock *mocks.MockUserRepository)
+		onRecord         func(mock *mocks.MockAuditLogger)
 	}
 
 	var testTable []testCase
@@ -145,10 +170,85 @@ func Test_UserUsecase_Login(t *testing.T) {
 				UserID:   1,
 				Name:     "John Doe",
 				Email:    "john@example.com",
-				Password: helper.HashPassword("password"),
+				Password: mustHashPassword("password"),
 			}
 			mock.EXPECT().GetUserByEmail("john@example.com").Return(mockUser, nil)
 		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionLoginFailed, event.Action)
+				assert.Equal(t, int64(1), event.TargetID)
+			})
+		},
+		expectedResponse: nil,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "success with outdated hash gets rehashed",
+		wantError: false,
+		request: &models.LoginUser{
+			Email:    "legacy@example.com",
+			Password: "password",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			legacyHash, _ := helper.NewBcryptHasher(bcrypt.MinCost).Hash("password")
+			mockUser := &models.User{
+				UserID:   5,
+				Email:    "legacy@example.com",
+				Password: legacyHash,
+			}
+			mock.EXPECT().GetUserByEmail("legacy@example.com").Return(mockUser, nil)
+			mock.EXPECT().UpdateUser(int64(5), gomock.Any()).DoAndReturn(func(_ int64, values map[string]interface{}) error {
+				cost, err := bcrypt.Cost([]byte(values["password"].(string)))
+				assert.Nil(t, err)
+				assert.Equal(t, helper.DEFAULT_BCRYPT_COST, cost)
+				return nil
+			})
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionLogin, event.Action)
+				assert.Equal(t, int64(5), event.TargetID)
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "user suspended",
+		wantError: true,
+		request: &models.LoginUser{
+			Email:    "suspended@example.com",
+			Password: "password",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			suspendedAt := time.Now()
+			mockUser := &models.User{
+				UserID:      3,
+				Email:       "suspended@example.com",
+				Password:    mustHashPassword("password"),
+				SuspendedAt: &suspendedAt,
+			}
+			mock.EXPECT().GetUserByEmail("suspended@example.com").Return(mockUser, nil)
+		},
+		expectedResponse: nil,
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "password reset required",
+		wantError: true,
+		request: &models.LoginUser{
+			Email:    "reset@example.com",
+			Password: "password",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mockUser := &models.User{
+				UserID:                4,
+				Email:                 "reset@example.com",
+				Password:              mustHashPassword("password"),
+				PasswordResetRequired: true,
+			}
+			mock.EXPECT().GetUserByEmail("reset@example.com").Return(mockUser, nil)
+		},
 		expectedResponse: nil,
 	})
 
@@ -176,15 +276,320 @@ func Test_UserUsecase_Login(t *testing.T) {
 				tc.onGetUserByEmail(userRepo)
 			}
 
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
+			}
+
+			usecase := &UserUsecase{userRepo: userRepo, auditLogger: auditLogger}
+
+			resp, err := usecase.Login(context.Background(), tc.request)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.NotEmpty(t, resp.Token)
+				if tc.expectedResponse != nil {
+					assert.Equal(t, *tc.expectedResponse, resp)
+				}
+			}
+		})
+	}
+}
+
+func Test_UserUsecase_ResetPassword(t *testing.T) {
+	type testCase struct {
+		name             string
+		wantError        bool
+		request          *models.ResetPasswordRequest
+		onGetUserByEmail func(mock *mocks.MockUserRepository)
+		onGetOtpByUserID func(mock *mocks.MockOtpRepository)
+		onFailOtp        func(mock *mocks.MockOtpRepository)
+		onConsumeOtp     func(mock *mocks.MockOtpRepository)
+		onUpdateUser     func(mock *mocks.MockUserRepository)
+		onRecord         func(mock *mocks.MockAuditLogger)
+	}
+
+	validOtp := func(userID int64) *models.OTPLog {
+		return &models.OTPLog{
+			ID:        userID * 10,
+			UserID:    userID,
+			OTPCode:   "123456",
+			Purpose:   models.OTPPurposePasswordReset,
+			Status:    "created",
+			ExpiredAt: time.Now().Add(time.Minute * 2),
+		}
+	}
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:      "success",
+		wantError: false,
+		request: &models.ResetPasswordRequest{
+			Email:    "john@example.com",
+			OTP:      "123456",
+			Password: "N3wPassphrase",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("john@example.com").Return(&models.User{
+				UserID:                1,
+				Email:                 "john@example.com",
+				PasswordResetRequired: true,
+				TokenVersion:          2,
+			}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(1), models.OTPPurposePasswordReset).Return(validOtp(1), nil)
+		},
+		onConsumeOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().ConsumeOtp(int64(10)).Return(true, nil)
+		},
+		onUpdateUser: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().UpdateUser(int64(1), gomock.Any()).DoAndReturn(func(_ int64, values map[string]interface{}) error {
+				assert.True(t, helper.ComparePassword([]byte(values["password"].(string)), []byte("N3wPassphrase")))
+				assert.Equal(t, false, values["password_reset_required"])
+				assert.Equal(t, gorm.Expr("token_version + 1"), values["token_version"])
+				return nil
+			})
+		},
+		onRecord: func(mock *mocks.MockAuditLogger) {
+			mock.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *models.AuditEvent) {
+				assert.Equal(t, models.AuditActionPasswordChange, event.Action)
+				assert.Equal(t, int64(1), event.TargetID)
+			})
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "user not found",
+		wantError: true,
+		request: &models.ResetPasswordRequest{
+			Email:    "nobody@example.com",
+			OTP:      "123456",
+			Password: "N3wPassphrase",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "password contains email",
+		wantError: true,
+		request: &models.ResetPasswordRequest{
+			Email:    "johnny@example.com",
+			OTP:      "123456",
+			Password: "Johnny2024",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("johnny@example.com").Return(&models.User{UserID: 5, Email: "johnny@example.com"}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(5), models.OTPPurposePasswordReset).Return(validOtp(5), nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "invalid otp",
+		wantError: true,
+		request: &models.ResetPasswordRequest{
+			Email:    "alice@example.com",
+			OTP:      "000000",
+			Password: "N3wPassphrase",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{UserID: 3}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposePasswordReset).Return(validOtp(3), nil)
+		},
+		onFailOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().FailOtp(int64(30), models.OTPMaxFailedAttempts).Return(nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "otp invalidated after too many failed attempts",
+		wantError: true,
+		request: &models.ResetPasswordRequest{
+			Email:    "alice@example.com",
+			OTP:      "123456",
+			Password: "N3wPassphrase",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("alice@example.com").Return(&models.User{UserID: 3}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			otp := validOtp(3)
+			otp.Status = "invalidated"
+			otp.FailedAttempts = models.OTPMaxFailedAttempts
+			mock.EXPECT().GetOtpByUserID(int64(3), models.OTPPurposePasswordReset).Return(otp, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "otp expired",
+		wantError: true,
+		request: &models.ResetPasswordRequest{
+			Email:    "bob@example.com",
+			OTP:      "222222",
+			Password: "N3wPassphrase",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("bob@example.com").Return(&models.User{UserID: 4}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(4), models.OTPPurposePasswordReset).Return(&models.OTPLog{
+				ID:        40,
+				UserID:    4,
+				OTPCode:   "222222",
+				Status:    "created",
+				ExpiredAt: time.Now().Add(-time.Minute),
+			}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:      "otp already used",
+		wantError: true,
+		request: &models.ResetPasswordRequest{
+			Email:    "bob@example.com",
+			OTP:      "123456",
+			Password: "N3wPassphrase",
+		},
+		onGetUserByEmail: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByEmail("bob@example.com").Return(&models.User{UserID: 4}, nil)
+		},
+		onGetOtpByUserID: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().GetOtpByUserID(int64(4), models.OTPPurposePasswordReset).Return(validOtp(4), nil)
+		},
+		onConsumeOtp: func(mock *mocks.MockOtpRepository) {
+			mock.EXPECT().ConsumeOtp(int64(40)).Return(false, nil)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+			otpRepo := mocks.NewMockOtpRepository(mockCtrl)
+			auditLogger := mocks.NewMockAuditLogger(mockCtrl)
+
+			if tc.onGetUserByEmail != nil {
+				tc.onGetUserByEmail(userRepo)
+			}
+
+			if tc.onGetOtpByUserID != nil {
+				tc.onGetOtpByUserID(otpRepo)
+			}
+
+			if tc.onFailOtp != nil {
+				tc.onFailOtp(otpRepo)
+			}
+
+			if tc.onConsumeOtp != nil {
+				tc.onConsumeOtp(otpRepo)
+			}
+
+			if tc.onUpdateUser != nil {
+				tc.onUpdateUser(userRepo)
+			}
+
+			if tc.onRecord != nil {
+				tc.onRecord(auditLogger)
+			}
+
+			usecase := &UserUsecase{userRepo: userRepo, otpRepo: otpRepo, auditLogger: auditLogger}
+
+			err := usecase.ResetPassword(context.Background(), tc.request)
+
+			if tc.wantError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_UserUsecase_ValidateSession(t *testing.T) {
+	type testCase struct {
+		name          string
+		wantError     bool
+		expectedCode  int
+		userID        int64
+		tokenVersion  int64
+		onGetUserByID func(mock *mocks.MockUserRepository)
+	}
+
+	suspendedAt := time.Now()
+
+	var testTable []testCase
+	testTable = append(testTable, testCase{
+		name:         "success",
+		wantError:    false,
+		userID:       1,
+		tokenVersion: 2,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(1)).Return(&models.User{UserID: 1, TokenVersion: 2}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "user not found",
+		wantError:    true,
+		expectedCode: 401,
+		userID:       2,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(2)).Return(nil, gorm.ErrRecordNotFound)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "user suspended",
+		wantError:    true,
+		expectedCode: 403,
+		userID:       3,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(3)).Return(&models.User{UserID: 3, SuspendedAt: &suspendedAt}, nil)
+		},
+	})
+
+	testTable = append(testTable, testCase{
+		name:         "session revoked",
+		wantError:    true,
+		expectedCode: 401,
+		userID:       4,
+		tokenVersion: 1,
+		onGetUserByID: func(mock *mocks.MockUserRepository) {
+			mock.EXPECT().GetUserByID(int64(4)).Return(&models.User{UserID: 4, TokenVersion: 2}, nil)
+		},
+	})
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(mockCtrl)
+
+			if tc.onGetUserByID != nil {
+				tc.onGetUserByID(userRepo)
+			}
+
 			usecase := &UserUsecase{userRepo: userRepo}
 
-			resp, err := usecase.Login(tc.request)
+			user, err := usecase.ValidateSession(context.Background(), tc.userID, tc.tokenVersion)
 
 			if tc.wantError {
 				assert.NotNil(t, err)
+				assert.Equal(t, tc.expectedCode, err.Code())
 			} else {
 				assert.Nil(t, err)
-				assert.Equal(t, tc.expectedResponse, resp)
+				assert.Equal(t, tc.userID, user.UserID)
 			}
 		})
 	}